@@ -7,6 +7,7 @@ require (
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/gorilla/websocket v1.5.3
 	github.com/spf13/cobra v1.8.0
+	golang.org/x/crypto v0.18.0
 	golang.org/x/term v0.16.0
 )
 
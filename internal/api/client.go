@@ -2,27 +2,85 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/devlyspace/dashspace-cli/internal/config"
+	"github.com/devlyspace/dashspace-cli/internal/logging"
 )
 
+// refreshMu serializes token refreshes so concurrent requests don't burn the refresh token twice.
+var refreshMu sync.Mutex
+
+// DefaultRetries is how many times an idempotent request is retried after a
+// network error or a 429/502/503/504 response.
+const DefaultRetries = 3
+
+const retryBaseDelay = 500 * time.Millisecond
+
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	retries    int
+	token      string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeout bounds connecting and waiting for the response headers. The
+// request body itself (e.g. an upload) is not limited.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient = &http.Client{Transport: &loggingTransport{next: newTransport(timeout)}}
+	}
+}
+
+// WithRetries sets how many times idempotent requests are retried; 0 disables retries.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithToken authenticates every request with token. The session of the
+// active profile is then neither sent nor refreshed.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTP client, e.g. with an httptest server's.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	User         User   `json:"user"`
 }
 
+// ErrSessionExpired is returned when the access token was rejected and could not be refreshed.
+var ErrSessionExpired = errors.New("your session has expired, run 'dashspace login' to sign in again")
+
 type User struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
@@ -39,57 +97,97 @@ type ModuleManifest struct {
 	Interfaces  []string `json:"interfaces"`
 }
 
-type ModuleSearchResult struct {
-	ID          int      `json:"id"`
-	Name        string   `json:"name"`
-	Version     string   `json:"version"`
-	Description string   `json:"description"`
-	Author      string   `json:"author"`
-	Tags        []string `json:"tags"`
+// Module is a module registered in the store.
+type Module struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
 }
 
-func NewClient() *Client {
+// ModuleVersion is an uploaded version of a module.
+type ModuleVersion struct {
+	ID            int       `json:"id"`
+	ModuleID      int       `json:"module_id"`
+	Version       string    `json:"version"`
+	RequiresSetup bool      `json:"requires_setup"`
+	Changelog     string    `json:"changelog,omitempty"`
+	Downloads     int       `json:"downloads,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	// Set by the store on published versions: the SHA-256 (hex) of the
+	// archive, and its base64 Ed25519 signature with the ID of the key.
+	SHA256       string `json:"sha256,omitempty"`
+	Signature    string `json:"signature,omitempty"`
+	SigningKeyID string `json:"signing_key_id,omitempty"`
+}
+
+// requestFunc builds a request. It is called again for every retry, so the
+// body can be sent more than once.
+type requestFunc func(ctx context.Context) (*http.Request, error)
+
+func NewClient(opts ...Option) *Client {
 	cfg := config.GetConfig()
-	return &Client{
-		baseURL:    cfg.APIBaseURL,
-		httpClient: &http.Client{},
+	return NewClientWithBaseURL(cfg.APIBaseURL, append([]Option{WithTimeout(cfg.Timeout)}, opts...)...)
+}
+
+// NewClientWithBaseURL returns a client for another API server, e.g. a local stand-in.
+func NewClientWithBaseURL(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Transport: &loggingTransport{next: newTransport(config.DefaultTimeout)}},
+		retries:    DefaultRetries,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
+
+func newTransport(timeout time.Duration) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	return transport
 }
 
-func (c *Client) Login(email, password string) (*AuthResponse, error) {
+func (c *Client) Login(ctx context.Context, email, password string) (*AuthResponse, error) {
 	payload := map[string]string{
 		"email":    email,
 		"password": password,
 	}
 
-	resp, err := c.post("/auth/login", payload)
+	// Not authenticated: a 401 here means wrong credentials, not an expired session
+	resp, err := c.do(ctx, c.jsonRequest("POST", "/auth/login", payload), "")
 	if err != nil {
 		return nil, err
 	}
 
 	var authResp AuthResponse
-	if err := json.Unmarshal(resp, &authResp); err != nil {
+	if err := decodeJSON(resp, &authResp); err != nil {
 		return nil, err
 	}
 
 	return &authResp, nil
 }
 
-func (c *Client) GetCurrentUser() (*User, error) {
-	resp, err := c.get("/auth/me")
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	resp, err := c.get(ctx, "/auth/me")
 	if err != nil {
 		return nil, err
 	}
 
 	var user User
-	if err := json.Unmarshal(resp, &user); err != nil {
+	if err := decodeJSON(resp, &user); err != nil {
 		return nil, err
 	}
 
 	return &user, nil
 }
 
-func (c *Client) CreateOrGetModule(manifest *ModuleManifest) (int, error) {
+func (c *Client) CreateOrGetModule(ctx context.Context, manifest *ModuleManifest) (*Module, error) {
 	payload := map[string]interface{}{
 		"name":         manifest.Name,
 		"display_name": manifest.Name,
@@ -98,152 +196,325 @@ func (c *Client) CreateOrGetModule(manifest *ModuleManifest) (int, error) {
 		"visibility":   "public",
 	}
 
-	resp, err := c.post("/modules", payload)
+	resp, err := c.post(ctx, "/modules", payload)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(resp, &result); err != nil {
-		return 0, err
+	var module Module
+	if err := decodeJSON(resp, &module); err != nil {
+		return nil, err
 	}
-
-	if id, ok := result["id"].(float64); ok {
-		return int(id), nil
+	if module.ID == 0 {
+		return nil, fmt.Errorf("unable to get module ID")
 	}
 
-	return 0, fmt.Errorf("unable to get module ID")
+	return &module, nil
 }
 
-func (c *Client) SearchModules(query string) ([]ModuleSearchResult, error) {
-	endpoint := fmt.Sprintf("/modules?search=%s", query)
-	resp, err := c.get(endpoint)
+// UploadModuleVersion uploads a module archive, with optional extra form fields.
+func (c *Client) UploadModuleVersion(ctx context.Context, moduleID int, zipPath string, fields map[string]string) (*ModuleVersion, error) {
+	endpoint := fmt.Sprintf("%s/modules/%d/module_versions/upload", c.baseURL, moduleID)
+
+	// The request is rebuilt from the file if it has to be retried after a token refresh
+	newRequest := func(ctx context.Context) (*http.Request, error) {
+		file, err := os.Open(zipPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		var b bytes.Buffer
+		writer := multipart.NewWriter(&b)
+
+		part, err := writer.CreateFormFile("file", filepath.Base(zipPath))
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.Copy(part, file); err != nil {
+			return nil, err
+		}
+
+		for name, value := range fields {
+			if err := writer.WriteField(name, value); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", endpoint, &b)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req, nil
+	}
+
+	resp, err := c.doAuthenticated(ctx, newRequest)
 	if err != nil {
 		return nil, err
 	}
 
-	var searchResponse struct {
-		Items []ModuleSearchResult `json:"items"`
-		Total int                  `json:"total"`
+	var version ModuleVersion
+	if err := decodeJSON(resp, &version); err != nil {
+		return nil, err
+	}
+	if version.ID == 0 {
+		return nil, fmt.Errorf("unable to get version ID")
 	}
 
-	if err := json.Unmarshal(resp, &searchResponse); err != nil {
-		return nil, err
+	return &version, nil
+}
+
+func (c *Client) get(ctx context.Context, endpoint string) ([]byte, error) {
+	return c.doAuthenticated(ctx, c.jsonRequest("GET", endpoint, nil))
+}
+
+func (c *Client) post(ctx context.Context, endpoint string, payload interface{}) ([]byte, error) {
+	return c.doAuthenticated(ctx, c.jsonRequest("POST", endpoint, payload))
+}
+
+func (c *Client) delete(ctx context.Context, endpoint string) ([]byte, error) {
+	return c.doAuthenticated(ctx, c.jsonRequest("DELETE", endpoint, nil))
+}
+
+// jsonRequest returns a requestFunc sending payload, if any, as JSON.
+func (c *Client) jsonRequest(method, endpoint string, payload interface{}) requestFunc {
+	return func(ctx context.Context) (*http.Request, error) {
+		var body io.Reader
+		if payload != nil {
+			jsonData, err := json.Marshal(payload)
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(jsonData)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, body)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		return req, nil
 	}
+}
 
-	return searchResponse.Items, nil
+// decodeJSON unmarshals a response body into v.
+func decodeJSON(body []byte, v interface{}) error {
+	if len(body) == 0 {
+		return fmt.Errorf("empty response from the API")
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid response from the API: %w", err)
+	}
+	return nil
 }
 
-func (c *Client) UploadModuleVersion(moduleID int, zipPath string) (int, error) {
-	url := fmt.Sprintf("%s/modules/%d/module_versions/upload", c.baseURL, moduleID)
+// SameServer reports whether two API base URLs point at the same server.
+func SameServer(a, b string) bool {
+	ua, errA := url.Parse(strings.TrimRight(a, "/"))
+	ub, errB := url.Parse(strings.TrimRight(b, "/"))
+	if errA != nil || errB != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host) && ua.Path == ub.Path
+}
 
-	file, err := os.Open(zipPath)
-	if err != nil {
-		return 0, err
+// checkProfileServer makes sure the session of the active profile only goes
+// to the profile's API URL.
+func (c *Client) checkProfileServer() error {
+	if apiURL := config.GetConfig().APIBaseURL; !SameServer(c.baseURL, apiURL) {
+		return fmt.Errorf("refusing to send the session of the active profile to %s, its API URL is %s", c.baseURL, apiURL)
 	}
-	defer file.Close()
+	return nil
+}
 
-	var b bytes.Buffer
-	writer := multipart.NewWriter(&b)
+// doAuthenticated sends the request built by newRequest with the token given
+// by WithToken, or else the saved access token of the active profile. A
+// session token about to expire is refreshed first; on 401 it is refreshed
+// and the request rebuilt and retried once.
+func (c *Client) doAuthenticated(ctx context.Context, newRequest requestFunc) ([]byte, error) {
+	if c.token != "" {
+		return c.do(ctx, newRequest, c.token)
+	}
+	if err := c.checkProfileServer(); err != nil {
+		return nil, err
+	}
+	if err := config.LoadCredentials(); err != nil {
+		return nil, err
+	}
 
-	part, err := writer.CreateFormFile("file", filepath.Base(zipPath))
-	if err != nil {
-		return 0, err
+	cfg := config.GetConfig()
+	if cfg.AuthToken != "" && cfg.RefreshToken != "" && cfg.TokenExpiresWithin(30*time.Second) {
+		// A failed early refresh is not fatal, the server decides whether the token is still valid
+		c.RefreshSession(ctx)
 	}
 
-	if _, err := io.Copy(part, file); err != nil {
-		return 0, err
+	usedToken := cfg.AuthToken
+	body, err := c.do(ctx, newRequest, usedToken)
+	if usedToken == "" || !errors.Is(err, ErrUnauthorized) {
+		return body, err
 	}
 
-	writer.Close()
+	if err := c.refreshRejected(ctx, usedToken); err != nil {
+		return nil, ErrSessionExpired
+	}
 
-	req, err := http.NewRequest("POST", url, &b)
-	if err != nil {
-		return 0, err
+	body, err = c.do(ctx, newRequest, cfg.AuthToken)
+	if errors.Is(err, ErrUnauthorized) {
+		return nil, ErrSessionExpired
 	}
+	return body, err
+}
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+// do sends the request with token, if any, and returns the body of a
+// successful response or an *APIError. Idempotent requests are retried with
+// exponential backoff when the failure looks temporary.
+func (c *Client) do(ctx context.Context, newRequest requestFunc, token string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.send(req, token)
+		if err == nil || attempt >= c.retries || !isIdempotent(req.Method) || !isTemporary(ctx, err) {
+			return body, err
+		}
 
-	if token := config.GetConfig().AuthToken; token != "" {
+		delay := retryDelay(attempt, err)
+		logging.Logger().InfoContext(ctx, "retrying request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"attempt", attempt+1,
+			"delay", delay,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c *Client) send(req *http.Request, token string) ([]byte, error) {
+	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(body))
-	}
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return 0, err
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp, body)
 	}
+	return body, nil
+}
 
-	if id, ok := result["id"].(float64); ok {
-		return int(id), nil
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
 	}
-
-	return 0, fmt.Errorf("unable to get version ID")
+	return false
 }
 
-func (c *Client) get(endpoint string) ([]byte, error) {
-	return c.request("GET", endpoint, nil)
+// isTemporary reports whether err may go away by retrying: network errors and
+// overload responses, but not a cancelled context.
+func isTemporary(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.temporary()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
 }
 
-func (c *Client) post(endpoint string, payload interface{}) ([]byte, error) {
-	return c.request("POST", endpoint, payload)
+// retryDelay doubles the delay on every attempt, with jitter, unless the
+// server asked for a specific delay with Retry-After.
+func retryDelay(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.retryAfter > 0 {
+		return apiErr.retryAfter
+	}
+	delay := retryBaseDelay << attempt
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
 }
 
-func (c *Client) request(method, endpoint string, payload interface{}) ([]byte, error) {
-	var body io.Reader
-
-	if payload != nil {
-		jsonData, err := json.Marshal(payload)
-		if err != nil {
-			return nil, err
-		}
-		body = bytes.NewBuffer(jsonData)
-	}
+// RefreshSession exchanges the saved refresh token for a new access token and saves it.
+func (c *Client) RefreshSession(ctx context.Context) error {
+	return c.refreshRejected(ctx, "")
+}
 
-	req, err := http.NewRequest(method, c.baseURL+endpoint, body)
-	if err != nil {
-		return nil, err
+// refreshRejected refreshes the session unless the rejected token was already
+// replaced by a concurrent refresh.
+func (c *Client) refreshRejected(ctx context.Context, rejected string) error {
+	if err := c.checkProfileServer(); err != nil {
+		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
 
-	if token := config.GetConfig().AuthToken; token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
+	cfg := config.GetConfig()
+	if rejected != "" && cfg.AuthToken != rejected {
+		return nil
+	}
+	if cfg.RefreshToken == "" {
+		return fmt.Errorf("no refresh token saved")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	payload := map[string]string{"refresh_token": cfg.RefreshToken}
+
+	resp, err := c.do(ctx, c.jsonRequest("POST", "/auth/refresh", payload), "")
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	var token TokenResponse
+	if err := decodeJSON(resp, &token); err != nil {
+		return err
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("no access token in refresh response")
 	}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(respBody))
+	cfg.SetTokens(token.AccessToken, token.RefreshToken, token.ExpiresIn)
+	return config.SaveCredentials()
+}
+
+// RevokeToken invalidates a token server-side (RFC 7009).
+func (c *Client) RevokeToken(ctx context.Context, token, tokenTypeHint string) error {
+	form := url.Values{}
+	form.Set("token", token)
+	if tokenTypeHint != "" {
+		form.Set("token_type_hint", tokenTypeHint)
 	}
+	form.Set("client_id", CLIClientID)
 
-	return respBody, nil
+	_, err := c.postForm(ctx, "/auth/revoke", form)
+	return err
 }
 
-func (c *Client) GetProfile() (*User, error) {
-	return c.GetCurrentUser()
+func (c *Client) GetProfile(ctx context.Context) (*User, error) {
+	return c.GetCurrentUser(ctx)
 }
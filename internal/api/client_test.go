@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/devlyspace/dashspace-cli/internal/config"
+	"github.com/devlyspace/dashspace-cli/internal/credentials"
+	"github.com/devlyspace/dashspace-cli/test"
+)
+
+// recordingServer answers every request with an empty version list and
+// records the Authorization headers it received.
+type recordingServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	headers []string
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.headers = append(s.headers, r.Header.Get("Authorization"))
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": []}`))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// useProfileServer logs the active profile in to apiURL with a session token.
+func useProfileServer(t *testing.T, apiURL string) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(credentials.TokenEnvVar, "session-token")
+	config.InitConfig(config.Overrides{APIURL: apiURL})
+}
+
+func TestSessionSentToProfileServer(t *testing.T) {
+	profile := newRecordingServer(t)
+	useProfileServer(t, profile.URL)
+
+	if _, err := NewClientWithBaseURL(profile.URL+"/").ListModuleVersions(context.Background(), 1); err != nil {
+		t.Fatalf("ListModuleVersions: %v", err)
+	}
+	test.AssertEqual(t, len(profile.headers), 1)
+	test.AssertEqual(t, profile.headers[0], "Bearer session-token")
+}
+
+func TestSessionNotSentToOtherServer(t *testing.T) {
+	profile := newRecordingServer(t)
+	other := newRecordingServer(t)
+	useProfileServer(t, profile.URL)
+
+	if _, err := NewClientWithBaseURL(other.URL).ListModuleVersions(context.Background(), 1); err == nil {
+		t.Fatal("expected the request to another server to be refused")
+	}
+	if err := NewClientWithBaseURL(other.URL).RefreshSession(context.Background()); err == nil {
+		t.Fatal("expected the refresh against another server to be refused")
+	}
+	test.AssertEqual(t, len(other.headers), 0)
+}
+
+func TestExplicitTokenSentToOtherServer(t *testing.T) {
+	profile := newRecordingServer(t)
+	other := newRecordingServer(t)
+	useProfileServer(t, profile.URL)
+
+	if _, err := NewClientWithBaseURL(other.URL, WithToken("explicit-token")).ListModuleVersions(context.Background(), 1); err != nil {
+		t.Fatalf("ListModuleVersions: %v", err)
+	}
+	test.AssertEqual(t, len(other.headers), 1)
+	test.AssertEqual(t, other.headers[0], "Bearer explicit-token")
+	test.AssertEqual(t, len(profile.headers), 0)
+}
+
+func TestSameServer(t *testing.T) {
+	test.AssertEqual(t, SameServer("https://modly.dashspace.dev", "https://MODLY.dashspace.dev/"), true)
+	test.AssertEqual(t, SameServer("https://modly.dashspace.dev", "http://modly.dashspace.dev"), false)
+	test.AssertEqual(t, SameServer("https://modly.dashspace.dev", "https://modly.dashspace.dev.evil.test"), false)
+	test.AssertEqual(t, SameServer("https://modly.dashspace.dev/api", "https://modly.dashspace.dev"), false)
+}
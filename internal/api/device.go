@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CLIClientID identifies the CLI to the DashSpace authorization server.
+const CLIClientID = "dashspace-cli"
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// devicePollUnit is the unit of the polling interval and expiry sent by the
+// server, seconds per RFC 8628. Tests shorten it.
+var devicePollUnit = time.Second
+
+// Errors returned by the device token endpoint (RFC 8628 section 3.5).
+var (
+	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrSlowDown             = errors.New("slow down")
+	ErrAccessDenied         = errors.New("authorization request was denied")
+	ErrExpiredToken         = errors.New("device code expired")
+)
+
+// DeviceCode is the device authorization response (RFC 8628 section 3.2).
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// TokenResponse is an OAuth access token response.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// RequestDeviceCode starts a device authorization flow.
+func (c *Client) RequestDeviceCode(ctx context.Context, clientID string) (*DeviceCode, error) {
+	form := url.Values{}
+	form.Set("client_id", clientID)
+
+	body, err := c.postForm(ctx, "/auth/device/code", form)
+	if err != nil {
+		return nil, err
+	}
+
+	var code DeviceCode
+	if err := decodeJSON(body, &code); err != nil {
+		return nil, err
+	}
+	if code.DeviceCode == "" || code.UserCode == "" || code.VerificationURI == "" {
+		return nil, fmt.Errorf("invalid device authorization response")
+	}
+	if code.Interval <= 0 {
+		code.Interval = 5
+	}
+
+	return &code, nil
+}
+
+// PollDeviceToken asks once for the token of a device code. It returns
+// ErrAuthorizationPending or ErrSlowDown while the user has not approved yet.
+func (c *Client) PollDeviceToken(ctx context.Context, clientID, deviceCode string) (*TokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", deviceCodeGrantType)
+	form.Set("device_code", deviceCode)
+	form.Set("client_id", clientID)
+
+	body, err := c.postForm(ctx, "/auth/device/token", form)
+	if err != nil {
+		return nil, err
+	}
+
+	var token TokenResponse
+	if err := decodeJSON(body, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+
+	return &token, nil
+}
+
+// WaitForDeviceToken polls the token endpoint at the interval requested by the
+// server until the user approves or denies the request, or the code expires.
+func (c *Client) WaitForDeviceToken(ctx context.Context, clientID string, code *DeviceCode) (*TokenResponse, error) {
+	interval := time.Duration(code.Interval) * devicePollUnit
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * devicePollUnit)
+
+	for {
+		if code.ExpiresIn > 0 && time.Now().After(deadline) {
+			return nil, ErrExpiredToken
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		token, err := c.PollDeviceToken(ctx, clientID, code.DeviceCode)
+		switch {
+		case err == nil:
+			return token, nil
+		case errors.Is(err, ErrAuthorizationPending):
+			continue
+		case errors.Is(err, ErrSlowDown):
+			// RFC 8628: increase the polling interval by 5 seconds
+			interval += 5 * devicePollUnit
+		default:
+			return nil, err
+		}
+	}
+}
+
+// GetUserWithToken returns the user owning token, before it is saved.
+func (c *Client) GetUserWithToken(ctx context.Context, token string) (*User, error) {
+	resp, err := c.do(ctx, c.jsonRequest("GET", "/auth/me", nil), token)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := decodeJSON(resp, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// postForm sends an OAuth form-encoded request and maps OAuth error codes.
+func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values) ([]byte, error) {
+	body, err := c.do(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+		return req, nil
+	}, "")
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case "authorization_pending":
+			return nil, ErrAuthorizationPending
+		case "slow_down":
+			return nil, ErrSlowDown
+		case "access_denied":
+			return nil, ErrAccessDenied
+		case "expired_token":
+			return nil, ErrExpiredToken
+		}
+	}
+	return body, err
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devlyspace/dashspace-cli/test"
+)
+
+// deviceServer is a local stand-in for the device authorization endpoints. The
+// token endpoint answers with responses in order, the last one repeating.
+type deviceServer struct {
+	t         *testing.T
+	responses []string // OAuth error codes, "" grants the token
+	interval  int
+	expiresIn int
+
+	mu    sync.Mutex
+	polls []time.Time
+}
+
+func (s *deviceServer) start() *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/device/code", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("client_id"); got != CLIClientID {
+			s.t.Errorf("client_id = %q, expected %q", got, CLIClientID)
+		}
+		json.NewEncoder(w).Encode(DeviceCode{
+			DeviceCode:      "device-code",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://example.test/device",
+			ExpiresIn:       s.expiresIn,
+			Interval:        s.interval,
+		})
+	})
+	mux.HandleFunc("/auth/device/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if got := r.PostForm.Get("grant_type"); got != deviceCodeGrantType {
+			s.t.Errorf("grant_type = %q, expected %q", got, deviceCodeGrantType)
+		}
+		if got := r.PostForm.Get("device_code"); got != "device-code" {
+			s.t.Errorf("device_code = %q, expected device-code", got)
+		}
+
+		s.mu.Lock()
+		s.polls = append(s.polls, time.Now())
+		response := s.responses[len(s.responses)-1]
+		if len(s.polls) <= len(s.responses) {
+			response = s.responses[len(s.polls)-1]
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if response != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": response})
+			return
+		}
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "access-token", RefreshToken: "refresh-token", TokenType: "Bearer"})
+	})
+
+	server := httptest.NewServer(mux)
+	s.t.Cleanup(server.Close)
+	return NewClientWithBaseURL(server.URL, WithRetries(0))
+}
+
+func (s *deviceServer) wait(ctx context.Context) (*TokenResponse, error) {
+	client := s.start()
+	code, err := client.RequestDeviceCode(ctx, CLIClientID)
+	if err != nil {
+		s.t.Fatalf("RequestDeviceCode: %v", err)
+	}
+	return client.WaitForDeviceToken(ctx, CLIClientID, code)
+}
+
+func shortenDevicePolling(t *testing.T) {
+	unit := devicePollUnit
+	devicePollUnit = 10 * time.Millisecond
+	t.Cleanup(func() { devicePollUnit = unit })
+}
+
+func TestWaitForDeviceTokenAuthorizationPending(t *testing.T) {
+	shortenDevicePolling(t)
+	server := &deviceServer{t: t, responses: []string{"authorization_pending", "authorization_pending", ""}, interval: 1, expiresIn: 100}
+
+	token, err := server.wait(context.Background())
+	if err != nil {
+		t.Fatalf("WaitForDeviceToken: %v", err)
+	}
+	test.AssertEqual(t, token.AccessToken, "access-token")
+	test.AssertEqual(t, token.RefreshToken, "refresh-token")
+	test.AssertEqual(t, len(server.polls), 3)
+}
+
+func TestWaitForDeviceTokenSlowDown(t *testing.T) {
+	shortenDevicePolling(t)
+	server := &deviceServer{t: t, responses: []string{"authorization_pending", "slow_down", ""}, interval: 1, expiresIn: 100}
+
+	if _, err := server.wait(context.Background()); err != nil {
+		t.Fatalf("WaitForDeviceToken: %v", err)
+	}
+	test.AssertEqual(t, len(server.polls), 3)
+
+	// The interval grows by 5 units after slow_down
+	if gap := server.polls[2].Sub(server.polls[1]); gap < 6*devicePollUnit {
+		t.Errorf("polled %v after slow_down, expected at least %v", gap, 6*devicePollUnit)
+	}
+}
+
+func TestWaitForDeviceTokenExpiredToken(t *testing.T) {
+	shortenDevicePolling(t)
+	server := &deviceServer{t: t, responses: []string{"authorization_pending", "expired_token"}, interval: 1, expiresIn: 100}
+
+	_, err := server.wait(context.Background())
+	if !errors.Is(err, ErrExpiredToken) {
+		t.Fatalf("expected ErrExpiredToken, got %v", err)
+	}
+	test.AssertEqual(t, len(server.polls), 2)
+}
+
+func TestWaitForDeviceTokenExpiresLocally(t *testing.T) {
+	shortenDevicePolling(t)
+	server := &deviceServer{t: t, responses: []string{"authorization_pending"}, interval: 1, expiresIn: 5}
+
+	_, err := server.wait(context.Background())
+	if !errors.Is(err, ErrExpiredToken) {
+		t.Fatalf("expected ErrExpiredToken, got %v", err)
+	}
+}
+
+func TestWaitForDeviceTokenAccessDenied(t *testing.T) {
+	shortenDevicePolling(t)
+	server := &deviceServer{t: t, responses: []string{"access_denied"}, interval: 1, expiresIn: 100}
+
+	_, err := server.wait(context.Background())
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+}
+
+func TestWaitForDeviceTokenCancelled(t *testing.T) {
+	shortenDevicePolling(t)
+	server := &deviceServer{t: t, responses: []string{"authorization_pending"}, interval: 1, expiresIn: 1000}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*devicePollUnit)
+	defer cancel()
+	_, err := server.wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context error, got %v", err)
+	}
+}
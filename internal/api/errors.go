@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors matched by *APIError with errors.Is.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrConflict     = errors.New("conflict")
+)
+
+// APIError is returned for any response with a 4xx or 5xx status.
+type APIError struct {
+	StatusCode int
+	Code       string // machine-readable error code, if the server sent one
+	Message    string
+	RequestID  string // quote it when reporting a server problem
+
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "API error (%d", e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " %s", e.Code)
+	}
+	b.WriteString(")")
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " (request ID %s)", e.RequestID)
+	}
+	return b.String()
+}
+
+// Is lets callers test the status with errors.Is(err, api.ErrNotFound).
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// temporary reports whether the request may succeed if sent again.
+func (e *APIError) temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+var errorCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// errorBody covers the error formats of the API and of its OAuth endpoints.
+type errorBody struct {
+	Error            json.RawMessage `json:"error"`
+	ErrorDescription string          `json:"error_description"`
+	Code             string          `json:"code"`
+	Message          string          `json:"message"`
+	RequestID        string          `json:"request_id"`
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var parsed errorBody
+	if json.Unmarshal(body, &parsed) != nil {
+		apiErr.Message = strings.TrimSpace(string(body))
+		if len(apiErr.Message) > 200 {
+			apiErr.Message = apiErr.Message[:200] + "…"
+		}
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	// "error" is either an OAuth error code or a human-readable message
+	var errorField string
+	json.Unmarshal(parsed.Error, &errorField)
+
+	switch {
+	case parsed.ErrorDescription != "":
+		apiErr.Code, apiErr.Message = errorField, parsed.ErrorDescription
+	case errorCodePattern.MatchString(errorField):
+		apiErr.Code = errorField
+	default:
+		apiErr.Message = errorField
+	}
+	if parsed.Code != "" {
+		apiErr.Code = parsed.Code
+	}
+	if parsed.Message != "" {
+		apiErr.Message = parsed.Message
+	}
+	if apiErr.Message == "" && apiErr.Code == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = parsed.RequestID
+	}
+
+	return apiErr
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// ExchangeAuthCode trades an authorization code from the web login callback
+// for tokens. codeVerifier is the PKCE secret whose S256 challenge was sent
+// with the authorization request (RFC 7636).
+func (c *Client) ExchangeAuthCode(ctx context.Context, clientID, code, codeVerifier, redirectURI string) (*TokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", clientID)
+
+	body, err := c.postForm(ctx, "/auth/token", form)
+	if err != nil {
+		return nil, err
+	}
+
+	var token TokenResponse
+	if err := decodeJSON(body, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("no access token in response")
+	}
+
+	return &token, nil
+}
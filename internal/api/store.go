@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Sort orders accepted by SearchModules.
+const (
+	SortDownloads = "downloads"
+	SortUpdated   = "updated"
+)
+
+type ModuleSearchResult struct {
+	ID          int       `json:"id"`
+	Slug        string    `json:"slug,omitempty"`
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Description string    `json:"description"`
+	Author      string    `json:"author"`
+	Category    string    `json:"category,omitempty"`
+	Tags        []string  `json:"tags"`
+	Downloads   int       `json:"downloads,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SearchOptions filters and pages a store search. Zero values are not sent.
+type SearchOptions struct {
+	Query     string
+	Category  string
+	Tags      []string
+	Provider  string
+	Interface string
+	Sort      string // SortDownloads or SortUpdated, relevance by default
+	Page      int    // 1-based
+	Limit     int
+}
+
+// SearchResult is one page of search results.
+type SearchResult struct {
+	Items []ModuleSearchResult `json:"items"`
+	Total int                  `json:"total"`
+	Page  int                  `json:"page"`
+	Limit int                  `json:"limit"`
+}
+
+// Pages returns the number of pages for Total results.
+func (r *SearchResult) Pages() int {
+	if r.Limit <= 0 {
+		return 1
+	}
+	return (r.Total + r.Limit - 1) / r.Limit
+}
+
+// ModuleProvider is a data provider a module needs.
+type ModuleProvider struct {
+	Name        string   `json:"name"`
+	Required    bool     `json:"required"`
+	Scopes      []string `json:"scopes,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// ConfigurationField is one input of a configuration step.
+type ConfigurationField struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Label       string `json:"label,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ConfigurationStep is a step users go through when adding the module.
+type ConfigurationStep struct {
+	ID          string               `json:"id"`
+	Title       string               `json:"title"`
+	Description string               `json:"description,omitempty"`
+	Order       int                  `json:"order,omitempty"`
+	Optional    bool                 `json:"optional,omitempty"`
+	Fields      []ConfigurationField `json:"fields,omitempty"`
+}
+
+// ModuleDetails is the full store entry of a module.
+type ModuleDetails struct {
+	ID                 int                 `json:"id"`
+	Slug               string              `json:"slug"`
+	Name               string              `json:"name"`
+	DisplayName        string              `json:"display_name,omitempty"`
+	Description        string              `json:"description"`
+	Author             string              `json:"author"`
+	Category           string              `json:"category,omitempty"`
+	Tags               []string            `json:"tags,omitempty"`
+	LatestVersion      string              `json:"latest_version"`
+	Downloads          int                 `json:"downloads"`
+	Providers          []ModuleProvider    `json:"providers,omitempty"`
+	Interfaces         []string            `json:"interfaces,omitempty"`
+	Permissions        []string            `json:"permissions,omitempty"`
+	RequiresSetup      bool                `json:"requires_setup"`
+	ConfigurationSteps []ConfigurationStep `json:"configuration_steps,omitempty"`
+	Repository         string              `json:"repository,omitempty"`
+	CreatedAt          time.Time           `json:"created_at"`
+	UpdatedAt          time.Time           `json:"updated_at"`
+}
+
+func (c *Client) SearchModules(ctx context.Context, opts SearchOptions) (*SearchResult, error) {
+	params := url.Values{}
+	if opts.Query != "" {
+		params.Set("search", opts.Query)
+	}
+	if opts.Category != "" {
+		params.Set("category", opts.Category)
+	}
+	for _, tag := range opts.Tags {
+		params.Add("tag", tag)
+	}
+	if opts.Provider != "" {
+		params.Set("provider", opts.Provider)
+	}
+	if opts.Interface != "" {
+		params.Set("interface", opts.Interface)
+	}
+	if opts.Sort != "" {
+		params.Set("sort", opts.Sort)
+	}
+	if opts.Page > 0 {
+		params.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	resp, err := c.get(ctx, "/modules?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var result SearchResult
+	if err := decodeJSON(resp, &result); err != nil {
+		return nil, err
+	}
+
+	// Older servers don't echo the paging back
+	if result.Page == 0 {
+		result.Page = max(opts.Page, 1)
+	}
+	if result.Limit == 0 {
+		result.Limit = opts.Limit
+	}
+	if result.Total == 0 {
+		result.Total = len(result.Items)
+	}
+
+	return &result, nil
+}
+
+// GetModule returns a module by slug or numeric ID.
+func (c *Client) GetModule(ctx context.Context, slugOrID string) (*ModuleDetails, error) {
+	resp, err := c.get(ctx, "/modules/"+url.PathEscape(slugOrID))
+	if err != nil {
+		return nil, err
+	}
+
+	var module ModuleDetails
+	if err := decodeJSON(resp, &module); err != nil {
+		return nil, err
+	}
+	if module.ID == 0 {
+		return nil, fmt.Errorf("unable to get module ID")
+	}
+
+	return &module, nil
+}
+
+// ListModuleVersions returns the published versions of a module, newest first.
+func (c *Client) ListModuleVersions(ctx context.Context, moduleID int) ([]ModuleVersion, error) {
+	resp, err := c.get(ctx, fmt.Sprintf("/modules/%d/module_versions", moduleID))
+	if err != nil {
+		return nil, err
+	}
+
+	var listResponse struct {
+		Items []ModuleVersion `json:"items"`
+	}
+	if err := decodeJSON(resp, &listResponse); err != nil {
+		return nil, err
+	}
+
+	return listResponse.Items, nil
+}
+
+// SigningKey is a public key the store signs module archives with.
+type SigningKey struct {
+	ID        string `json:"id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"` // base64
+}
+
+// ListSigningKeys returns the keys module archives may be signed with.
+func (c *Client) ListSigningKeys(ctx context.Context) ([]SigningKey, error) {
+	resp, err := c.get(ctx, "/signing_keys")
+	if err != nil {
+		return nil, err
+	}
+
+	var listResponse struct {
+		Items []SigningKey `json:"items"`
+	}
+	if err := decodeJSON(resp, &listResponse); err != nil {
+		return nil, err
+	}
+
+	return listResponse.Items, nil
+}
+
+// DownloadModuleVersion returns the archive of a published version.
+func (c *Client) DownloadModuleVersion(ctx context.Context, moduleID, versionID int) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s/modules/%d/module_versions/%d/download", c.baseURL, moduleID, versionID)
+
+	archive, err := c.doAuthenticated(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/zip")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(archive) == 0 {
+		return nil, fmt.Errorf("empty archive from the API")
+	}
+
+	return archive, nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Scopes a personal access token can be limited to.
+const (
+	ScopeRead    = "read"
+	ScopePublish = "publish"
+	ScopeTokens  = "tokens"
+)
+
+// AccessToken is a personal access token, meant for CI where no one can log in.
+type AccessToken struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Prefix     string     `json:"prefix,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	// Token is the secret itself, only returned when the token is created
+	Token string `json:"token,omitempty"`
+}
+
+// CreateAccessToken creates a personal access token. expiresIn 0 means it never expires.
+func (c *Client) CreateAccessToken(ctx context.Context, name string, scopes []string, expiresIn time.Duration) (*AccessToken, error) {
+	payload := map[string]interface{}{
+		"name":   name,
+		"scopes": scopes,
+	}
+	if expiresIn > 0 {
+		payload["expires_in"] = int(expiresIn.Seconds())
+	}
+
+	resp, err := c.post(ctx, "/auth/tokens", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var token AccessToken
+	if err := decodeJSON(resp, &token); err != nil {
+		return nil, err
+	}
+	if token.Token == "" {
+		return nil, fmt.Errorf("no token in response")
+	}
+
+	return &token, nil
+}
+
+// ListAccessTokens returns the personal access tokens of the current user.
+// Secrets are never returned, only their prefix.
+func (c *Client) ListAccessTokens(ctx context.Context) ([]AccessToken, error) {
+	resp, err := c.get(ctx, "/auth/tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	var listResponse struct {
+		Items []AccessToken `json:"items"`
+	}
+	if err := decodeJSON(resp, &listResponse); err != nil {
+		return nil, err
+	}
+
+	return listResponse.Items, nil
+}
+
+// RevokeAccessToken deletes a personal access token; it stops working immediately.
+func (c *Client) RevokeAccessToken(ctx context.Context, id int) error {
+	_, err := c.delete(ctx, fmt.Sprintf("/auth/tokens/%d", id))
+	return err
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/devlyspace/dashspace-cli/internal/logging"
+)
+
+// maxLoggedBody caps how much of a request or response body is logged.
+const maxLoggedBody = 4096
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are never logged in clear.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Proxy-Authorization": true,
+}
+
+// sensitiveFieldPattern matches JSON and form fields holding secrets.
+var sensitiveFieldPattern = regexp.MustCompile(`(?i)(token|password|secret|passphrase|verifier|device_code|api_?key)`)
+
+// isSensitiveParam also redacts the OAuth authorization code, sent as a form
+// or query parameter; a JSON "code" is an error code worth keeping.
+func isSensitiveParam(name string) bool {
+	return name == "code" || sensitiveFieldPattern.MatchString(name)
+}
+
+// loggingTransport logs every request with its status, duration and request
+// ID. Headers and bodies are logged at debug level, with secrets redacted.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := logging.Logger()
+	ctx := req.Context()
+	debug := logger.Enabled(ctx, slog.LevelDebug)
+
+	if debug {
+		logger.DebugContext(ctx, "http request",
+			"method", req.Method,
+			"url", redactURL(req.URL),
+			"headers", redactHeaders(req.Header),
+			"body", requestBody(req))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start).Round(time.Microsecond).String()
+
+	if err != nil {
+		logger.WarnContext(ctx, "http error",
+			"method", req.Method,
+			"url", redactURL(req.URL),
+			"duration", duration,
+			"error", err)
+		return nil, err
+	}
+
+	attrs := []any{
+		"method", req.Method,
+		"url", redactURL(req.URL),
+		"status", resp.StatusCode,
+		"duration", duration,
+	}
+	if requestID := resp.Header.Get("X-Request-Id"); requestID != "" {
+		attrs = append(attrs, "request_id", requestID)
+	}
+
+	level := slog.LevelInfo
+	if resp.StatusCode >= 500 {
+		level = slog.LevelWarn
+	}
+	logger.Log(ctx, level, "http response", attrs...)
+
+	if debug {
+		logger.DebugContext(ctx, "http response body",
+			"status", resp.StatusCode,
+			"headers", redactHeaders(resp.Header),
+			"body", responseBody(resp))
+	}
+
+	return resp, nil
+}
+
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if len(query) == 0 {
+		return u.String()
+	}
+
+	for name := range query {
+		if isSensitiveParam(name) {
+			query.Set(name, redacted)
+		}
+	}
+
+	clean := *u
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// requestBody returns a redacted copy of the body, read through GetBody so
+// the request itself is untouched.
+func requestBody(req *http.Request) string {
+	if req.GetBody == nil || req.ContentLength == 0 {
+		return ""
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(body, maxLoggedBody+1))
+	return redactBody(req.Header.Get("Content-Type"), data)
+}
+
+// responseBody reads the start of the body for logging and puts it back in
+// front of the rest, so the caller still reads the whole body.
+func responseBody(resp *http.Response) string {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+
+	return redactBody(resp.Header.Get("Content-Type"), data)
+}
+
+func redactBody(contentType string, data []byte) string {
+	truncated := len(data) > maxLoggedBody
+	if truncated {
+		data = data[:maxLoggedBody]
+	}
+
+	var text string
+	switch {
+	case strings.HasPrefix(contentType, "multipart/"):
+		return "[multipart body]"
+	case strings.HasPrefix(contentType, "application/zip"), strings.HasPrefix(contentType, "application/octet-stream"):
+		return "[binary body]"
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(string(data))
+		if err != nil {
+			return "[unparsable form]"
+		}
+		for name := range form {
+			if isSensitiveParam(name) {
+				form.Set(name, redacted)
+			}
+		}
+		text = form.Encode()
+	default:
+		var value interface{}
+		if json.Unmarshal(data, &value) != nil {
+			if truncated {
+				return "[truncated body]"
+			}
+			text = string(data)
+			break
+		}
+		clean, _ := json.Marshal(redactJSON(value))
+		text = string(clean)
+	}
+
+	if truncated {
+		text += "…"
+	}
+	return text
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, isString := field.(string); isString && sensitiveFieldPattern.MatchString(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactJSON(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
@@ -4,11 +4,18 @@ import (
 	"bufio"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
 	"strings"
 	"syscall"
@@ -16,6 +23,7 @@ import (
 
 	"github.com/devlyspace/dashspace-cli/internal/api"
 	"github.com/devlyspace/dashspace-cli/internal/config"
+	"github.com/devlyspace/dashspace-cli/internal/credentials"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -23,21 +31,45 @@ import (
 // ====== COMMAND DEFINITIONS ======
 
 func NewLoginCmd() *cobra.Command {
-	var webLogin bool
+	var (
+		webLogin, deviceLogin, tokenStdin bool
+		credentialStore                   string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "login",
 		Short: "Login to DashSpace",
-		Long:  "Authenticate with DashSpace to publish modules",
+		Long: `Authenticate with DashSpace to publish modules.
+
+Use --device on machines without a browser (SSH sessions, containers): the CLI
+shows a code to enter on any other device.
+
+In CI, pipe a personal access token with --token-stdin, or set DASHSPACE_TOKEN
+without logging in at all (see 'dashspace tokens create').`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if credentialStore != "" {
+				if err := config.SetCredentialStore(credentialStore); err != nil {
+					return err
+				}
+			}
+			if tokenStdin {
+				return handleTokenLogin(cmd.Context(), os.Stdin)
+			}
+			if deviceLogin {
+				return handleDeviceLogin(cmd.Context())
+			}
 			if webLogin {
-				return handleWebLogin()
+				return handleWebLogin(cmd.Context())
 			}
-			return handleLogin()
+			return handleLogin(cmd.Context())
 		},
 	}
 
 	cmd.Flags().BoolVarP(&webLogin, "web", "w", false, "Use web browser for authentication")
+	cmd.Flags().BoolVar(&deviceLogin, "device", false, "Authenticate with a code entered on another device (for headless machines)")
+	cmd.Flags().StringVar(&credentialStore, "credential-store", "", "Where to keep the token: keyring, file (encrypted) or env (DASHSPACE_TOKEN)")
+	cmd.Flags().BoolVar(&tokenStdin, "token-stdin", false, "Read a personal access token from stdin (for CI)")
+	cmd.MarkFlagsMutuallyExclusive("web", "device", "token-stdin")
 
 	return cmd
 }
@@ -47,7 +79,7 @@ func NewLogoutCmd() *cobra.Command {
 		Use:   "logout",
 		Short: "Logout from DashSpace",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return handleLogout()
+			return handleLogout(cmd.Context())
 		},
 	}
 }
@@ -57,14 +89,14 @@ func NewWhoamiCmd() *cobra.Command {
 		Use:   "whoami",
 		Short: "Display current logged in user",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return handleWhoami()
+			return handleWhoami(cmd.Context())
 		},
 	}
 }
 
 // ====== HANDLER FUNCTIONS ======
 
-func handleLogin() error {
+func handleLogin(ctx context.Context) error {
 	fmt.Println("🔐 Login to DashSpace")
 	fmt.Println("───────────────────")
 
@@ -87,67 +119,160 @@ func handleLogin() error {
 	// Authenticate
 	fmt.Println("\n⏳ Authenticating...")
 	client := api.NewClient()
-	authResponse, err := client.Login(email, password)
+	authResponse, err := client.Login(ctx, email, password)
+	if errors.Is(err, api.ErrUnauthorized) {
+		return fmt.Errorf("❌ Authentication failed: invalid email or password")
+	}
 	if err != nil {
 		return fmt.Errorf("❌ Authentication failed: %v", err)
 	}
 
-	// Save credentials
-	cfg := config.GetConfig()
-	cfg.AuthToken = authResponse.Token
-	cfg.Username = authResponse.User.Username
-	cfg.Email = authResponse.User.Email
-
-	if err := config.SaveConfig(); err != nil {
-		return fmt.Errorf("failed to save configuration: %v", err)
+	if err := saveAuthConfig(authResponse); err != nil {
+		return err
 	}
 
-	fmt.Printf("\n✅ Successfully logged in as %s\n", cfg.Username)
 	fmt.Println("📦 You can now publish modules to DashSpace!")
 
 	return nil
 }
 
-func handleWebLogin() error {
+func handleWebLogin(ctx context.Context) error {
 	fmt.Println("🚀 Opening DashSpace app for authentication...")
 
-	state := generateRandomState()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, webLoginTimeout)
+	defer cancel()
+
+	authResponse, err := runWebLogin(ctx, api.NewClient(), func(deepLink string) error {
+		fmt.Printf("🔗 If the app doesn't open automatically, click: %s\n", deepLink)
+		fmt.Println("⏳ Waiting for authentication...")
 
-	// Deep link to the app
-	deepLink := fmt.Sprintf("dashspace://auth/cli?state=%s", state)
+		if err := openDeepLink(deepLink); err != nil {
+			fmt.Printf("⚠️  Failed to open app: %v\n", err)
+			fmt.Println("💡 Please open DashSpace app manually and go to Settings > CLI Authentication")
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("authentication failed: %v", err)
+	}
+
+	return saveAuthConfig(authResponse)
+}
+
+func handleDeviceLogin(ctx context.Context) error {
+	fmt.Println("🔐 Login to DashSpace from another device")
+	fmt.Println("───────────────────")
 
-	fmt.Printf("🔗 If the app doesn't open automatically, click: %s\n", deepLink)
-	fmt.Println("⏳ Waiting for authentication...")
+	client := api.NewClient()
+	code, err := client.RequestDeviceCode(ctx, api.CLIClientID)
+	if err != nil {
+		return fmt.Errorf("failed to start device login: %v", err)
+	}
 
-	if err := openDeepLink(deepLink); err != nil {
-		fmt.Printf("⚠️  Failed to open app: %v\n", err)
-		fmt.Println("💡 Please open DashSpace app manually and go to Settings > CLI Authentication")
+	fmt.Printf("\n1. Open %s on any device\n", code.VerificationURI)
+	fmt.Printf("2. Enter the code: %s\n", code.UserCode)
+	if code.VerificationURIComplete != "" {
+		fmt.Printf("\n🔗 Or open directly: %s\n", code.VerificationURIComplete)
 	}
+	fmt.Printf("\n⏳ Waiting for approval (code expires in %d minutes)...\n", (code.ExpiresIn+59)/60)
 
-	authResponse, err := waitForAuthCallback(state)
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	token, err := client.WaitForDeviceToken(ctx, api.CLIClientID, code)
 	if err != nil {
-		return fmt.Errorf("authentication failed: %v", err)
+		switch {
+		case errors.Is(err, api.ErrAccessDenied):
+			return fmt.Errorf("❌ Login was denied")
+		case errors.Is(err, api.ErrExpiredToken):
+			return fmt.Errorf("❌ The code expired, run 'dashspace login --device' again")
+		case errors.Is(err, context.Canceled):
+			return fmt.Errorf("login cancelled")
+		}
+		return fmt.Errorf("❌ Authentication failed: %v", err)
 	}
 
-	return saveAuthConfig(authResponse)
+	user, err := client.GetUserWithToken(ctx, token.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user profile: %v", err)
+	}
+
+	return saveAuthConfig(&api.AuthResponse{
+		Token:        token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresIn:    token.ExpiresIn,
+		User:         *user,
+	})
 }
 
-func handleLogout() error {
+// handleTokenLogin saves a personal access token read from r, after checking
+// it against the API. Reading from stdin keeps the token out of the shell
+// history and the process list.
+func handleTokenLogin(ctx context.Context, r io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(r, 16*1024))
+	if err != nil {
+		return fmt.Errorf("error reading token: %v", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return fmt.Errorf("no token on stdin, e.g. echo \"$TOKEN\" | dashspace login --token-stdin")
+	}
+
+	client := api.NewClient()
+	user, err := client.GetUserWithToken(ctx, token)
+	if errors.Is(err, api.ErrUnauthorized) {
+		return fmt.Errorf("❌ Authentication failed: the token is invalid, expired or revoked")
+	}
+	if err != nil {
+		return fmt.Errorf("❌ Authentication failed: %v", err)
+	}
+
+	return saveAuthConfig(&api.AuthResponse{Token: token, User: *user})
+}
+
+func handleLogout(ctx context.Context) error {
+	if err := config.LoadCredentials(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
 	cfg := config.GetConfig()
 
-	if cfg.AuthToken == "" {
+	if cfg.AuthToken == "" && cfg.Username == "" {
 		fmt.Println("❌ You are not logged in")
 		return nil
 	}
 
-	// Clear auth info
-	config.ClearAuth()
+	if os.Getenv(credentials.TokenEnvVar) != "" {
+		fmt.Printf("⚠️  %s is set, unset it to stop using that token\n", credentials.TokenEnvVar)
+	} else {
+		// Revoke server-side so a leaked copy of the token is useless too
+		client := api.NewClient()
+		if cfg.RefreshToken != "" {
+			if err := client.RevokeToken(ctx, cfg.RefreshToken, "refresh_token"); err != nil {
+				fmt.Printf("⚠️  Failed to revoke refresh token: %v\n", err)
+			}
+		}
+		if cfg.AuthToken != "" {
+			if err := client.RevokeToken(ctx, cfg.AuthToken, "access_token"); err != nil {
+				fmt.Printf("⚠️  Failed to revoke access token: %v\n", err)
+			}
+		}
+	}
+
+	// Purge credentials from every store
+	if err := config.ClearAuth(); err != nil {
+		return fmt.Errorf("logged out, but some credentials could not be removed: %v", err)
+	}
 
 	fmt.Println("✅ Successfully logged out")
 	return nil
 }
 
-func handleWhoami() error {
+func handleWhoami(ctx context.Context) error {
+	if err := config.LoadCredentials(); err != nil {
+		return err
+	}
 	cfg := config.GetConfig()
 
 	if cfg.AuthToken == "" {
@@ -159,13 +284,22 @@ func handleWhoami() error {
 	fmt.Println("👤 Current user:")
 	fmt.Printf("   Username: %s\n", cfg.Username)
 	fmt.Printf("   Email: %s\n", cfg.Email)
+	fmt.Printf("   Credentials: %s\n", config.CredentialStoreName())
 
-	// Optionally verify token is still valid
+	// Verify the token is still valid, refreshing it if needed
 	client := api.NewClient()
-	if _, err := client.GetProfile(); err != nil {
-		fmt.Println("\n⚠️  Your session may have expired. Please login again.")
-	} else {
-		fmt.Println("\n✅ Session is active")
+	if _, err := client.GetProfile(ctx); err != nil {
+		if errors.Is(err, api.ErrSessionExpired) {
+			fmt.Printf("\n❌ %v\n", err)
+			return nil
+		}
+		fmt.Printf("\n⚠️  Could not verify your session: %v\n", err)
+		return nil
+	}
+
+	fmt.Println("\n✅ Session is active")
+	if cfg.TokenExpiry != 0 {
+		fmt.Printf("   Token expires: %s\n", time.Unix(cfg.TokenExpiry, 0).Format(time.RFC1123))
 	}
 
 	return nil
@@ -173,39 +307,128 @@ func handleWhoami() error {
 
 // ====== UTILITY FUNCTIONS ======
 
-func waitForAuthCallback(expectedState string) (*api.AuthResponse, error) {
-	resultChan := make(chan *api.AuthResponse, 1)
+const webLoginTimeout = 5 * time.Minute
+
+// runWebLogin performs the app/browser login: it listens for the callback on
+// an ephemeral loopback port, hands the deep link to open, then exchanges the
+// returned authorization code using PKCE. open is injectable for tests.
+func runWebLogin(ctx context.Context, client *api.Client, open func(deepLink string) error) (*api.AuthResponse, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback server: %v", err)
+	}
+
+	redirectURI := fmt.Sprintf("http://%s/auth/callback", listener.Addr().String())
+	state := generateRandomState()
+	verifier := generateRandomState()
+	challenge := sha256.Sum256([]byte(verifier))
+
+	params := url.Values{}
+	params.Set("client_id", api.CLIClientID)
+	params.Set("state", state)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	params.Set("code_challenge_method", "S256")
+	deepLink := "dashspace://auth/cli?" + params.Encode()
+
+	codeChan := make(chan string, 1)
 	errorChan := make(chan error, 1)
 
-	// Start temporary server to receive callback
-	server := &http.Server{Addr: ":8080"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/callback", authCallbackHandler(state, codeChan, errorChan))
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			errorChan <- err
+		}
+	}()
+	defer server.Shutdown(context.Background())
+
+	if err := open(deepLink); err != nil {
+		return nil, err
+	}
+
+	var code string
+	select {
+	case code = <-codeChan:
+	case err := <-errorChan:
+		return nil, err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("authentication timeout")
+		}
+		return nil, fmt.Errorf("login cancelled")
+	}
+
+	token, err := client.ExchangeAuthCode(ctx, api.CLIClientID, code, verifier, redirectURI)
+	if err != nil {
+		return nil, fmt.Errorf("code exchange failed: %v", err)
+	}
+
+	user, err := client.GetUserWithToken(ctx, token.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user profile: %v", err)
+	}
+
+	return &api.AuthResponse{
+		Token:        token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresIn:    token.ExpiresIn,
+		User:         *user,
+	}, nil
+}
 
-	http.HandleFunc("/auth/callback", func(w http.ResponseWriter, r *http.Request) {
-		state := r.URL.Query().Get("state")
-		token := r.URL.Query().Get("token")
-		username := r.URL.Query().Get("username")
-		email := r.URL.Query().Get("email")
+// authCallbackHandler accepts the first callback carrying the expected state.
+// Requests with a wrong state are rejected without ending the login, so a
+// stray local request can't abort it.
+func authCallbackHandler(expectedState string, codeChan chan<- string, errorChan chan<- error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-		if state != expectedState {
+		query := r.URL.Query()
+		if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(expectedState)) != 1 {
 			http.Error(w, "Invalid state", http.StatusBadRequest)
-			errorChan <- fmt.Errorf("invalid state parameter")
 			return
 		}
 
-		if token == "" {
-			errorMessage := r.URL.Query().Get("error")
+		code := query.Get("code")
+		if code == "" {
+			errorMessage := query.Get("error_description")
 			if errorMessage == "" {
-				errorMessage = "No token received"
+				errorMessage = query.Get("error")
+			}
+			if errorMessage == "" {
+				errorMessage = "No authorization code received"
 			}
 			http.Error(w, errorMessage, http.StatusBadRequest)
-			errorChan <- fmt.Errorf("authentication failed: %s", errorMessage)
+			select {
+			case errorChan <- fmt.Errorf("%s", errorMessage):
+			default:
+			}
+			return
+		}
+
+		select {
+		case codeChan <- code:
+		default:
+			http.Error(w, "Authentication already completed", http.StatusConflict)
 			return
 		}
 
-		// Success response
 		w.Header().Set("Content-Type", "text/html")
 		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`
+		w.Write([]byte(authSuccessPage))
+	}
+}
+
+const authSuccessPage = `
             <!DOCTYPE html>
             <html>
             <head>
@@ -245,41 +468,7 @@ func waitForAuthCallback(expectedState string) (*api.AuthResponse, error) {
                 </script>
             </body>
             </html>
-        `))
-
-		authResponse := &api.AuthResponse{
-			Token: token,
-			User: api.User{
-				Username: username,
-				Email:    email,
-			},
-		}
-
-		resultChan <- authResponse
-	})
-
-	go func() {
-		if err := server.ListenAndServe(); err != http.ErrServerClosed {
-			errorChan <- err
-		}
-	}()
-
-	// Wait for result or timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	select {
-	case authResponse := <-resultChan:
-		server.Shutdown(context.Background())
-		return authResponse, nil
-	case err := <-errorChan:
-		server.Shutdown(context.Background())
-		return nil, err
-	case <-ctx.Done():
-		server.Shutdown(context.Background())
-		return nil, fmt.Errorf("authentication timeout after 5 minutes")
-	}
-}
+        `
 
 func openDeepLink(link string) error {
 	var cmd string
@@ -305,20 +494,25 @@ func openDeepLink(link string) error {
 func generateRandomState() string {
 	b := make([]byte, 32)
 	rand.Read(b)
-	return base64.URLEncoding.EncodeToString(b)
+	return base64.RawURLEncoding.EncodeToString(b)
 }
 
 func saveAuthConfig(authResponse *api.AuthResponse) error {
 	cfg := config.GetConfig()
 
-	cfg.AuthToken = authResponse.Token
+	cfg.RefreshToken = ""
+	cfg.SetTokens(authResponse.Token, authResponse.RefreshToken, authResponse.ExpiresIn)
 	cfg.Username = authResponse.User.Username
 	cfg.Email = authResponse.User.Email
 
+	if err := config.SaveCredentials(); err != nil {
+		return err
+	}
 	if err := config.SaveConfig(); err != nil {
 		return fmt.Errorf("failed to save configuration: %v", err)
 	}
 
 	fmt.Printf("\n✅ Successfully logged in as %s\n", cfg.Username)
+	fmt.Printf("🔐 Token saved in the %s\n", config.CredentialStoreName())
 	return nil
 }
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/devlyspace/dashspace-cli/internal/api"
+	"github.com/devlyspace/dashspace-cli/test"
+)
+
+// fakeAuthServer stands in for the token and profile endpoints. The token
+// endpoint only accepts code with the verifier of the challenge it was given.
+func fakeAuthServer(t *testing.T, code string, challenge *string) *api.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("code") != code ||
+			base64.RawURLEncoding.EncodeToString(verifier[:]) != *challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(api.TokenResponse{AccessToken: "access-token", RefreshToken: "refresh-token", TokenType: "Bearer"})
+	})
+	mux.HandleFunc("/auth/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.User{ID: 1, Username: "octocat", Email: "octocat@example.test"})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return api.NewClientWithBaseURL(server.URL, api.WithRetries(0))
+}
+
+// fakeBrowser returns an open function that records the deep link and
+// follows it the way the app would, by calling back with the given query.
+func fakeBrowser(t *testing.T, challenge *string, callbacks ...func(state string) url.Values) func(string) error {
+	return func(deepLink string) error {
+		link, err := url.Parse(deepLink)
+		if err != nil {
+			t.Fatalf("invalid deep link %q: %v", deepLink, err)
+		}
+		params := link.Query()
+		test.AssertEqual(t, params.Get("client_id"), api.CLIClientID)
+		test.AssertEqual(t, params.Get("code_challenge_method"), "S256")
+		*challenge = params.Get("code_challenge")
+
+		redirectURI := params.Get("redirect_uri")
+		if !strings.HasPrefix(redirectURI, "http://127.0.0.1:") {
+			t.Fatalf("redirect_uri %q is not on the loopback interface", redirectURI)
+		}
+
+		go func() {
+			for _, callback := range callbacks {
+				resp, err := http.Get(redirectURI + "?" + callback(params.Get("state")).Encode())
+				if err != nil {
+					t.Errorf("callback failed: %v", err)
+					return
+				}
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+}
+
+func withCode(code string) func(state string) url.Values {
+	return func(state string) url.Values {
+		return url.Values{"code": {code}, "state": {state}}
+	}
+}
+
+func TestRunWebLogin(t *testing.T) {
+	var challenge string
+	client := fakeAuthServer(t, "auth-code", &challenge)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	auth, err := runWebLogin(ctx, client, fakeBrowser(t, &challenge, withCode("auth-code")))
+	if err != nil {
+		t.Fatalf("runWebLogin: %v", err)
+	}
+	test.AssertEqual(t, auth.Token, "access-token")
+	test.AssertEqual(t, auth.RefreshToken, "refresh-token")
+	test.AssertEqual(t, auth.User.Username, "octocat")
+}
+
+func TestRunWebLoginIgnoresStateMismatch(t *testing.T) {
+	var challenge string
+	client := fakeAuthServer(t, "auth-code", &challenge)
+
+	// A callback with another state is rejected without ending the login
+	forged := func(state string) url.Values {
+		return url.Values{"code": {"forged-code"}, "state": {"forged-state"}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	auth, err := runWebLogin(ctx, client, fakeBrowser(t, &challenge, forged, withCode("auth-code")))
+	if err != nil {
+		t.Fatalf("runWebLogin: %v", err)
+	}
+	test.AssertEqual(t, auth.Token, "access-token")
+}
+
+func TestAuthCallbackHandlerStateMismatch(t *testing.T) {
+	codeChan := make(chan string, 1)
+	errorChan := make(chan error, 1)
+	handler := authCallbackHandler("expected-state", codeChan, errorChan)
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest("GET", "/auth/callback?code=auth-code&state=forged-state", nil))
+	test.AssertEqual(t, recorder.Code, http.StatusBadRequest)
+	test.AssertEqual(t, len(codeChan), 0)
+	test.AssertEqual(t, len(errorChan), 0)
+}
+
+func TestRunWebLoginCallbackError(t *testing.T) {
+	var challenge string
+	client := fakeAuthServer(t, "auth-code", &challenge)
+
+	denied := func(state string) url.Values {
+		return url.Values{"error": {"access_denied"}, "error_description": {"The user denied access"}, "state": {state}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := runWebLogin(ctx, client, fakeBrowser(t, &challenge, denied))
+	if err == nil || err.Error() != "The user denied access" {
+		t.Fatalf("expected the callback error, got %v", err)
+	}
+}
+
+func TestRunWebLoginRejectsWrongVerifier(t *testing.T) {
+	var challenge string
+	client := fakeAuthServer(t, "auth-code", &challenge)
+
+	// The server saw another challenge, so the CLI's verifier does not match
+	open := fakeBrowser(t, &challenge, withCode("auth-code"))
+	tamper := func(deepLink string) error {
+		err := open(deepLink)
+		challenge = "another-challenge"
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := runWebLogin(ctx, client, tamper)
+	if err == nil || !strings.Contains(err.Error(), "code exchange failed") {
+		t.Fatalf("expected the code exchange to fail, got %v", err)
+	}
+}
+
+func TestRunWebLoginTimeout(t *testing.T) {
+	var challenge string
+	client := fakeAuthServer(t, "auth-code", &challenge)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := runWebLogin(ctx, client, fakeBrowser(t, &challenge))
+	if err == nil || err.Error() != "authentication timeout" {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestRunWebLoginCancelled(t *testing.T) {
+	var challenge string
+	client := fakeAuthServer(t, "auth-code", &challenge)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	open := fakeBrowser(t, &challenge)
+	_, err := runWebLogin(ctx, client, func(deepLink string) error {
+		err := open(deepLink)
+		cancel()
+		return err
+	})
+	if err == nil || err.Error() != "login cancelled" {
+		t.Fatalf("expected the login to be cancelled, got %v", err)
+	}
+}
@@ -1,10 +1,13 @@
 package build
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -19,6 +22,7 @@ type BuildOptions struct {
 	SkipChecks bool
 	Strict     bool
 	NoStrict   bool
+	Init       bool
 }
 
 func NewBuildCmd() *cobra.Command {
@@ -38,7 +42,8 @@ The build process includes the following validation steps by default:
    - Checks package.json structure
 
 2. TYPESCRIPT VALIDATION (--strict mode, default)
-   - Type checking with tsc --noEmit
+   - One incremental tsc run with the project's tsconfig (tsc --build for
+     project references), cached in .dashspace/cache
    - Interface implementation validation
    - dashspace-lib type compatibility
    - Unused imports detection
@@ -63,6 +68,10 @@ The build process includes the following validation steps by default:
    - JSX transformation
    - Tree shaking for optimal bundle size
    - Minification (production mode)
+   - Permission audit: storage, notification/modal, network and provider
+     calls of the sources and bundle against the declared permissions
+   - Provider scope check: unknown scopes, and scopes the called endpoints
+     need or could narrow, from the bundled provider catalogs
 
 6. BUNDLE GENERATION
    - Wraps module in Dashspace loader
@@ -78,6 +87,8 @@ FLAGS:
 By default, the build runs in strict mode with all validations enabled.
 Use --no-strict to disable strict mode and treat warnings as non-fatal.
 Use --skip-checks to skip TypeScript and linting validation entirely (not recommended).
+Config files are never written silently: a missing tsconfig.json or ESLint config
+is created with --init, or after asking in a terminal.
 
 EXAMPLES:
   dashspace build
@@ -85,6 +96,7 @@ EXAMPLES:
   dashspace build --watch
   dashspace build --no-strict
   dashspace build --skip-checks
+  dashspace build --init
   dashspace build -o ./my-dist`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if opts.NoStrict {
@@ -93,7 +105,10 @@ EXAMPLES:
 				opts.Strict = true
 			}
 
-			return buildModule(opts)
+			if !opts.Watch {
+				return buildModule(context.Background(), opts)
+			}
+			return watchModule(opts)
 		},
 	}
 
@@ -104,6 +119,7 @@ EXAMPLES:
 	cmd.Flags().StringVar(&opts.Format, "format", "js", "Output format: js")
 	cmd.Flags().BoolVar(&opts.SkipChecks, "skip-checks", false, "Skip TypeScript and linting checks (not recommended)")
 	cmd.Flags().BoolVar(&opts.NoStrict, "no-strict", false, "Disable strict mode (warnings won't fail the build)")
+	cmd.Flags().BoolVar(&opts.Init, "init", false, "Create a missing tsconfig.json and .eslintrc.json with the defaults")
 
 	cmd.Flags().BoolVar(&opts.Strict, "strict", true, "Enable strict mode (default)")
 	cmd.Flags().MarkHidden("strict")
@@ -111,7 +127,30 @@ EXAMPLES:
 	return cmd
 }
 
-func buildModule(opts BuildOptions) error {
+// watchModule builds once, then rebuilds on every change until interrupted.
+func watchModule(opts BuildOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := buildModule(ctx, opts); err != nil {
+		fmt.Printf("❌ Build failed: %v\n", err)
+	}
+
+	watcher := NewWatcher(".")
+	watcher.Ignore(opts.Output)
+
+	fmt.Println("\n👀 Watching for changes...")
+	return watcher.Watch(ctx, func(ctx context.Context, changed []string) {
+		if err := buildModule(ctx, opts); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			fmt.Printf("❌ Build failed: %v\n", err)
+		}
+	})
+}
+
+func buildModule(ctx context.Context, opts BuildOptions) error {
 	startTime := time.Now()
 	fmt.Println("🚀 Building Dashspace module...")
 
@@ -127,7 +166,7 @@ func buildModule(opts BuildOptions) error {
 		return err
 	}
 
-	if err := ensureDependencies(); err != nil {
+	if err := ensureDependencies(ctx); err != nil {
 		return err
 	}
 
@@ -135,7 +174,8 @@ func buildModule(opts BuildOptions) error {
 		fmt.Println("\n📋 Running validation suite...")
 		fmt.Println("╔═══════════════════════════════════════════════════╗")
 
-		tsValidator := NewTypeScriptValidator(".")
+		tsValidator := NewTypeScriptValidator(ctx, ".")
+		tsValidator.Init = opts.Init
 
 		fmt.Println("\n1️⃣  TypeScript Type Checking")
 		if err := tsValidator.Validate(); err != nil {
@@ -169,7 +209,8 @@ func buildModule(opts BuildOptions) error {
 		tsValidator.ValidateSatisfiesUsage()
 
 		fmt.Println("\n6️⃣  ESLint Code Quality")
-		linter := NewLintingValidator(".")
+		linter := NewLintingValidator(ctx, ".")
+		linter.Init = opts.Init
 		if err := linter.RunESLint(); err != nil {
 			if opts.Strict {
 				return err
@@ -196,6 +237,10 @@ func buildModule(opts BuildOptions) error {
 		fmt.Println("   Run without --skip-checks to enable full validation")
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if err := os.MkdirAll(opts.Output, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
@@ -326,19 +371,25 @@ func buildModule(opts BuildOptions) error {
 	dataSchemaExtractor := NewDataSchemaExtractor()
 	dataSchema, err := dataSchemaExtractor.ExtractDataSchema()
 	if err != nil {
+		if opts.Strict {
+			return fmt.Errorf("failed to extract data schema: %w", err)
+		}
 		fmt.Printf("⚠️  Warning: Failed to extract data schema: %v\n", err)
 		dataSchema = nil
 	} else if dataSchema != nil && dataSchema.ExposeData {
 		fmt.Printf("✅ Module exposes data:\n")
-		if dataSchema.DataType != "" {
-			fmt.Printf("   Type: %s\n", dataSchema.DataType)
-		}
-		if dataSchema.Schema != nil {
-			fmt.Printf("   Fields: %d\n", len(dataSchema.Schema.Fields))
-			fmt.Printf("   Capabilities: %d\n", len(dataSchema.Schema.Capabilities))
-		}
-		if len(dataSchema.ComputedFields) > 0 {
-			fmt.Printf("   Computed metrics: %d\n", len(dataSchema.ComputedFields))
+		for _, provider := range dataSchema.Providers {
+			fmt.Printf("   Provider: %s\n", provider.ID)
+			if provider.DataType != "" {
+				fmt.Printf("     Type: %s\n", provider.DataType)
+			}
+			if provider.Schema != nil {
+				fmt.Printf("     Fields: %d\n", len(provider.Schema.Fields))
+				fmt.Printf("     Capabilities: %d\n", len(provider.Schema.Capabilities))
+			}
+			if len(provider.ComputedFields) > 0 {
+				fmt.Printf("     Computed metrics: %d\n", len(provider.ComputedFields))
+			}
 		}
 
 		if !opts.SkipChecks {
@@ -358,6 +409,10 @@ func buildModule(opts BuildOptions) error {
 		return fmt.Errorf("no entry point found")
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	fmt.Printf("\n📦 Compiling %s...\n", entryPoint)
 	compiler := NewCompiler(opts)
 	bundleContent, err := compiler.Compile(entryPoint)
@@ -365,6 +420,21 @@ func buildModule(opts BuildOptions) error {
 		return fmt.Errorf("compilation failed: %w", err)
 	}
 
+	if !opts.SkipChecks {
+		if err := auditPermissions(permissions, providers, bundleContent); err != nil {
+			if opts.Strict {
+				return err
+			}
+			fmt.Printf("⚠️  Warning: %v\n", err)
+		}
+		if err := auditProviderScopes(providers); err != nil {
+			if opts.Strict {
+				return err
+			}
+			fmt.Printf("⚠️  Warning: %v\n", err)
+		}
+	}
+
 	fmt.Println("🔧 Generating bundle...")
 	generator := NewGenerator(config)
 	finalBundle, checksum := generator.Generate(bundleContent)
@@ -389,13 +459,6 @@ func buildModule(opts BuildOptions) error {
 	duration := time.Since(startTime)
 	printBuildSummary(config, opts.Output, duration, opts.Strict, dataSchema)
 
-	if opts.Watch {
-		watcher := NewWatcher()
-		return watcher.Watch(func() {
-			buildModule(opts)
-		})
-	}
-
 	return nil
 }
 
@@ -486,7 +549,11 @@ func printBuildSummary(config *DashspaceConfig, outputDir string, duration time.
 	}
 
 	if dataSchema != nil && dataSchema.ExposeData {
-		fmt.Printf("   Data:        Exposed (%s)\n", dataSchema.DataType)
+		if len(dataSchema.Providers) > 1 {
+			fmt.Printf("   Data:        Exposed (%d providers)\n", len(dataSchema.Providers))
+		} else {
+			fmt.Printf("   Data:        Exposed (%s)\n", dataSchema.DataType)
+		}
 	}
 
 	fmt.Printf("\n")
@@ -550,6 +617,10 @@ func BuildManifest(
 		manifest["tags"] = config.Tags
 	}
 
+	if config.Size != nil {
+		manifest["size"] = config.Size
+	}
+
 	if config.RequiresSetup && len(configSteps) > 0 {
 		manifest["configuration_steps"] = configSteps
 	}
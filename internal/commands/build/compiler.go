@@ -14,11 +14,21 @@ func NewCompiler(options BuildOptions) *Compiler {
 }
 
 func (c *Compiler) Compile(entryPoint string) (string, error) {
+	return c.compile(entryPoint, api.FormatIIFE)
+}
+
+// CompileCommonJS bundles entryPoint as a CommonJS module, to run it outside
+// the dashboard.
+func (c *Compiler) CompileCommonJS(entryPoint string) (string, error) {
+	return c.compile(entryPoint, api.FormatCommonJS)
+}
+
+func (c *Compiler) compile(entryPoint string, format api.Format) (string, error) {
 	result := api.Build(api.BuildOptions{
 		EntryPoints:       []string{entryPoint},
 		Bundle:            true,
 		Write:             false,
-		Format:            api.FormatIIFE,
+		Format:            format,
 		Platform:          api.PlatformBrowser,
 		Target:            api.ES2020,
 		MinifyWhitespace:  c.options.Minify,
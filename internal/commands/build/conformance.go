@@ -0,0 +1,429 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devlyspace/dashspace-cli/internal/uql"
+)
+
+// InterfaceTestOptions configures RunInterfaceTests.
+type InterfaceTestOptions struct {
+	// Interfaces restricts the run to some declared interfaces
+	Interfaces []string
+	// CallTimeout bounds each handler call
+	CallTimeout time.Duration
+	// ShowLogs prints the module's console output after the run
+	ShowLogs bool
+}
+
+// conformanceCase calls one interface method and checks what it returns.
+type conformanceCase struct {
+	Method string
+	// Args returns the argument lists to call the method with, given the
+	// results of the earlier cases of the interface
+	Args func(previous map[string]*HarnessResult) [][]interface{}
+	// Check validates a successful result and describes it
+	Check func(r *HarnessResult) (string, error)
+	// Skip explains why the method is not called
+	Skip string
+}
+
+// callback is passed to handlers as a no-op function.
+var callback = map[string]interface{}{"$function": true}
+
+// sampleUQLQuery is passed to translateUQLQuery.
+var sampleUQLQuery = uql.MustParse(`title contains "test" limit 10`)
+
+// conformanceCases are run in order for each interface; methods of a
+// contract without a case are reported as not exercised.
+var conformanceCases = map[string][]conformanceCase{
+	"ISearchable": {
+		{Method: "search", Args: with([]interface{}{"test"}, []interface{}{""}), Check: expectSearchItems},
+		{Method: "getSearchResults", Args: with(nil), Check: expectSearchItems},
+		{Method: "getSearchFilters", Args: with(nil), Check: expectArray},
+		{Method: "getUQLCapabilities", Args: with(nil), Check: expectUQLCapabilities},
+		{Method: "translateUQLQuery", Args: with([]interface{}{sampleUQLQuery}), Check: expectDefined},
+	},
+	"IRefreshable": {
+		{Method: "refresh", Args: with(nil), Check: expectNoError},
+		{Method: "getLastRefresh", Args: with(nil), Check: expectDateLike},
+		{Method: "setAutoRefresh", Args: with([]interface{}{false}), Check: expectNoError},
+	},
+	"IExportable": {
+		{Method: "getSupportedFormats", Args: with(nil), Check: expectStringList},
+		{Method: "exportData", Args: eachSupportedFormat, Check: expectDefined},
+		{Method: "export", Skip: "triggers a download, needs a browser"},
+	},
+	"IFilterable": {
+		{Method: "getCurrentFilter", Args: with(nil), Check: expectObjectOrNull},
+		{Method: "applyFilter", Args: currentFilter, Check: expectNoError},
+		{Method: "clearFilters", Args: with(nil), Check: expectNoError},
+		{Method: "getUQLCapabilities", Args: with(nil), Check: expectUQLCapabilities},
+		{Method: "translateUQLQuery", Args: with([]interface{}{sampleUQLQuery}), Check: expectDefined},
+	},
+	"IThemeable": {
+		{Method: "getSupportedThemes", Args: with(nil), Check: expectStringList},
+		{Method: "getThemeConfig", Args: with(nil), Check: expectObject},
+		{Method: "applyTheme", Args: firstSupportedTheme, Check: expectNoError},
+	},
+	"INotifiable": {
+		{Method: "subscribe", Args: with([]interface{}{callback}), Check: expectNoError},
+		{Method: "unsubscribe", Args: with([]interface{}{callback}), Check: expectNoError},
+		{Method: "sendNotification", Args: with([]interface{}{map[string]interface{}{
+			"title": "dashspace test", "message": "Interface conformance test", "type": "info",
+		}}), Check: expectNoError},
+	},
+	"IDataProvider": {
+		{Method: "getData", Args: with(nil), Check: expectDefined},
+		{Method: "getDataSchema", Args: with(nil), Check: expectObject},
+		{Method: "subscribe", Args: with([]interface{}{callback}), Check: expectNoError},
+	},
+	"ISchedulable": {
+		{Method: "getSchedule", Args: with(nil), Check: expectNoError},
+		{Method: "schedule", Skip: "changes the module's schedule"},
+		{Method: "unschedule", Skip: "changes the module's schedule"},
+	},
+}
+
+// RunInterfaceTests calls the handlers of the interfaces declared in
+// Module.ts and checks their results against the expected shapes.
+func RunInterfaceTests(ctx context.Context, opts InterfaceTestOptions) error {
+	parser, err := NewModuleParser()
+	if err != nil {
+		return err
+	}
+	declared, err := parser.DeclaredInterfaces()
+	if err != nil {
+		return err
+	}
+
+	contracts := LoadInterfaceContracts(".")
+	fmt.Printf("📚 Interface contracts: %s\n", contracts.Source)
+
+	interfaces := contracts.Canonical(declared)
+	if len(opts.Interfaces) > 0 {
+		wanted := contracts.Canonical(opts.Interfaces)
+		for _, name := range wanted {
+			if !containsString(interfaces, name) {
+				return fmt.Errorf("interface %s is not declared in Module.ts", name)
+			}
+		}
+		interfaces = wanted
+	}
+	if len(interfaces) == 0 {
+		fmt.Println("ℹ️  Module.ts declares no interfaces, nothing to test")
+		return nil
+	}
+
+	componentFile := findComponentFile()
+	if componentFile == "" {
+		return fmt.Errorf("Component.tsx not found")
+	}
+
+	fmt.Printf("🧪 Rendering %s...\n", componentFile)
+	harness, err := StartHarness(ctx, ".", componentFile)
+	if err != nil {
+		return err
+	}
+	defer func() { harness.Close() }()
+	harness.CallTimeout = opts.CallTimeout
+
+	var logs strings.Builder
+	passed, failed, skipped := 0, 0, 0
+	for _, iface := range interfaces {
+		fmt.Printf("\n%s\n", iface)
+
+		methods, ok := harness.Interfaces[iface]
+		if !ok {
+			fmt.Println("   ❌ not passed to useModuleInterfaces")
+			failed++
+			continue
+		}
+
+		contract := &InterfaceContract{}
+		if _, c, ok := contracts.Lookup(iface); ok {
+			contract = c
+		}
+
+		tested := map[string]bool{}
+		previous := map[string]*HarnessResult{}
+		for _, tc := range conformanceCases[iface] {
+			tested[tc.Method] = true
+			implemented := containsString(methods, tc.Method)
+
+			switch {
+			case !implemented && containsString(contract.Optional, tc.Method):
+				continue
+			case !implemented:
+				fmt.Printf("   ❌ %s: not implemented\n", tc.Method)
+				failed++
+				continue
+			case tc.Skip != "":
+				fmt.Printf("   ⏭️  %s: %s\n", tc.Method, tc.Skip)
+				skipped++
+				continue
+			}
+
+			argLists := tc.Args(previous)
+			if len(argLists) == 0 {
+				fmt.Printf("   ⏭️  %s: nothing to call it with\n", tc.Method)
+				skipped++
+				continue
+			}
+			for _, args := range argLists {
+				call := fmt.Sprintf("%s(%s)", tc.Method, formatArgs(args))
+
+				result, err := harness.Call(iface, tc.Method, args...)
+				if err != nil {
+					fmt.Printf("   ❌ %s: %v\n", call, err)
+					failed++
+
+					// The process is gone with the handlers' state: render again
+					logs.WriteString(harness.Logs())
+					harness.Close()
+					if harness, err = StartHarness(ctx, ".", componentFile); err != nil {
+						return fmt.Errorf("interface tests aborted: %w", err)
+					}
+					harness.CallTimeout = opts.CallTimeout
+					continue
+				}
+				previous[tc.Method] = result
+
+				if !result.OK {
+					fmt.Printf("   ❌ %s threw: %s\n", call, result.Error)
+					failed++
+					continue
+				}
+				summary, err := tc.Check(result)
+				if err != nil {
+					fmt.Printf("   ❌ %s: %v\n", call, err)
+					failed++
+					continue
+				}
+				fmt.Printf("   ✅ %s → %s (%s)\n", call, summary, formatCallDuration(result.Duration))
+				passed++
+			}
+		}
+
+		// Contracts of newer dashspace-lib releases may add methods
+		untested := []string{}
+		for _, method := range append(append([]string{}, contract.Methods...), contract.Optional...) {
+			if !tested[method] && containsString(methods, method) {
+				untested = appendUnique(untested, method)
+			}
+		}
+		sort.Strings(untested)
+		for _, method := range untested {
+			fmt.Printf("   ⏭️  %s: no conformance check for this method yet\n", method)
+			skipped++
+		}
+	}
+
+	if opts.ShowLogs {
+		logs.WriteString(harness.Logs())
+		if output := strings.TrimSpace(logs.String()); output != "" {
+			fmt.Printf("\n📜 Module output:\n%s\n", output)
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+	if failed > 0 {
+		return fmt.Errorf("%d interface checks failed", failed)
+	}
+	fmt.Println("✅ All interface handlers conform")
+	return nil
+}
+
+func with(argLists ...[]interface{}) func(map[string]*HarnessResult) [][]interface{} {
+	return func(map[string]*HarnessResult) [][]interface{} {
+		return argLists
+	}
+}
+
+func eachSupportedFormat(previous map[string]*HarnessResult) [][]interface{} {
+	argLists := [][]interface{}{}
+	if r := previous["getSupportedFormats"]; r != nil && r.OK {
+		if formats, ok := r.Decoded().([]interface{}); ok {
+			for _, format := range formats {
+				argLists = append(argLists, []interface{}{format})
+			}
+		}
+	}
+	return argLists
+}
+
+func firstSupportedTheme(previous map[string]*HarnessResult) [][]interface{} {
+	if r := previous["getSupportedThemes"]; r != nil && r.OK {
+		if themes, ok := r.Decoded().([]interface{}); ok && len(themes) > 0 {
+			return [][]interface{}{{themes[0]}}
+		}
+	}
+	return nil
+}
+
+// currentFilter re-applies the filter reported by getCurrentFilter.
+func currentFilter(previous map[string]*HarnessResult) [][]interface{} {
+	if r := previous["getCurrentFilter"]; r != nil && r.OK && r.Type == "object" {
+		return [][]interface{}{{r.Decoded()}}
+	}
+	return [][]interface{}{{map[string]interface{}{}}}
+}
+
+func formatCallDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return "<1ms"
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+func formatArgs(args []interface{}) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if m, ok := arg.(map[string]interface{}); ok && m["$function"] == true {
+			parts[i] = "fn"
+			continue
+		}
+		data, _ := json.Marshal(arg)
+		parts[i] = string(data)
+		if len(parts[i]) > 40 {
+			parts[i] = parts[i][:37] + "..."
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func expectNoError(r *HarnessResult) (string, error) {
+	return r.Type, nil
+}
+
+func expectDefined(r *HarnessResult) (string, error) {
+	if r.Type == "undefined" || r.Type == "null" {
+		return "", fmt.Errorf("returned %s", r.Type)
+	}
+	return r.Type, nil
+}
+
+func expectObject(r *HarnessResult) (string, error) {
+	if r.Type != "object" {
+		return "", fmt.Errorf("expected an object, got %s", r.Type)
+	}
+	return "object", nil
+}
+
+func expectObjectOrNull(r *HarnessResult) (string, error) {
+	if r.Type != "object" && r.Type != "null" && r.Type != "undefined" {
+		return "", fmt.Errorf("expected an object or null, got %s", r.Type)
+	}
+	return r.Type, nil
+}
+
+func expectArray(r *HarnessResult) (string, error) {
+	if r.Type != "array" {
+		return "", fmt.Errorf("expected an array, got %s", r.Type)
+	}
+	return fmt.Sprintf("%d entries", len(r.Decoded().([]interface{}))), nil
+}
+
+func expectStringList(r *HarnessResult) (string, error) {
+	if r.Type != "array" {
+		return "", fmt.Errorf("expected an array of strings, got %s", r.Type)
+	}
+	list := r.Decoded().([]interface{})
+	if len(list) == 0 {
+		return "", fmt.Errorf("returned an empty list")
+	}
+	names := make([]string, len(list))
+	for i, entry := range list {
+		name, ok := entry.(string)
+		if !ok || name == "" {
+			return "", fmt.Errorf("entry %d: expected a non-empty string, got %s", i, jsonType(entry))
+		}
+		names[i] = name
+	}
+	return strings.Join(names, ", "), nil
+}
+
+// expectSearchItems checks the items global search shows: an id, a title
+// and optional description, icon and url strings.
+func expectSearchItems(r *HarnessResult) (string, error) {
+	if r.Type != "array" {
+		return "", fmt.Errorf("expected an array of results, got %s", r.Type)
+	}
+	items := r.Decoded().([]interface{})
+	for i, entry := range items {
+		item, ok := entry.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("item %d: expected an object, got %s", i, jsonType(entry))
+		}
+		switch id := item["id"].(type) {
+		case string:
+			if id == "" {
+				return "", fmt.Errorf("item %d: id is empty", i)
+			}
+		case float64:
+		default:
+			return "", fmt.Errorf("item %d: id must be a string or a number, got %s", i, jsonType(item["id"]))
+		}
+		if title, ok := item["title"].(string); !ok || strings.TrimSpace(title) == "" {
+			return "", fmt.Errorf("item %d: title must be a non-empty string", i)
+		}
+		for _, field := range []string{"description", "icon", "url"} {
+			if value, present := item[field]; present && value != nil {
+				if _, ok := value.(string); !ok {
+					return "", fmt.Errorf("item %d: %s must be a string, got %s", i, field, jsonType(value))
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("%d items", len(items)), nil
+}
+
+// expectUQLCapabilities checks the fields, operators and limits a module
+// declares for UQL queries.
+func expectUQLCapabilities(r *HarnessResult) (string, error) {
+	if r.Type != "object" {
+		return "", fmt.Errorf("expected an object, got %s", r.Type)
+	}
+	caps, err := uql.ParseCapabilities(r.Value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d fields, %d operators", len(caps.Fields), len(caps.Operators)), nil
+}
+
+// expectDateLike accepts a Date, a timestamp, an ISO date string or null
+// for a module that never refreshed.
+func expectDateLike(r *HarnessResult) (string, error) {
+	switch r.Type {
+	case "date", "null", "undefined", "number":
+		return r.Type, nil
+	case "string":
+		value, _ := r.Decoded().(string)
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "", fmt.Errorf("expected an ISO date, got %q", value)
+		}
+		return "string", nil
+	}
+	return "", fmt.Errorf("expected a Date or null, got %s", r.Type)
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
@@ -0,0 +1,569 @@
+package build
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devlyspace/dashspace-cli/internal/semver"
+)
+
+// contractsFS holds the interface contracts of released dashspace-lib
+// versions, one <version>.json per release. They are used when dashspace-lib
+// is not installed, and to report drift when it is.
+//
+//go:embed contracts
+var contractsFS embed.FS
+
+// InterfaceContract lists the methods a module interface requires.
+type InterfaceContract struct {
+	Methods  []string `json:"methods"`
+	Optional []string `json:"optional,omitempty"`
+}
+
+// InterfaceContracts are the module interfaces of one dashspace-lib version.
+type InterfaceContracts struct {
+	LibVersion string                        `json:"dashspaceLibVersion"`
+	Interfaces map[string]*InterfaceContract `json:"interfaces"`
+
+	// Source describes where the contracts were read from
+	Source string `json:"-"`
+	// Installed is set when they come from node_modules/dashspace-lib
+	Installed bool `json:"-"`
+}
+
+// Lookup returns the contract of an interface named with or without its I
+// prefix (ModuleInterfaces.Searchable or ISearchable).
+func (c *InterfaceContracts) Lookup(name string) (string, *InterfaceContract, bool) {
+	for _, candidate := range []string{name, "I" + name} {
+		if contract, ok := c.Interfaces[candidate]; ok {
+			return candidate, contract, true
+		}
+	}
+	return "", nil, false
+}
+
+var (
+	contractsMu    sync.Mutex
+	contractsCache = map[string]*InterfaceContracts{}
+)
+
+// Canonical returns the interface names dashspace-lib exports for names
+// declared in Module.ts, in order and without duplicates.
+func (c *InterfaceContracts) Canonical(declared []string) []string {
+	names := []string{}
+	for _, name := range declared {
+		if canonical, _, ok := c.Lookup(name); ok {
+			name = canonical
+		} else if !strings.HasPrefix(name, "I") {
+			name = "I" + name
+		}
+		names = appendUnique(names, name)
+	}
+	return names
+}
+
+// LoadInterfaceContracts returns the interface contracts of the dashspace-lib
+// installed in projectPath, read from its type declarations. Without an
+// installed lib, the embedded table matching package.json is used. Drift
+// between the installed lib and the embedded table is reported once.
+func LoadInterfaceContracts(projectPath string) *InterfaceContracts {
+	libDir := filepath.Join(projectPath, "node_modules", "dashspace-lib")
+	libVersion := installedLibVersion(libDir)
+
+	contractsMu.Lock()
+	defer contractsMu.Unlock()
+
+	cacheKey := projectPath + "@" + libVersion
+	if contracts, ok := contractsCache[cacheKey]; ok {
+		return contracts
+	}
+
+	var contracts *InterfaceContracts
+	if libVersion != "" {
+		expected := embeddedContracts(libVersion, true)
+
+		installed, err := parseLibContracts(libDir)
+		switch {
+		case err != nil:
+			fmt.Printf("⚠️  Warning: could not read dashspace-lib %s type declarations: %v\n", libVersion, err)
+		case len(installed.Interfaces) == 0:
+			fmt.Printf("⚠️  Warning: no module interfaces found in dashspace-lib %s type declarations\n", libVersion)
+		default:
+			installed.LibVersion = libVersion
+			installed.Source = fmt.Sprintf("dashspace-lib %s type declarations", libVersion)
+			installed.Installed = true
+			reportContractDrift(installed, expected)
+			contracts = installed
+		}
+
+		if contracts == nil {
+			contracts = expected
+		}
+	} else {
+		contracts = embeddedContracts(declaredLibRange(projectPath), false)
+	}
+
+	contractsCache[cacheKey] = contracts
+	return contracts
+}
+
+func installedLibVersion(libDir string) string {
+	data, err := os.ReadFile(filepath.Join(libDir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || pkg.Version == "" {
+		return "0.0.0"
+	}
+	return pkg.Version
+}
+
+// declaredLibRange returns the dashspace-lib range from package.json, if any.
+func declaredLibRange(projectPath string) string {
+	data, err := os.ReadFile(filepath.Join(projectPath, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	if r, ok := pkg.Dependencies["dashspace-lib"]; ok {
+		return r
+	}
+	return pkg.DevDependencies["dashspace-lib"]
+}
+
+// embeddedContracts picks the newest embedded table matching version: at or
+// below it for an installed version, within it for a package.json range. The
+// newest table is used when none matches.
+func embeddedContracts(version string, installed bool) *InterfaceContracts {
+	tables := map[string]*InterfaceContracts{}
+	versions := []semver.Version{}
+
+	entries, _ := fs.ReadDir(contractsFS, "contracts")
+	for _, entry := range entries {
+		data, err := contractsFS.ReadFile(path.Join("contracts", entry.Name()))
+		if err != nil {
+			continue
+		}
+		var table InterfaceContracts
+		if json.Unmarshal(data, &table) != nil {
+			continue
+		}
+		v, err := semver.Parse(table.LibVersion)
+		if err != nil {
+			continue
+		}
+		tables[v.String()] = &table
+		versions = append(versions, v)
+	}
+
+	spec := version
+	if installed {
+		spec = "<=" + version
+	}
+	versionRange, err := semver.ParseRange(spec)
+	if err != nil {
+		versionRange, _ = semver.ParseRange("*")
+	}
+
+	best, ok := versionRange.Best(versions)
+	if !ok {
+		latest, _ := semver.ParseRange("*")
+		best, _ = latest.Best(versions)
+	}
+
+	table := tables[best.String()]
+	if table == nil {
+		return &InterfaceContracts{Interfaces: map[string]*InterfaceContract{}, Source: "no interface contracts"}
+	}
+	table.Source = fmt.Sprintf("contracts embedded for dashspace-lib %s", table.LibVersion)
+	return table
+}
+
+// reportContractDrift prints where the installed dashspace-lib disagrees with
+// the contracts this CLI was released with.
+func reportContractDrift(installed, expected *InterfaceContracts) {
+	drift := []string{}
+
+	names := []string{}
+	for name := range installed.Interfaces {
+		names = append(names, name)
+	}
+	for name := range expected.Interfaces {
+		if _, ok := installed.Interfaces[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		got, inLib := installed.Interfaces[name]
+		want, inCLI := expected.Interfaces[name]
+		switch {
+		case !inCLI:
+			drift = append(drift, fmt.Sprintf("%s: new interface", name))
+		case !inLib:
+			drift = append(drift, fmt.Sprintf("%s: no longer in dashspace-lib", name))
+		default:
+			added, removed := diffMethods(got.Methods, want.Methods)
+			changes := []string{}
+			if len(added) > 0 {
+				changes = append(changes, "now requires "+strings.Join(added, ", "))
+			}
+			if len(removed) > 0 {
+				changes = append(changes, "no longer requires "+strings.Join(removed, ", "))
+			}
+			if len(changes) > 0 {
+				drift = append(drift, fmt.Sprintf("%s: %s", name, strings.Join(changes, "; ")))
+			}
+		}
+	}
+
+	if len(drift) == 0 {
+		return
+	}
+
+	fmt.Printf("⚠️  dashspace-lib %s differs from the interface contracts this CLI knows (%s):\n", installed.LibVersion, expected.LibVersion)
+	for _, line := range drift {
+		fmt.Printf("   - %s\n", line)
+	}
+	fmt.Println("   The installed dashspace-lib is used; update the CLI or dashspace-lib if this is unexpected")
+}
+
+func diffMethods(got, want []string) (added, removed []string) {
+	wanted := map[string]bool{}
+	for _, method := range want {
+		wanted[method] = true
+	}
+	for _, method := range got {
+		if !wanted[method] {
+			added = append(added, method)
+		}
+		delete(wanted, method)
+	}
+	for _, method := range want {
+		if wanted[method] {
+			removed = append(removed, method)
+		}
+	}
+	return added, removed
+}
+
+// tsInterface is an interface or object type alias read from a .d.ts file.
+type tsInterface struct {
+	extends  []string
+	required []string
+	optional []string
+}
+
+var (
+	interfaceDeclRegex  = regexp.MustCompile(`\binterface\s+([A-Za-z_$][\w$]*)\s*(?:<[^{]*?>)?\s*(?:extends\s+([^{]+?))?\s*\{`)
+	typeAliasDeclRegex  = regexp.MustCompile(`\btype\s+([A-Za-z_$][\w$]*)\s*(?:<[^{]*?>)?\s*=\s*\{`)
+	moduleInterfacesRe  = regexp.MustCompile(`\benum\s+ModuleInterfaces\s*\{([^}]*)\}`)
+	enumMemberRegex     = regexp.MustCompile(`([A-Za-z_$][\w$]*)\s*(?:=\s*['"]([^'"]+)['"])?`)
+	memberRegex         = regexp.MustCompile(`^(?:readonly\s+)?([A-Za-z_$][\w$]*|'[^']*'|"[^"]*")\s*(\?)?\s*([(<:])`)
+	maxDeclarationFiles = 500
+)
+
+// parseLibContracts reads the module interfaces from the .d.ts files of an
+// installed dashspace-lib. The ModuleInterfaces enum tells which interfaces
+// are module interfaces; without it, the interfaces known to the embedded
+// contracts are looked up.
+func parseLibContracts(libDir string) (*InterfaceContracts, error) {
+	declared := map[string]*tsInterface{}
+	var enumInterfaces []string
+	files := 0
+
+	err := filepath.WalkDir(libDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "node_modules" && p != libDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".d.ts") {
+			return nil
+		}
+		if files++; files > maxDeclarationFiles {
+			return fmt.Errorf("more than %d declaration files", maxDeclarationFiles)
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		source := stripTSComments(string(data))
+
+		for name, iface := range parseTSInterfaces(source) {
+			if existing, ok := declared[name]; ok {
+				// Declaration merging
+				existing.extends = append(existing.extends, iface.extends...)
+				existing.required = append(existing.required, iface.required...)
+				existing.optional = append(existing.optional, iface.optional...)
+				continue
+			}
+			declared[name] = iface
+		}
+		if names := parseModuleInterfacesEnum(source); len(names) > 0 {
+			enumInterfaces = names
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if enumInterfaces == nil {
+		for name := range embeddedContracts("*", false).Interfaces {
+			enumInterfaces = append(enumInterfaces, name)
+		}
+	}
+
+	contracts := &InterfaceContracts{Interfaces: map[string]*InterfaceContract{}}
+	for _, name := range enumInterfaces {
+		if _, ok := declared[name]; !ok {
+			continue
+		}
+		required, optional := resolveTSInterface(declared, name, map[string]bool{})
+		contracts.Interfaces[name] = &InterfaceContract{Methods: required, Optional: optional}
+	}
+
+	return contracts, nil
+}
+
+// resolveTSInterface collects the methods of an interface and of the
+// interfaces it extends.
+func resolveTSInterface(declared map[string]*tsInterface, name string, seen map[string]bool) (required, optional []string) {
+	iface, ok := declared[name]
+	if !ok || seen[name] {
+		return nil, nil
+	}
+	seen[name] = true
+
+	for _, parent := range iface.extends {
+		parentRequired, parentOptional := resolveTSInterface(declared, parent, seen)
+		required = appendUnique(required, parentRequired...)
+		optional = appendUnique(optional, parentOptional...)
+	}
+	required = appendUnique(required, iface.required...)
+	optional = appendUnique(optional, iface.optional...)
+
+	// A method required by the interface itself is not optional
+	filtered := optional[:0]
+	for _, method := range optional {
+		if !containsString(required, method) {
+			filtered = append(filtered, method)
+		}
+	}
+	return required, filtered
+}
+
+// parseModuleInterfacesEnum returns the interface names of the
+// ModuleInterfaces enum: the string value, or the member name with an I prefix.
+func parseModuleInterfacesEnum(source string) []string {
+	match := moduleInterfacesRe.FindStringSubmatch(source)
+	if match == nil {
+		return nil
+	}
+
+	names := []string{}
+	for _, member := range strings.Split(match[1], ",") {
+		m := enumMemberRegex.FindStringSubmatch(strings.TrimSpace(member))
+		if m == nil {
+			continue
+		}
+		name := m[2]
+		if name == "" {
+			name = m[1]
+		}
+		if !strings.HasPrefix(name, "I") {
+			name = "I" + name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func parseTSInterfaces(source string) map[string]*tsInterface {
+	interfaces := map[string]*tsInterface{}
+
+	add := func(name string, extends string, bodyStart int) {
+		end := matchingBrace(source, bodyStart-1)
+		if end < 0 {
+			return
+		}
+		iface := &tsInterface{}
+		for _, parent := range splitTopLevel(extends, ",") {
+			parent = strings.TrimSpace(parent)
+			if i := strings.IndexByte(parent, '<'); i >= 0 {
+				parent = parent[:i]
+			}
+			if i := strings.LastIndexByte(parent, '.'); i >= 0 {
+				parent = parent[i+1:]
+			}
+			if parent != "" {
+				iface.extends = append(iface.extends, strings.TrimSpace(parent))
+			}
+		}
+
+		for _, member := range splitTopLevel(source[bodyStart:end], ";,\n") {
+			m := memberRegex.FindStringSubmatch(strings.TrimSpace(member))
+			if m == nil {
+				continue
+			}
+			method := strings.Trim(m[1], `'"`)
+			if m[3] == ":" && !isFunctionType(strings.TrimSpace(member[strings.Index(member, ":")+1:])) {
+				continue // a plain property
+			}
+			if m[2] == "?" {
+				iface.optional = appendUnique(iface.optional, method)
+			} else {
+				iface.required = appendUnique(iface.required, method)
+			}
+		}
+		interfaces[name] = iface
+	}
+
+	for _, loc := range interfaceDeclRegex.FindAllStringSubmatchIndex(source, -1) {
+		extends := ""
+		if loc[4] >= 0 {
+			extends = source[loc[4]:loc[5]]
+		}
+		add(source[loc[2]:loc[3]], extends, loc[1])
+	}
+	for _, loc := range typeAliasDeclRegex.FindAllStringSubmatchIndex(source, -1) {
+		add(source[loc[2]:loc[3]], "", loc[1])
+	}
+
+	return interfaces
+}
+
+func isFunctionType(typeText string) bool {
+	return (strings.HasPrefix(typeText, "(") || strings.HasPrefix(typeText, "<")) && strings.Contains(typeText, "=>")
+}
+
+// matchingBrace returns the index of the brace closing the one at open.
+func matchingBrace(source string, open int) int {
+	depth := 0
+	for i := open; i < len(source); i++ {
+		switch source[i] {
+		case '\'', '"', '`':
+			i = skipString(source, i)
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits on any of seps outside brackets and strings.
+func splitTopLevel(source string, seps string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipString(source, i)
+		case c == '=' && i+1 < len(source) && source[i+1] == '>':
+			i++ // the arrow of a function type is not a closing bracket
+		case strings.IndexByte("({[<", c) >= 0:
+			depth++
+		case strings.IndexByte(")}]>", c) >= 0:
+			depth--
+		case depth == 0 && strings.IndexByte(seps, c) >= 0:
+			parts = append(parts, source[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, source[start:])
+}
+
+// skipString returns the index of the quote closing the string opened at i.
+func skipString(source string, i int) int {
+	quote := source[i]
+	for i++; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return i
+}
+
+// stripTSComments removes // and /* */ comments, leaving strings untouched.
+func stripTSComments(source string) string {
+	var b strings.Builder
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := skipString(source, i)
+			if end >= len(source) {
+				end = len(source) - 1
+			}
+			b.WriteString(source[i : end+1])
+			i = end
+		case c == '/' && i+1 < len(source) && source[i+1] == '/':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+			b.WriteByte('\n')
+		case c == '/' && i+1 < len(source) && source[i+1] == '*':
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			i += end + 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if !containsString(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
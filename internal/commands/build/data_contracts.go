@@ -0,0 +1,163 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contractField is a field every provider of a data type must expose.
+type contractField struct {
+	name     string
+	types    []string
+	nullable bool
+}
+
+func canonicalField(name string, types ...string) contractField {
+	return contractField{name: name, types: types}
+}
+
+func canonicalNullableField(name string, types ...string) contractField {
+	return contractField{name: name, types: types, nullable: true}
+}
+
+// dataContracts are the canonical fields of each data type, the ones
+// consumers of the data registry may rely on whatever module provides the
+// data. Types without canonical fields accept any schema.
+var dataContracts = map[string][]contractField{
+	"issue-tracker": {
+		canonicalField("id", "string", "number"),
+		canonicalField("title", "string"),
+		canonicalField("status", "string"),
+		canonicalField("createdAt", "date"),
+	},
+	"code-review": {
+		canonicalField("id", "string", "number"),
+		canonicalField("title", "string"),
+		canonicalField("status", "string"),
+		canonicalField("author", "string", "object"),
+		canonicalField("createdAt", "date"),
+	},
+	"version-control": {
+		canonicalField("id", "string"),
+		canonicalField("message", "string"),
+		canonicalField("author", "string", "object"),
+		canonicalField("createdAt", "date"),
+	},
+	"ci-cd": {
+		canonicalField("id", "string", "number"),
+		canonicalField("status", "string"),
+		canonicalField("branch", "string"),
+		canonicalField("createdAt", "date"),
+	},
+	"payment-system": {
+		canonicalField("id", "string"),
+		canonicalField("amount", "number"),
+		canonicalField("currency", "string"),
+		canonicalField("status", "string"),
+		canonicalField("createdAt", "date"),
+	},
+	"error-tracking": {
+		canonicalField("id", "string", "number"),
+		canonicalField("title", "string"),
+		canonicalField("count", "number"),
+		canonicalField("lastSeen", "date"),
+	},
+	"monitoring": {
+		canonicalField("name", "string"),
+		canonicalField("value", "number"),
+		canonicalField("timestamp", "date"),
+	},
+	"deployment-system": {
+		canonicalField("id", "string", "number"),
+		canonicalField("environment", "string"),
+		canonicalField("status", "string"),
+		canonicalField("createdAt", "date"),
+	},
+	"task-management": {
+		canonicalField("id", "string", "number"),
+		canonicalField("title", "string"),
+		canonicalField("status", "string"),
+		canonicalNullableField("dueDate", "date"),
+	},
+	"project-management": {
+		canonicalField("id", "string", "number"),
+		canonicalField("name", "string"),
+		canonicalField("status", "string"),
+	},
+	"communication": {
+		canonicalField("id", "string"),
+		canonicalField("author", "string", "object"),
+		canonicalField("text", "string"),
+		canonicalField("createdAt", "date"),
+	},
+	"calendar": {
+		canonicalField("id", "string"),
+		canonicalField("title", "string"),
+		canonicalField("start", "date"),
+		canonicalField("end", "date"),
+	},
+	"documentation": {
+		canonicalField("id", "string"),
+		canonicalField("title", "string"),
+		canonicalField("url", "string"),
+		canonicalField("updatedAt", "date"),
+	},
+	"analytics": {
+		canonicalField("metric", "string"),
+		canonicalField("value", "number"),
+		canonicalField("timestamp", "date"),
+	},
+	"database":    nil,
+	"api-service": nil,
+	"cloud-storage": {
+		canonicalField("id", "string"),
+		canonicalField("name", "string"),
+		canonicalField("size", "number"),
+		canonicalField("updatedAt", "date"),
+	},
+	"authentication": nil,
+	"notification": {
+		canonicalField("id", "string", "number"),
+		canonicalField("title", "string"),
+		canonicalField("read", "boolean"),
+		canonicalField("createdAt", "date"),
+	},
+	"workflow": {
+		canonicalField("id", "string", "number"),
+		canonicalField("name", "string"),
+		canonicalField("status", "string"),
+	},
+	"generic": nil,
+}
+
+// checkDataContract returns the differences between fields and the
+// canonical fields of dataType.
+func checkDataContract(dataType string, fields []DataFieldDefinition) []string {
+	declared := map[string]DataFieldDefinition{}
+	for _, f := range fields {
+		declared[f.Name] = f
+	}
+
+	problems := []string{}
+	for _, want := range dataContracts[dataType] {
+		f, ok := declared[want.name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("missing field '%s' (%s)", want.name, strings.Join(want.types, " or ")))
+		case !containsString(want.types, f.Type):
+			problems = append(problems, fmt.Sprintf("field '%s' is %s, expected %s", want.name, f.Type, strings.Join(want.types, " or ")))
+		case f.Nullable && !want.nullable:
+			problems = append(problems, fmt.Sprintf("field '%s' must not be nullable or optional", want.name))
+		}
+	}
+	return problems
+}
+
+// contractFieldNames lists the canonical fields of dataType.
+func contractFieldNames(dataType string) []string {
+	names := []string{}
+	for _, want := range dataContracts[dataType] {
+		names = append(names, want.name)
+	}
+	return names
+}
@@ -0,0 +1,386 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DataSchemaChange is a difference between the data schemas of two versions
+// of a module. Breaking changes can break consumers of the previous data.
+type DataSchemaChange struct {
+	Provider string `json:"provider,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+	Breaking bool   `json:"breaking"`
+}
+
+func (c DataSchemaChange) String() string {
+	parts := []string{}
+	if c.Provider != "" {
+		parts = append(parts, c.Provider)
+	}
+	if c.Path != "" {
+		parts = append(parts, c.Path)
+	}
+	parts = append(parts, c.Message)
+	return strings.Join(parts, ": ")
+}
+
+// BreakingChanges returns the breaking changes of a diff.
+func BreakingChanges(changes []DataSchemaChange) []DataSchemaChange {
+	breaking := []DataSchemaChange{}
+	for _, change := range changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// ReadManifestDataSchema reads the data schema of a dashspace.json, or of
+// the dashspace.json in a directory. It returns nil when the module exposes
+// no data.
+func ReadManifestDataSchema(path string) (*ModuleDataSchema, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "dashspace.json")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ParseManifestDataSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// ParseManifestDataSchema reads the data schema of dashspace.json content.
+func ParseManifestDataSchema(data []byte) (*ModuleDataSchema, error) {
+	var manifest struct {
+		DataSchema *ModuleDataSchema `json:"data_schema"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid dashspace.json: %w", err)
+	}
+	if manifest.DataSchema == nil || !manifest.DataSchema.ExposeData {
+		return nil, nil
+	}
+	return manifest.DataSchema, nil
+}
+
+// DiffDataSchemas compares the data a module exposes in two versions, from
+// the point of view of consumers of the old version: removing a field,
+// making it nullable or changing its type is breaking, adding one is not.
+func DiffDataSchemas(previous, current *ModuleDataSchema) []DataSchemaChange {
+	changes := []DataSchemaChange{}
+	oldProviders, newProviders := dataProviders(previous), dataProviders(current)
+
+	if len(oldProviders) > 0 && len(newProviders) == 0 {
+		return append(changes, DataSchemaChange{Message: "module no longer exposes data", Breaking: true})
+	}
+
+	matched := map[int]bool{}
+	for _, before := range oldProviders {
+		index := -1
+		for i, after := range newProviders {
+			// Manifests without providers describe a single unnamed provider
+			if after.ID == before.ID || (before.ID == "" && i == 0) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			changes = append(changes, DataSchemaChange{Provider: before.ID, Message: "data provider removed", Breaking: true})
+			continue
+		}
+		matched[index] = true
+		changes = append(changes, diffDataProvider(before, newProviders[index])...)
+	}
+
+	for i, after := range newProviders {
+		if !matched[i] {
+			changes = append(changes, DataSchemaChange{Provider: after.ID, Message: "data provider added"})
+		}
+	}
+	return changes
+}
+
+func dataProviders(schema *ModuleDataSchema) []DataProviderSchema {
+	if schema == nil || !schema.ExposeData {
+		return nil
+	}
+	if len(schema.Providers) > 0 {
+		return schema.Providers
+	}
+	return []DataProviderSchema{{
+		DataType:       schema.DataType,
+		Schema:         schema.Schema,
+		ComputedFields: schema.ComputedFields,
+		JSONSchema:     schema.JSONSchema,
+	}}
+}
+
+func diffDataProvider(previous, current DataProviderSchema) []DataSchemaChange {
+	provider := current.ID
+	changes := []DataSchemaChange{}
+	add := func(path, message string, breaking bool) {
+		changes = append(changes, DataSchemaChange{Provider: provider, Path: path, Message: message, Breaking: breaking})
+	}
+
+	if previous.DataType != current.DataType {
+		add("", fmt.Sprintf("data type changed from '%s' to '%s'", previous.DataType, current.DataType), previous.DataType != "")
+	}
+
+	for _, name := range previous.ComputedFields {
+		if !containsString(current.ComputedFields, name) {
+			add(name, "computed metric removed", true)
+		}
+	}
+	for _, name := range current.ComputedFields {
+		if !containsString(previous.ComputedFields, name) {
+			add(name, "computed metric added", false)
+		}
+	}
+
+	oldCapabilities, newCapabilities := capabilities(previous.Schema), capabilities(current.Schema)
+	for name, fields := range oldCapabilities {
+		kept, ok := newCapabilities[name]
+		if !ok {
+			add("", fmt.Sprintf("capability '%s' removed", name), true)
+			continue
+		}
+		for _, field := range fields {
+			if !containsString(kept, field) {
+				add(field, fmt.Sprintf("no longer %s", name), true)
+			}
+		}
+	}
+	for name := range newCapabilities {
+		if _, ok := oldCapabilities[name]; !ok {
+			add("", fmt.Sprintf("capability '%s' added", name), false)
+		}
+	}
+
+	if previous.JSONSchema != nil && current.JSONSchema != nil {
+		diff := &schemaDiff{oldRoot: previous.JSONSchema, newRoot: current.JSONSchema, seen: map[string]bool{}, add: add}
+		oldItems, newItems := previous.JSONSchema, current.JSONSchema
+		if oldItems.Type.Has("array") && newItems.Type.Has("array") && oldItems.Items != nil && newItems.Items != nil {
+			// Paths of list items start at their fields
+			oldItems, newItems = oldItems.Items, newItems.Items
+		}
+		diff.compare("", oldItems, newItems)
+	} else if previous.Schema != nil && current.Schema != nil {
+		diffFields(previous.Schema.Fields, current.Schema.Fields, add)
+	}
+
+	return changes
+}
+
+func capabilities(schema *DataSchemaDefinition) map[string][]string {
+	byName := map[string][]string{}
+	if schema != nil {
+		for _, capability := range schema.Capabilities {
+			byName[capability.Name] = capability.Fields
+		}
+	}
+	return byName
+}
+
+func diffFields(previous, current []DataFieldDefinition, add func(path, message string, breaking bool)) {
+	byName := map[string]DataFieldDefinition{}
+	for _, field := range current {
+		byName[field.Name] = field
+	}
+	existed := map[string]bool{}
+
+	for _, before := range previous {
+		existed[before.Name] = true
+		after, ok := byName[before.Name]
+		switch {
+		case !ok:
+			add(before.Name, "field removed", true)
+		case after.Type != before.Type:
+			add(before.Name, fmt.Sprintf("type changed from %s to %s", before.Type, after.Type), true)
+		case after.Nullable && !before.Nullable:
+			add(before.Name, "field became nullable", true)
+		case before.Nullable && !after.Nullable:
+			add(before.Name, "field is no longer nullable", false)
+		}
+	}
+	for _, after := range current {
+		if !existed[after.Name] {
+			add(after.Name, "field added", false)
+		}
+	}
+}
+
+// schemaDiff compares two JSON Schemas of provider data.
+type schemaDiff struct {
+	oldRoot, newRoot *JSONSchema
+	// seen holds the pairs of $defs compared, to stop on recursive types
+	seen map[string]bool
+	add  func(path, message string, breaking bool)
+}
+
+func (d *schemaDiff) resolve(root, schema *JSONSchema) *JSONSchema {
+	if schema.Ref == "" {
+		return schema
+	}
+	if def := root.Defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]; def != nil {
+		return def
+	}
+	return &JSONSchema{}
+}
+
+func (d *schemaDiff) compare(path string, previous, current *JSONSchema) {
+	if previous.Ref != "" || current.Ref != "" {
+		key := previous.Ref + "|" + current.Ref
+		if d.seen[key] {
+			return
+		}
+		d.seen[key] = true
+		previous, current = d.resolve(d.oldRoot, previous), d.resolve(d.newRoot, current)
+	}
+
+	if !d.compareTypes(path, previous, current) {
+		return
+	}
+
+	if previous.Format != "" && previous.Format != current.Format {
+		d.add(path, fmt.Sprintf("format changed from '%s' to '%s'", previous.Format, current.Format), true)
+	}
+	d.compareEnums(path, previous.Enum, current.Enum)
+
+	required := func(schema *JSONSchema, name string) bool { return containsString(schema.Required, name) }
+	for _, name := range previous.PropertyNames() {
+		property, ok := current.Properties[name]
+		child := joinFieldPath(path, name)
+		if !ok {
+			if current.AdditionalProperties == nil {
+				d.add(child, "field removed", true)
+			}
+			continue
+		}
+		if required(previous, name) && !required(current, name) {
+			d.add(child, "field became optional", true)
+		}
+		d.compare(child, previous.Properties[name], property)
+	}
+	for _, name := range current.PropertyNames() {
+		if _, ok := previous.Properties[name]; !ok {
+			d.add(joinFieldPath(path, name), "field added", false)
+		}
+	}
+
+	if previous.Items != nil && current.Items != nil {
+		d.compare(path+"[]", previous.Items, current.Items)
+	}
+	if previous.AdditionalProperties != nil && current.AdditionalProperties != nil {
+		d.compare(joinFieldPath(path, "*"), previous.AdditionalProperties, current.AdditionalProperties)
+	}
+
+	for _, alternatives := range [][2]interface{}{{previous.AnyOf, current.AnyOf}, {previous.AllOf, current.AllOf}, {previous.PrefixItems, current.PrefixItems}} {
+		if string(mustMarshal(alternatives[0])) != string(mustMarshal(alternatives[1])) {
+			d.add(path, "shape changed", true)
+			break
+		}
+	}
+}
+
+// compareTypes reports type changes. It returns false when the types differ
+// too much to compare the rest of the schemas.
+func (d *schemaDiff) compareTypes(path string, previous, current *JSONSchema) bool {
+	if len(previous.Type) == 0 {
+		return true // consumers could not rely on a type
+	}
+	if len(current.Type) == 0 {
+		if len(current.AnyOf) == 0 && len(current.AllOf) == 0 {
+			d.add(path, fmt.Sprintf("type changed from %s to any", typeNames(previous.Type)), true)
+		}
+		return false
+	}
+
+	added, removed := []string{}, []string{}
+	for _, t := range current.Type {
+		if !previous.Type.Has(t) && !(t == "integer" && previous.Type.Has("number")) {
+			added = append(added, t)
+		}
+	}
+	for _, t := range previous.Type {
+		if !current.Type.Has(t) {
+			removed = append(removed, t)
+		}
+	}
+
+	switch {
+	case len(added) == 1 && added[0] == "null":
+		d.add(path, "field became nullable", true)
+	case len(added) > 0:
+		d.add(path, fmt.Sprintf("type changed from %s to %s", typeNames(previous.Type), typeNames(current.Type)), true)
+		return false
+	case len(removed) == 1 && removed[0] == "null":
+		d.add(path, "field is no longer nullable", false)
+	case len(removed) > 0:
+		d.add(path, fmt.Sprintf("type narrowed from %s to %s", typeNames(previous.Type), typeNames(current.Type)), false)
+	}
+	return true
+}
+
+func (d *schemaDiff) compareEnums(path string, previous, current []interface{}) {
+	if len(previous) == 0 {
+		if len(current) > 0 {
+			d.add(path, "values restricted to "+formatValues(current), false)
+		}
+		return
+	}
+	if len(current) == 0 {
+		d.add(path, "values no longer restricted to "+formatValues(previous), true)
+		return
+	}
+
+	added, removed := []interface{}{}, []interface{}{}
+	for _, value := range current {
+		if value != nil && !containsValue(previous, value) {
+			added = append(added, value)
+		}
+	}
+	for _, value := range previous {
+		if value != nil && !containsValue(current, value) {
+			removed = append(removed, value)
+		}
+	}
+	if len(added) > 0 {
+		d.add(path, "new values "+formatValues(added), true)
+	}
+	if len(removed) > 0 {
+		d.add(path, "values removed "+formatValues(removed), false)
+	}
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func typeNames(types JSONSchemaType) string {
+	return strings.Join(types, "|")
+}
+
+func formatValues(values []interface{}) string {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = string(mustMarshal(value))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func mustMarshal(value interface{}) []byte {
+	data, _ := json.Marshal(value)
+	return data
+}
@@ -10,11 +10,25 @@ import (
 
 type DataSchemaExtractor struct{}
 
+// ModuleDataSchema is the data a module exposes. The top-level fields
+// describe the first provider, for hosts reading a single schema.
 type ModuleDataSchema struct {
 	ExposeData     bool                  `json:"exposeData"`
 	DataType       string                `json:"dataType,omitempty"`
 	Schema         *DataSchemaDefinition `json:"schema,omitempty"`
 	ComputedFields []string              `json:"computedFields,omitempty"`
+	JSONSchema     *JSONSchema           `json:"jsonSchema,omitempty"`
+	Providers      []DataProviderSchema  `json:"providers,omitempty"`
+}
+
+// DataProviderSchema is the data exposed by one useDataProvider call.
+type DataProviderSchema struct {
+	ID             string                `json:"id"`
+	DataType       string                `json:"dataType,omitempty"`
+	TypeName       string                `json:"typeName,omitempty"`
+	Schema         *DataSchemaDefinition `json:"schema,omitempty"`
+	ComputedFields []string              `json:"computedFields,omitempty"`
+	JSONSchema     *JSONSchema           `json:"jsonSchema,omitempty"`
 }
 
 type DataSchemaDefinition struct {
@@ -39,65 +53,122 @@ func NewDataSchemaExtractor() *DataSchemaExtractor {
 	return &DataSchemaExtractor{}
 }
 
+// ExtractDataSchema reads the useDataProvider calls of the component, the
+// hooks and the files they import. The schema of each provider is inferred
+// from the type argument of the call when there is one.
 func (d *DataSchemaExtractor) ExtractDataSchema() (*ModuleDataSchema, error) {
-	componentFile := findComponentFile()
-	if componentFile == "" {
-		hooksFile := findHooksFile()
-		if hooksFile == "" {
-			return &ModuleDataSchema{ExposeData: false}, nil
-		}
-		componentFile = hooksFile
+	entries := hooksFiles()
+	if componentFile := findComponentFile(); componentFile != "" {
+		entries = append([]string{componentFile}, entries...)
 	}
-
-	content, err := ioutil.ReadFile(componentFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+	if len(entries) == 0 {
+		return &ModuleDataSchema{ExposeData: false}, nil
 	}
 
-	fileContent := string(content)
-
-	useDataProviderPattern := regexp.MustCompile(`useDataProvider\s*<[^>]*>\s*\(`)
-	if !useDataProviderPattern.MatchString(fileContent) {
-		useDataProviderPattern = regexp.MustCompile(`useDataProvider\s*\(`)
-		if !useDataProviderPattern.MatchString(fileContent) {
-			return &ModuleDataSchema{ExposeData: false}, nil
+	sources := newTSSources()
+	calls := []dataProviderCall{}
+	for _, file := range moduleSourceFiles(entries) {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
 		}
+		sources.add(file, string(content))
+		calls = append(calls, findDataProviderCalls(file, string(content))...)
 	}
 
-	fmt.Println("📊 Found useDataProvider - extracting data schema...")
+	if len(calls) == 0 {
+		return &ModuleDataSchema{ExposeData: false}, nil
+	}
+
+	if len(calls) == 1 {
+		fmt.Println("📊 Found useDataProvider - extracting data schema...")
+	} else {
+		fmt.Printf("📊 Found %d useDataProvider calls - extracting data schemas...\n", len(calls))
+	}
 
 	schema := &ModuleDataSchema{
 		ExposeData: true,
 	}
 
-	callPattern := regexp.MustCompile(`(?s)useDataProvider\s*(?:<[^>]*>)?\s*\(\s*['"]([^'"]+)['"],\s*\{(.*?)\},\s*\{(.*?)\}`)
-	callMatch := callPattern.FindStringSubmatch(fileContent)
+	for _, call := range calls {
+		provider, ok, err := d.extractProvider(call, sources)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		schema.Providers = append(schema.Providers, provider)
+	}
 
-	if len(callMatch) < 4 {
-		fmt.Printf("⚠️  Warning: useDataProvider found but couldn't extract full schema\n")
-		return schema, nil
+	if len(schema.Providers) > 0 {
+		first := schema.Providers[0]
+		schema.DataType = first.DataType
+		schema.Schema = first.Schema
+		schema.ComputedFields = first.ComputedFields
+		schema.JSONSchema = first.JSONSchema
 	}
 
-	payloadContent := callMatch[2]
-	metadataContent := callMatch[3]
+	return schema, nil
+}
 
-	if dataType := d.extractDataType(metadataContent); dataType != "" {
-		schema.DataType = dataType
-		fmt.Printf("   Data type: %s\n", dataType)
+// extractProvider reads the id, payload and metadata arguments of a call and
+// infers its JSON Schema, resolving the type argument in the calling file.
+func (d *DataSchemaExtractor) extractProvider(call dataProviderCall, sources *tsSources) (DataProviderSchema, bool, error) {
+	provider := DataProviderSchema{TypeName: call.typeArg}
+	if len(call.args) == 0 {
+		fmt.Printf("⚠️  Warning: useDataProvider without a provider id in %s:%d\n", call.file, call.line)
+		return provider, false, nil
 	}
+	provider.ID = strings.Trim(call.args[0], "'\"`")
+	fmt.Printf("   Provider: %s\n", provider.ID)
 
-	if schemaObj := d.extractSchemaObject(payloadContent); schemaObj != nil {
-		schema.Schema = schemaObj
-		fmt.Printf("   Fields: %d\n", len(schemaObj.Fields))
-		fmt.Printf("   Capabilities: %d\n", len(schemaObj.Capabilities))
+	if len(call.args) > 1 {
+		payloadContent := objectBody(call.args[1])
+		provider.Schema = d.extractSchemaObject(payloadContent)
+		provider.ComputedFields = d.extractComputedFields(payloadContent)
 	}
+	if len(call.args) > 2 {
+		provider.DataType = d.extractDataType(objectBody(call.args[2]))
+	}
+
+	if call.typeArg != "" {
+		parser := &tsTypeParser{src: call.typeArg}
+		builder := newSchemaBuilder(sources, call.file)
+		provider.JSONSchema = builder.root(parser.parseType())
+		if len(builder.errors) > 0 {
+			return provider, false, fmt.Errorf("data provider '%s' (%s:%d): %w", provider.ID, call.file, call.line, builder.errors[0])
+		}
+		for _, name := range builder.unresolved {
+			fmt.Printf("⚠️  Warning: type '%s' used by data provider '%s' is not declared in the module sources, its data is left untyped\n", name, provider.ID)
+		}
 
-	if computedFields := d.extractComputedFields(payloadContent); len(computedFields) > 0 {
-		schema.ComputedFields = computedFields
-		fmt.Printf("   Computed metrics: %d\n", len(computedFields))
+		if provider.Schema == nil {
+			provider.Schema = &DataSchemaDefinition{}
+		}
+		if len(provider.Schema.Fields) == 0 {
+			provider.Schema.Fields = fieldsFromJSONSchema(provider.JSONSchema)
+		}
+		fmt.Printf("   Type: %s\n", call.typeArg)
+	} else if provider.Schema != nil {
+		provider.JSONSchema = jsonSchemaFromFields(provider.Schema.Fields)
 	}
 
-	return schema, nil
+	if provider.DataType != "" {
+		fmt.Printf("   Data type: %s\n", provider.DataType)
+	}
+	if provider.Schema != nil {
+		fmt.Printf("   Fields: %d\n", len(provider.Schema.Fields))
+		fmt.Printf("   Capabilities: %d\n", len(provider.Schema.Capabilities))
+	}
+	if len(provider.ComputedFields) > 0 {
+		fmt.Printf("   Computed metrics: %d\n", len(provider.ComputedFields))
+	}
+	if provider.Schema == nil {
+		fmt.Printf("⚠️  Warning: useDataProvider '%s' has neither a type argument nor a schema\n", provider.ID)
+	}
+
+	return provider, true, nil
 }
 
 func (d *DataSchemaExtractor) extractDataType(metadataContent string) string {
@@ -109,25 +180,19 @@ func (d *DataSchemaExtractor) extractDataType(metadataContent string) string {
 }
 
 func (d *DataSchemaExtractor) extractSchemaObject(payloadContent string) *DataSchemaDefinition {
-	schemaPattern := regexp.MustCompile(`(?s)schema:\s*\{(.*?)\}(?:\s*,|\s*\})`)
-	schemaMatch := schemaPattern.FindStringSubmatch(payloadContent)
-
-	if len(schemaMatch) < 2 {
+	schemaContent := propertyBody(payloadContent, "schema")
+	if schemaContent == "" {
 		return nil
 	}
 
-	schemaContent := schemaMatch[1]
-
 	schemaObj := &DataSchemaDefinition{}
 
-	fieldsPattern := regexp.MustCompile(`(?s)fields:\s*\[(.*?)\]`)
-	if fieldsMatch := fieldsPattern.FindStringSubmatch(schemaContent); len(fieldsMatch) > 1 {
-		schemaObj.Fields = d.extractFields(fieldsMatch[1])
+	if fieldsContent := propertyBody(schemaContent, "fields"); fieldsContent != "" {
+		schemaObj.Fields = d.extractFields(fieldsContent)
 	}
 
-	capabilitiesPattern := regexp.MustCompile(`(?s)capabilities:\s*\[(.*?)\]`)
-	if capMatch := capabilitiesPattern.FindStringSubmatch(schemaContent); len(capMatch) > 1 {
-		schemaObj.Capabilities = d.extractCapabilities(capMatch[1])
+	if capContent := propertyBody(schemaContent, "capabilities"); capContent != "" {
+		schemaObj.Capabilities = d.extractCapabilities(capContent)
 	}
 
 	return schemaObj
@@ -207,10 +272,7 @@ func (d *DataSchemaExtractor) extractCapabilities(capContent string) []DataCapab
 func (d *DataSchemaExtractor) extractComputedFields(payloadContent string) []string {
 	var computedFields []string
 
-	computedPattern := regexp.MustCompile(`(?s)computed:\s*\{(.*?)\}`)
-	if computedMatch := computedPattern.FindStringSubmatch(payloadContent); len(computedMatch) > 1 {
-		computedContent := computedMatch[1]
-
+	if computedContent := propertyBody(payloadContent, "computed"); computedContent != "" {
 		fieldPattern := regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*:`)
 		fieldMatches := fieldPattern.FindAllStringSubmatch(computedContent, -1)
 
@@ -231,44 +293,50 @@ func (d *DataSchemaExtractor) ValidateDataSchema(schema *ModuleDataSchema) error
 
 	fmt.Println("🔍 Validating data schema...")
 
-	if schema.DataType == "" {
-		fmt.Printf("⚠️  Warning: No data type specified (using 'generic')\n")
-	} else {
-		validTypes := map[string]bool{
-			"issue-tracker":      true,
-			"code-review":        true,
-			"version-control":    true,
-			"ci-cd":              true,
-			"payment-system":     true,
-			"error-tracking":     true,
-			"monitoring":         true,
-			"deployment-system":  true,
-			"task-management":    true,
-			"project-management": true,
-			"communication":      true,
-			"calendar":           true,
-			"documentation":      true,
-			"analytics":          true,
-			"database":           true,
-			"api-service":        true,
-			"cloud-storage":      true,
-			"authentication":     true,
-			"notification":       true,
-			"workflow":           true,
-			"generic":            true,
-		}
+	providers := schema.Providers
+	if len(providers) == 0 {
+		providers = []DataProviderSchema{{DataType: schema.DataType, Schema: schema.Schema}}
+	}
 
-		if !validTypes[schema.DataType] {
-			return fmt.Errorf("invalid data type: %s", schema.DataType)
+	seen := map[string]bool{}
+	for _, provider := range providers {
+		if provider.ID != "" {
+			if seen[provider.ID] {
+				return fmt.Errorf("data provider '%s' is declared more than once", provider.ID)
+			}
+			seen[provider.ID] = true
 		}
+		if err := d.validateProvider(provider); err != nil {
+			if provider.ID != "" && len(providers) > 1 {
+				return fmt.Errorf("data provider '%s': %w", provider.ID, err)
+			}
+			return err
+		}
+	}
+
+	fmt.Println("✅ Data schema validated")
+	return nil
+}
+
+func (d *DataSchemaExtractor) validateProvider(provider DataProviderSchema) error {
+	if provider.DataType == "" {
+		fmt.Printf("⚠️  Warning: No data type specified (using 'generic')\n")
+	} else if _, ok := dataContracts[provider.DataType]; !ok {
+		return fmt.Errorf("invalid data type: %s", provider.DataType)
+	}
+
+	if len(dataContracts[provider.DataType]) > 0 && (provider.Schema == nil || len(provider.Schema.Fields) == 0) {
+		fmt.Printf("⚠️  Warning: No fields to check against the %s contract (%s)\n",
+			provider.DataType, strings.Join(contractFieldNames(provider.DataType), ", "))
 	}
 
-	if schema.Schema != nil {
-		if len(schema.Schema.Fields) == 0 {
+	if provider.Schema != nil {
+		if len(provider.Schema.Fields) == 0 {
 			fmt.Printf("⚠️  Warning: Schema defined but no fields found\n")
 		}
 
-		for _, field := range schema.Schema.Fields {
+		fieldNames := map[string]bool{}
+		for _, field := range provider.Schema.Fields {
 			validFieldTypes := map[string]bool{
 				"string": true, "number": true, "date": true,
 				"boolean": true, "array": true, "object": true,
@@ -277,9 +345,17 @@ func (d *DataSchemaExtractor) ValidateDataSchema(schema *ModuleDataSchema) error
 			if !validFieldTypes[field.Type] {
 				return fmt.Errorf("invalid field type '%s' for field '%s'", field.Type, field.Name)
 			}
+			fieldNames[field.Name] = true
 		}
 
-		for _, cap := range schema.Schema.Capabilities {
+		if len(provider.Schema.Fields) > 0 {
+			if problems := checkDataContract(provider.DataType, provider.Schema.Fields); len(problems) > 0 {
+				return fmt.Errorf("%s data must expose %s: %s", provider.DataType,
+					strings.Join(contractFieldNames(provider.DataType), ", "), strings.Join(problems, "; "))
+			}
+		}
+
+		for _, cap := range provider.Schema.Capabilities {
 			validCapabilities := map[string]bool{
 				"filterable": true, "sortable": true, "groupable": true,
 				"aggregatable": true, "time-series": true,
@@ -288,10 +364,14 @@ func (d *DataSchemaExtractor) ValidateDataSchema(schema *ModuleDataSchema) error
 			if !validCapabilities[cap.Name] {
 				fmt.Printf("⚠️  Warning: Unknown capability '%s'\n", cap.Name)
 			}
+			for _, field := range cap.Fields {
+				if len(fieldNames) > 0 && !fieldNames[field] {
+					fmt.Printf("⚠️  Warning: Capability '%s' refers to unknown field '%s'\n", cap.Name, field)
+				}
+			}
 		}
 	}
 
-	fmt.Println("✅ Data schema validated")
 	return nil
 }
 
@@ -308,7 +388,8 @@ func (d *DataSchemaExtractor) SerializeToJSON(schema *ModuleDataSchema) (string,
 	return string(jsonData), nil
 }
 
-func findHooksFile() string {
+// hooksFiles returns the hooks files of the module that exist.
+func hooksFiles() []string {
 	candidates := []string{
 		"hooks/useModuleData.ts",
 		"hooks/useData.ts",
@@ -318,11 +399,12 @@ func findHooksFile() string {
 		"src/hooks/index.ts",
 	}
 
+	files := []string{}
 	for _, candidate := range candidates {
 		if fileExists(candidate) {
-			return candidate
+			files = append(files, candidate)
 		}
 	}
 
-	return ""
+	return files
 }
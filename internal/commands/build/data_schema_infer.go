@@ -0,0 +1,770 @@
+package build
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// JSONSchemaDialect is the JSON Schema version of inferred data schemas.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema used to describe provider data.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 JSONSchemaType         `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	PrefixItems          []*JSONSchema          `json:"prefixItems,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+
+	// order lists Properties in declaration order
+	order []string
+}
+
+// JSONSchemaType is "type", written as a name or as a list of names.
+type JSONSchemaType []string
+
+func (t JSONSchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *JSONSchemaType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = JSONSchemaType{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("type must be a name or a list of names")
+	}
+	*t = names
+	return nil
+}
+
+// Has reports whether the type lists name.
+func (t JSONSchemaType) Has(name string) bool {
+	return containsString(t, name)
+}
+
+// PropertyNames returns the property names in declaration order when known,
+// sorted otherwise.
+func (s *JSONSchema) PropertyNames() []string {
+	if len(s.order) == len(s.Properties) {
+		return s.order
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaBuilder converts TypeScript types into JSON Schema.
+type schemaBuilder struct {
+	sources *tsSources
+	// file is the source whose names are being resolved
+	file string
+	// resolving holds the declarations being expanded, to stop recursion
+	resolving map[*tsDecl]bool
+	defs      map[string]*JSONSchema
+	// defNames names declarations in $defs, apart when several files
+	// declare the same name
+	defNames map[*tsDecl]string
+	// Unresolved lists referenced types that are not declared
+	unresolved []string
+	// errors lists the type names that could not be resolved safely
+	errors []error
+}
+
+func newSchemaBuilder(sources *tsSources, file string) *schemaBuilder {
+	return &schemaBuilder{
+		sources:   sources,
+		file:      file,
+		resolving: map[*tsDecl]bool{},
+		defs:      map[string]*JSONSchema{},
+		defNames:  map[*tsDecl]string{},
+	}
+}
+
+// defName returns the $defs name of decl.
+func (b *schemaBuilder) defName(decl *tsDecl) string {
+	if name, ok := b.defNames[decl]; ok {
+		return name
+	}
+	name := decl.name
+	for i := 2; b.defNameTaken(name); i++ {
+		name = fmt.Sprintf("%s_%d", decl.name, i)
+	}
+	b.defNames[decl] = name
+	return name
+}
+
+func (b *schemaBuilder) defNameTaken(name string) bool {
+	for _, taken := range b.defNames {
+		if taken == name {
+			return true
+		}
+	}
+	return false
+}
+
+// root converts t into a standalone schema. Recursive types are defined
+// once in $defs and referenced.
+func (b *schemaBuilder) root(t *tsType) *JSONSchema {
+	schema := b.build(t)
+	if len(b.defs) > 0 {
+		schema.Defs = b.defs
+	}
+	schema.Schema = JSONSchemaDialect
+	return schema
+}
+
+func (b *schemaBuilder) build(t *tsType) *JSONSchema {
+	switch t.kind {
+	case "literal":
+		return literalSchema(t.literal)
+	case "object":
+		return b.object(t)
+	case "array":
+		return &JSONSchema{Type: JSONSchemaType{"array"}, Items: b.build(t.elem)}
+	case "tuple":
+		tuple := &JSONSchema{Type: JSONSchemaType{"array"}}
+		for _, element := range t.types {
+			tuple.PrefixItems = append(tuple.PrefixItems, b.build(element))
+		}
+		return tuple
+	case "union":
+		return b.union(t.types)
+	case "intersection":
+		parts := make([]*JSONSchema, len(t.types))
+		for i, part := range t.types {
+			parts[i] = b.build(part)
+		}
+		return mergeObjectSchemas(parts)
+	case "name":
+		return b.named(t)
+	}
+	return &JSONSchema{}
+}
+
+func literalSchema(value interface{}) *JSONSchema {
+	switch value.(type) {
+	case string:
+		return &JSONSchema{Type: JSONSchemaType{"string"}, Enum: []interface{}{value}}
+	case float64:
+		return &JSONSchema{Type: JSONSchemaType{"number"}, Enum: []interface{}{value}}
+	case bool:
+		return &JSONSchema{Type: JSONSchemaType{"boolean"}, Enum: []interface{}{value}}
+	}
+	return &JSONSchema{}
+}
+
+func (b *schemaBuilder) object(t *tsType) *JSONSchema {
+	object := &JSONSchema{Type: JSONSchemaType{"object"}, Properties: map[string]*JSONSchema{}}
+	for _, member := range t.members {
+		property := b.build(member.typ)
+		if member.doc != "" {
+			property.Description = member.doc
+		}
+		if _, exists := object.Properties[member.name]; !exists {
+			object.order = append(object.order, member.name)
+		}
+		object.Properties[member.name] = property
+		if !member.optional && !acceptsUndefined(member.typ) {
+			object.Required = appendUnique(object.Required, member.name)
+		}
+	}
+	if t.index != nil {
+		object.AdditionalProperties = b.build(t.index)
+	}
+	if len(object.Properties) == 0 {
+		object.Properties = nil
+	}
+	return object
+}
+
+func acceptsUndefined(t *tsType) bool {
+	if t.kind == "name" && (t.name == "undefined" || t.name == "void") {
+		return true
+	}
+	if t.kind == "union" {
+		for _, member := range t.types {
+			if acceptsUndefined(member) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (b *schemaBuilder) union(types []*tsType) *JSONSchema {
+	nullable := false
+	rest := []*tsType{}
+	for _, t := range types {
+		switch {
+		case t.kind == "name" && t.name == "null":
+			nullable = true
+		case t.kind == "name" && (t.name == "undefined" || t.name == "void" || t.name == "never"):
+		default:
+			rest = append(rest, t)
+		}
+	}
+
+	var schema *JSONSchema
+	options := make([]*JSONSchema, len(rest))
+	for i, t := range rest {
+		options[i] = b.build(t)
+	}
+
+	switch {
+	case len(options) == 0:
+		schema = &JSONSchema{}
+		if nullable {
+			return &JSONSchema{Type: JSONSchemaType{"null"}}
+		}
+	case len(options) == 1:
+		schema = options[0]
+	default:
+		schema = mergeEnums(options)
+		if schema == nil {
+			schema = mergeTypes(options)
+		}
+		if schema == nil {
+			schema = &JSONSchema{AnyOf: options}
+		}
+	}
+
+	if nullable {
+		return withNull(schema)
+	}
+	return schema
+}
+
+// mergeEnums turns a union of literals of the same type into an enum, and
+// true | false into boolean. It returns nil for other unions.
+func mergeEnums(options []*JSONSchema) *JSONSchema {
+	merged := &JSONSchema{Type: options[0].Type}
+	for _, option := range options {
+		if len(option.Enum) == 0 || len(option.Type) != 1 || option.Type[0] != merged.Type[0] {
+			return nil
+		}
+		for _, value := range option.Enum {
+			if !containsValue(merged.Enum, value) {
+				merged.Enum = append(merged.Enum, value)
+			}
+		}
+	}
+	if merged.Type[0] == "boolean" && len(merged.Enum) == 2 {
+		merged.Enum = nil
+	}
+	return merged
+}
+
+// mergeTypes turns a union of primitive types, such as string | number, into
+// a list of types. It returns nil for other unions.
+func mergeTypes(options []*JSONSchema) *JSONSchema {
+	merged := &JSONSchema{}
+	for _, option := range options {
+		if len(option.Type) != 1 || option.Type.Has("object") || option.Type.Has("array") ||
+			option.Format != "" || len(option.Enum) > 0 {
+			return nil
+		}
+		if !merged.Type.Has(option.Type[0]) {
+			merged.Type = append(merged.Type, option.Type[0])
+		}
+	}
+	return merged
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// withNull makes a schema accept null.
+func withNull(schema *JSONSchema) *JSONSchema {
+	if len(schema.Type) == 0 || schema.Ref != "" || len(schema.AnyOf) > 0 {
+		if len(schema.Type) == 0 && schema.Ref == "" && len(schema.AnyOf) == 0 && len(schema.AllOf) == 0 {
+			return schema // already accepts anything
+		}
+		if len(schema.AnyOf) > 0 && schema.Ref == "" {
+			schema.AnyOf = append(schema.AnyOf, &JSONSchema{Type: JSONSchemaType{"null"}})
+			return schema
+		}
+		return &JSONSchema{AnyOf: []*JSONSchema{schema, {Type: JSONSchemaType{"null"}}}}
+	}
+	if !schema.Type.Has("null") {
+		schema.Type = append(schema.Type, "null")
+	}
+	if len(schema.Enum) > 0 && !containsValue(schema.Enum, nil) {
+		schema.Enum = append(schema.Enum, nil)
+	}
+	return schema
+}
+
+// mergeObjectSchemas merges the object schemas of an intersection or of an
+// interface and the ones it extends. Other schemas are kept in allOf.
+func mergeObjectSchemas(parts []*JSONSchema) *JSONSchema {
+	merged := &JSONSchema{Type: JSONSchemaType{"object"}, Properties: map[string]*JSONSchema{}}
+	others := []*JSONSchema{}
+	for _, part := range parts {
+		if len(part.Type) != 1 || part.Type[0] != "object" || part.Ref != "" {
+			others = append(others, part)
+			continue
+		}
+		for _, name := range part.PropertyNames() {
+			if _, exists := merged.Properties[name]; !exists {
+				merged.order = append(merged.order, name)
+			}
+			merged.Properties[name] = part.Properties[name]
+		}
+		merged.Required = appendUnique(merged.Required, part.Required...)
+		if part.AdditionalProperties != nil {
+			merged.AdditionalProperties = part.AdditionalProperties
+		}
+	}
+	if len(merged.Properties) == 0 {
+		merged.Properties = nil
+	}
+	if len(others) == 0 {
+		return merged
+	}
+	if len(merged.Properties) == 0 && len(others) == 1 {
+		return others[0]
+	}
+	return &JSONSchema{AllOf: append([]*JSONSchema{merged}, others...)}
+}
+
+func (b *schemaBuilder) named(t *tsType) *JSONSchema {
+	arg := func(i int) *tsType {
+		if i < len(t.args) {
+			return t.args[i]
+		}
+		return &tsType{kind: "unknown"}
+	}
+
+	switch t.name {
+	case "string", "number", "boolean", "null":
+		return &JSONSchema{Type: JSONSchemaType{t.name}}
+	case "bigint":
+		return &JSONSchema{Type: JSONSchemaType{"integer"}}
+	case "object", "Object":
+		return &JSONSchema{Type: JSONSchemaType{"object"}}
+	case "any", "unknown", "undefined", "void", "never":
+		return &JSONSchema{}
+	case "Date":
+		return &JSONSchema{Type: JSONSchemaType{"string"}, Format: "date-time"}
+	case "Array", "ReadonlyArray", "Set", "ReadonlySet":
+		return &JSONSchema{Type: JSONSchemaType{"array"}, Items: b.build(arg(0))}
+	case "Record", "Map", "ReadonlyMap":
+		if keys := literalStrings(arg(0)); len(keys) > 0 && t.name == "Record" {
+			members := make([]tsMember, len(keys))
+			for i, key := range keys {
+				members[i] = tsMember{name: key, typ: arg(1)}
+			}
+			return b.object(&tsType{kind: "object", members: members})
+		}
+		return &JSONSchema{Type: JSONSchemaType{"object"}, AdditionalProperties: b.build(arg(1))}
+	case "Promise", "Readonly", "NonNullable":
+		return b.build(arg(0))
+	case "Partial", "Required":
+		schema := b.build(arg(0))
+		if t.name == "Partial" {
+			schema.Required = nil
+		} else {
+			schema.Required = schema.PropertyNames()
+		}
+		return schema
+	case "Pick", "Omit":
+		schema := b.build(arg(0))
+		keys := literalStrings(arg(1))
+		if schema.Properties == nil || keys == nil {
+			return schema
+		}
+		picked := &JSONSchema{Type: schema.Type, Properties: map[string]*JSONSchema{}, AdditionalProperties: schema.AdditionalProperties}
+		for _, name := range schema.PropertyNames() {
+			if containsString(keys, name) == (t.name == "Pick") {
+				picked.order = append(picked.order, name)
+				picked.Properties[name] = schema.Properties[name]
+				if containsString(schema.Required, name) {
+					picked.Required = append(picked.Required, name)
+				}
+			}
+		}
+		return picked
+	}
+
+	scope := b.file
+	if t.scope != "" {
+		scope = t.scope
+	}
+	decl, err := b.sources.resolve(scope, t.name)
+	if err != nil {
+		b.errors = append(b.errors, err)
+		return &JSONSchema{}
+	}
+	if decl == nil {
+		b.unresolved = appendUnique(b.unresolved, t.name)
+		return &JSONSchema{}
+	}
+
+	if decl.isEnum {
+		schema := &JSONSchema{Title: decl.name, Description: decl.doc, Enum: decl.enum}
+		for _, value := range decl.enum {
+			valueType := "number"
+			if _, ok := value.(string); ok {
+				valueType = "string"
+			}
+			if !schema.Type.Has(valueType) {
+				schema.Type = append(schema.Type, valueType)
+			}
+		}
+		return schema
+	}
+
+	defName := b.defName(decl)
+	ref := &JSONSchema{Ref: "#/$defs/" + defName}
+	if _, recursive := b.defs[defName]; recursive || b.resolving[decl] {
+		if !recursive {
+			b.defs[defName] = nil // defined once its expansion completes
+		}
+		return ref
+	}
+	b.resolving[decl] = true
+	defer delete(b.resolving, decl)
+
+	// Arguments keep the names of the caller, the body those of its file
+	bindings := map[string]*tsType{}
+	for i, param := range decl.params {
+		bindings[param] = scoped(arg(i), scope)
+	}
+	defer func(file string) { b.file = file }(b.file)
+	b.file = decl.file
+
+	schema := b.build(substituteType(decl.typ, bindings))
+	if len(decl.extends) > 0 {
+		parts := []*JSONSchema{}
+		for _, parent := range decl.extends {
+			parts = append(parts, b.build(substituteType(parent, bindings)))
+		}
+		schema = mergeObjectSchemas(append(parts, schema))
+	}
+	if schema.Ref == "" && schema.Title == "" && (schema.Type.Has("object") || len(schema.Enum) > 0) {
+		schema.Title = decl.name
+	}
+	if decl.doc != "" && schema.Description == "" {
+		schema.Description = decl.doc
+	}
+	if _, recursive := b.defs[defName]; recursive {
+		b.defs[defName] = schema
+		return ref
+	}
+	return schema
+}
+
+// literalStrings returns the values of a string literal or union of string
+// literals, or nil.
+func literalStrings(t *tsType) []string {
+	types := []*tsType{t}
+	if t.kind == "union" {
+		types = t.types
+	}
+	values := []string{}
+	for _, member := range types {
+		value, ok := member.literal.(string)
+		if member.kind != "literal" || !ok {
+			return nil
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// substituteType replaces the type parameters of a generic declaration.
+func substituteType(t *tsType, bindings map[string]*tsType) *tsType {
+	if t == nil || len(bindings) == 0 {
+		return t
+	}
+	if t.kind == "name" && len(t.args) == 0 {
+		if bound, ok := bindings[t.name]; ok {
+			return bound
+		}
+		return t
+	}
+
+	copied := *t
+	copied.elem = substituteType(t.elem, bindings)
+	copied.index = substituteType(t.index, bindings)
+	copied.args = substituteTypes(t.args, bindings)
+	copied.types = substituteTypes(t.types, bindings)
+	if t.members != nil {
+		copied.members = make([]tsMember, len(t.members))
+		for i, member := range t.members {
+			member.typ = substituteType(member.typ, bindings)
+			copied.members[i] = member
+		}
+	}
+	return &copied
+}
+
+func substituteTypes(types []*tsType, bindings map[string]*tsType) []*tsType {
+	if types == nil {
+		return nil
+	}
+	substituted := make([]*tsType, len(types))
+	for i, t := range types {
+		substituted[i] = substituteType(t, bindings)
+	}
+	return substituted
+}
+
+// fieldsFromJSONSchema lists the top-level fields of the items a schema
+// describes, in the format of the field list.
+func fieldsFromJSONSchema(schema *JSONSchema) []DataFieldDefinition {
+	item := schema
+	if item.Type.Has("array") && item.Items != nil {
+		item = item.Items
+	}
+	if item.Ref != "" {
+		item = schema.Defs[strings.TrimPrefix(item.Ref, "#/$defs/")]
+	}
+	if item == nil {
+		return nil
+	}
+
+	fields := []DataFieldDefinition{}
+	for _, name := range item.PropertyNames() {
+		property := item.Properties[name]
+		fields = append(fields, DataFieldDefinition{
+			Name:        name,
+			Type:        fieldType(property),
+			Description: property.Description,
+			Nullable:    property.Type.Has("null") || !containsString(item.Required, name),
+		})
+	}
+	return fields
+}
+
+// fieldType maps a JSON Schema to the field types of the field list.
+func fieldType(schema *JSONSchema) string {
+	for _, option := range schema.AnyOf {
+		if !option.Type.Has("null") || len(option.Type) > 1 {
+			return fieldType(option)
+		}
+	}
+	for _, t := range schema.Type {
+		switch t {
+		case "string":
+			if schema.Format == "date-time" || schema.Format == "date" {
+				return "date"
+			}
+			return "string"
+		case "integer", "number":
+			return "number"
+		case "boolean", "array", "object":
+			return t
+		}
+	}
+	return "object"
+}
+
+// jsonSchemaFromFields describes a list of items with the given fields, for
+// providers declaring a field list without a type argument.
+func jsonSchemaFromFields(fields []DataFieldDefinition) *JSONSchema {
+	item := &JSONSchema{Type: JSONSchemaType{"object"}, Properties: map[string]*JSONSchema{}}
+	for _, field := range fields {
+		property := &JSONSchema{Description: field.Description}
+		switch field.Type {
+		case "date":
+			property.Type, property.Format = JSONSchemaType{"string"}, "date-time"
+		case "string", "number", "boolean", "array", "object":
+			property.Type = JSONSchemaType{field.Type}
+		}
+		if field.Nullable {
+			property = withNull(property)
+		} else {
+			item.Required = append(item.Required, field.Name)
+		}
+		item.order = append(item.order, field.Name)
+		item.Properties[field.Name] = property
+	}
+	return &JSONSchema{Schema: JSONSchemaDialect, Type: JSONSchemaType{"array"}, Items: item}
+}
+
+var (
+	relativeImportRegex = regexp.MustCompile(`(?:\bfrom|\bimport)\s*['"](\.{1,2}/[^'"]+)['"]`)
+	dataProviderRegex   = regexp.MustCompile(`\buseDataProvider\b`)
+	maxModuleSources    = 200
+)
+
+// moduleSourceFiles returns entries and the files they import with
+// relative paths, transitively.
+func moduleSourceFiles(entries []string) []string {
+	files := []string{}
+	seen := map[string]bool{}
+	queue := append([]string{}, entries...)
+
+	for len(queue) > 0 && len(files) < maxModuleSources {
+		file := filepath.Clean(queue[0])
+		queue = queue[1:]
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		files = append(files, file)
+
+		for _, match := range relativeImportRegex.FindAllStringSubmatch(blankComments(string(content)), -1) {
+			if resolved := resolveImport(filepath.Dir(file), match[1]); resolved != "" {
+				queue = append(queue, resolved)
+			}
+		}
+	}
+	return files
+}
+
+func resolveImport(dir, specifier string) string {
+	base := filepath.Join(dir, specifier)
+	for _, suffix := range []string{"", ".ts", ".tsx", ".d.ts", "/index.ts", "/index.tsx"} {
+		candidate := base + suffix
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			if ext := filepath.Ext(candidate); ext == ".ts" || ext == ".tsx" {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// dataProviderCall is a useDataProvider call found in the module sources.
+type dataProviderCall struct {
+	file    string
+	line    int
+	typeArg string
+	args    []string
+}
+
+// findDataProviderCalls returns the useDataProvider calls of a source.
+func findDataProviderCalls(file, original string) []dataProviderCall {
+	source := blankComments(original)
+	calls := []dataProviderCall{}
+
+	for _, loc := range dataProviderRegex.FindAllStringIndex(source, -1) {
+		p := &tsTypeParser{src: source, pos: loc[1]}
+		call := dataProviderCall{file: file, line: strings.Count(source[:loc[0]], "\n") + 1}
+
+		if p.peek() == '<' {
+			end := matchingBracket(source, p.pos, '<', '>')
+			if end < 0 {
+				continue
+			}
+			call.typeArg = strings.TrimSpace(source[p.pos+1 : end])
+			p.pos = end + 1
+		}
+		if p.peek() != '(' {
+			continue // an import or a reference
+		}
+		end := matchingBracket(source, p.pos, '(', ')')
+		if end < 0 {
+			continue
+		}
+		call.args = splitArguments(source[p.pos+1 : end])
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// splitArguments splits call arguments on top-level commas. Unlike
+// splitTopLevel, angle brackets are comparisons here.
+func splitArguments(source string) []string {
+	args := []string{}
+	depth, start := 0, 0
+	for i := 0; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipString(source, i)
+		case strings.IndexByte("({[", c) >= 0:
+			depth++
+		case strings.IndexByte(")}]", c) >= 0:
+			depth--
+		case c == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(source[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(source[start:]); last != "" {
+		args = append(args, last)
+	}
+	return args
+}
+
+// objectBody returns the content of an object literal argument.
+func objectBody(arg string) string {
+	if strings.HasPrefix(arg, "{") && strings.HasSuffix(arg, "}") {
+		return arg[1 : len(arg)-1]
+	}
+	return ""
+}
+
+// propertyBody returns the content of the object or array literal assigned
+// to a top-level key of an object literal's content, or "".
+func propertyBody(content, key string) string {
+	depth := 0
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipString(content, i)
+		case strings.IndexByte("({[", c) >= 0:
+			depth++
+		case strings.IndexByte(")}]", c) >= 0:
+			depth--
+		case depth == 0 && strings.HasPrefix(content[i:], key) && (i == 0 || !isIdentByte(content[i-1])):
+			p := &tsTypeParser{src: content, pos: i + len(key)}
+			if !p.accept(":") {
+				continue
+			}
+			opening, closing := p.peek(), byte('}')
+			if opening == '[' {
+				closing = ']'
+			} else if opening != '{' {
+				continue
+			}
+			if end := matchingBracket(content, p.pos, opening, closing); end > 0 {
+				return content[p.pos+1 : end]
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
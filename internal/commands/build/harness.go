@@ -0,0 +1,269 @@
+package build
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed harness/harness.js
+var harnessScript []byte
+
+// DefaultCallTimeout bounds each handler call made through a Harness.
+const DefaultCallTimeout = 10 * time.Second
+
+// Harness runs a module component in a Node subprocess and calls the
+// interface handlers it passes to useModuleInterfaces.
+type Harness struct {
+	// Interfaces lists the methods of each handler captured while rendering
+	Interfaces map[string][]string
+	// CallTimeout bounds each call, DefaultCallTimeout when zero
+	CallTimeout time.Duration
+
+	dir    string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	lines  chan []byte
+	stderr *lockedBuffer
+	nextID int
+}
+
+// HarnessResult is the outcome of one handler call.
+type HarnessResult struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	// Async is set when the method returned a promise
+	Async bool `json:"async"`
+	// Type is the JavaScript type of the (awaited) result: undefined, null,
+	// array, date, string, number, boolean, object or function
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+
+	Duration time.Duration `json:"-"`
+}
+
+// Decoded returns the JSON-decoded result value.
+func (r *HarnessResult) Decoded() interface{} {
+	var value interface{}
+	if len(r.Value) > 0 {
+		json.Unmarshal(r.Value, &value)
+	}
+	return value
+}
+
+type harnessMessage struct {
+	HarnessResult
+	ID         int                 `json:"id"`
+	Ready      bool                `json:"ready"`
+	Fatal      string              `json:"fatal"`
+	Interfaces map[string][]string `json:"interfaces"`
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// StartHarness bundles componentFile with the build Compiler, renders it in
+// Node from projectPath (whose node_modules provide react, react-dom and
+// dashspace-lib) and waits for the captured handlers.
+func StartHarness(ctx context.Context, projectPath, componentFile string) (*Harness, error) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		return nil, fmt.Errorf("Node.js is required to run module handlers: %w", err)
+	}
+
+	code, err := NewCompiler(BuildOptions{Dev: true}).CompileCommonJS(componentFile)
+	if err != nil {
+		return nil, fmt.Errorf("compilation failed: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "dashspace-harness-")
+	if err != nil {
+		return nil, err
+	}
+	h := &Harness{dir: dir, lines: make(chan []byte), stderr: &lockedBuffer{}}
+
+	bundlePath := filepath.Join(dir, "bundle.js")
+	scriptPath := filepath.Join(dir, "harness.js")
+	if err := os.WriteFile(bundlePath, []byte(code), 0644); err != nil {
+		h.Close()
+		return nil, err
+	}
+	if err := os.WriteFile(scriptPath, harnessScript, 0644); err != nil {
+		h.Close()
+		return nil, err
+	}
+
+	h.cmd = exec.CommandContext(ctx, node, scriptPath, bundlePath, projectPath)
+	h.cmd.Dir = projectPath
+	h.cmd.Stderr = h.stderr
+	if h.stdin, err = h.cmd.StdinPipe(); err != nil {
+		h.Close()
+		return nil, err
+	}
+	stdout, err := h.cmd.StdoutPipe()
+	if err != nil {
+		h.Close()
+		return nil, err
+	}
+	if err := h.cmd.Start(); err != nil {
+		h.Close()
+		return nil, fmt.Errorf("failed to start node: %w", err)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			h.lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(h.lines)
+	}()
+
+	message, err := h.read(DefaultCallTimeout)
+	if err == nil && message.Fatal != "" {
+		err = fmt.Errorf("%s", message.Fatal)
+	}
+	if err == nil && !message.Ready {
+		err = fmt.Errorf("unexpected harness output")
+	}
+	if err != nil {
+		h.Close()
+		return nil, err
+	}
+
+	h.Interfaces = message.Interfaces
+	return h, nil
+}
+
+// StartComponentHarness starts a Harness for the Component.tsx of the
+// current directory.
+func StartComponentHarness(ctx context.Context) (*Harness, error) {
+	componentFile := findComponentFile()
+	if componentFile == "" {
+		return nil, fmt.Errorf("Component.tsx not found")
+	}
+	return StartHarness(ctx, ".", componentFile)
+}
+
+// Call calls iface.method with args and waits for its result. A map with
+// "$function": true is passed as a no-op callback.
+func (h *Harness) Call(iface, method string, args ...interface{}) (*HarnessResult, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+	h.nextID++
+	request, err := json.Marshal(map[string]interface{}{
+		"id":        h.nextID,
+		"interface": iface,
+		"method":    method,
+		"args":      args,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	if _, err := h.stdin.Write(append(request, '\n')); err != nil {
+		return nil, fmt.Errorf("harness stopped: %w", err)
+	}
+
+	timeout := h.CallTimeout
+	if timeout == 0 {
+		timeout = DefaultCallTimeout
+	}
+	message, err := h.read(timeout)
+	if err != nil {
+		return nil, err
+	}
+	if message.ID != h.nextID {
+		return nil, fmt.Errorf("unexpected harness output")
+	}
+
+	result := message.HarnessResult
+	result.Duration = time.Since(started)
+	return &result, nil
+}
+
+// read waits for the next message. The process is killed on timeout since
+// the pending call can no longer be told apart from the next one.
+func (h *Harness) read(timeout time.Duration) (*harnessMessage, error) {
+	select {
+	case line, ok := <-h.lines:
+		if !ok {
+			h.cmd.Wait()
+			return nil, fmt.Errorf("node exited unexpectedly%s", h.logTail())
+		}
+		message := &harnessMessage{}
+		if err := json.Unmarshal(line, message); err != nil {
+			return nil, fmt.Errorf("unexpected harness output: %s", line)
+		}
+		return message, nil
+	case <-time.After(timeout):
+		h.cmd.Process.Kill()
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// Logs returns what the module wrote to the console.
+func (h *Harness) Logs() string {
+	return h.stderr.String()
+}
+
+func (h *Harness) logTail() string {
+	logs := strings.TrimSpace(h.Logs())
+	if logs == "" {
+		return ""
+	}
+	lines := strings.Split(logs, "\n")
+	if len(lines) > 10 {
+		lines = lines[len(lines)-10:]
+	}
+	return ":\n" + strings.Join(lines, "\n")
+}
+
+// Close stops the Node process and removes the bundle.
+func (h *Harness) Close() error {
+	if h.stdin != nil {
+		h.stdin.Close()
+	}
+	if h.cmd != nil && h.cmd.Process != nil {
+		done := make(chan struct{})
+		go func() {
+			for range h.lines {
+			}
+			h.cmd.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			h.cmd.Process.Kill()
+			<-done
+		}
+	}
+	return os.RemoveAll(h.dir)
+}
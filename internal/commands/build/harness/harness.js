@@ -0,0 +1,224 @@
+'use strict';
+
+// Runs a module component outside the dashboard for `dashspace test`.
+//
+// The component bundle (CommonJS, react/react-dom/dashspace-lib external) is
+// rendered once with React's server renderer to capture the handlers passed
+// to useModuleInterfaces. Calls are then read as JSON lines on stdin and
+// answered as JSON lines on stdout:
+//
+//   <- {"ready": true, "interfaces": {"ISearchable": ["search", ...]}}
+//   -> {"id": 1, "interface": "ISearchable", "method": "search", "args": ["test"]}
+//   <- {"id": 1, "ok": true, "async": true, "type": "array", "value": [...]}
+//
+// Arguments of the form {"$function": true} are passed as no-op callbacks.
+// The module's console output goes to stderr.
+
+const fs = require('fs');
+const path = require('path');
+const readline = require('readline');
+const util = require('util');
+const { createRequire } = require('module');
+
+const [bundlePath, projectDir] = process.argv.slice(2);
+const projectRequire = createRequire(path.join(path.resolve(projectDir), 'package.json'));
+
+function send(message) {
+    process.stdout.write(JSON.stringify(message) + '\n');
+}
+
+function fatal(message, err) {
+    send({ fatal: message + (err ? ': ' + (err.stack || err) : '') });
+    process.exit(1);
+}
+
+for (const level of ['log', 'info', 'warn', 'error', 'debug']) {
+    console[level] = (...args) => process.stderr.write(util.format(...args) + '\n');
+}
+
+let captured = null;
+function useModuleInterfaces(handlers) {
+    captured = handlers;
+}
+
+// What the dashboard provides, with empty data: enough to render
+const memoryStorage = new Map();
+const host = {
+    getConfig: () => ({}),
+    isSetupCompleted: () => true,
+    callProvider: async () => [],
+    storage: {
+        get: async (key) => memoryStorage.get(key),
+        set: async (key, value) => { memoryStorage.set(key, value); },
+        remove: async (key) => { memoryStorage.delete(key); }
+    }
+};
+
+// Without an installed dashspace-lib, the parts modules use while rendering
+function stubLib() {
+    const names = (list) => Object.fromEntries(list.map((name) => [name, name]));
+    const noopClass = function () {};
+    return {
+        Provider: names(['GITHUB', 'STRIPE', 'GITLAB', 'LINEAR', 'SHOPIFY', 'GOOGLE', 'SENTRY', 'VERCEL',
+            'NETLIFY', 'ATLASSIAN', 'ASANA', 'PAGERDUTY', 'SLACK', 'DISCORD', 'NOTION', 'CALENDLY',
+            'AIRTABLE', 'FIGMA']),
+        ModuleInterfaces: names(['ISearchable', 'IRefreshable', 'IExportable', 'IFilterable', 'IThemeable',
+            'INotifiable', 'IDataProvider', 'ISchedulable']),
+        useModuleConfig: () => ({}),
+        useProvider: () => ({ call: async () => ({ data: [] }), isAuthenticated: () => true }),
+        useModuleStorage: () => host.storage,
+        useModuleEvent: () => {},
+        useNotification: () => ({ show() {}, showNotification() {} }),
+        useWebhookEvents: () => ({ events: [] }),
+        useProviderWebhooks: () => ({ events: [] }),
+        useDataProvider: () => ({ data: [], loading: false, error: null }),
+        useDataQuery: () => ({ data: [], loading: false, error: null }),
+        BaseModule: noopClass,
+        DashspaceModule: noopClass,
+        ConfigurationStep: noopClass,
+        TextField: noopClass,
+        NumberField: noopClass,
+        SelectField: noopClass,
+        BooleanField: noopClass,
+        PasswordField: noopClass,
+        UrlField: noopClass,
+        UQL_EXAMPLES: []
+    };
+}
+
+function loadLib() {
+    let real;
+    try {
+        real = projectRequire('dashspace-lib');
+    } catch (err) {
+        console.warn('dashspace-lib is not installed, using a stub');
+        real = stubLib();
+    }
+    const lib = { ...real, useModuleInterfaces };
+    if (real.__esModule) {
+        Object.defineProperty(lib, '__esModule', { value: true });
+    }
+    return lib;
+}
+
+let React, ReactDOMServer;
+try {
+    React = projectRequire('react');
+    ReactDOMServer = projectRequire('react-dom/server');
+} catch (err) {
+    fatal('react and react-dom must be installed to render the component (run npm install)');
+}
+
+global.window = global;
+global.self = global;
+window.DashspaceLib = window.DashspaceLib || host;
+window.DASHSPACE_LIB = window.DASHSPACE_LIB || host;
+
+const lib = loadLib();
+function requireShim(name) {
+    if (name === 'dashspace-lib') {
+        return lib;
+    }
+    if (name === 'react') {
+        return React;
+    }
+    return projectRequire(name);
+}
+
+const bundle = { exports: {} };
+try {
+    new Function('require', 'module', 'exports', fs.readFileSync(bundlePath, 'utf8'))(requireShim, bundle, bundle.exports);
+} catch (err) {
+    fatal('failed to load the component bundle', err);
+}
+
+const exported = bundle.exports;
+const Component = typeof exported === 'function' ? exported : exported.default;
+if (typeof Component !== 'function') {
+    fatal('the component file must default-export a React component');
+}
+
+try {
+    ReactDOMServer.renderToString(React.createElement(Component, {}));
+} catch (err) {
+    fatal('rendering the component failed', err);
+}
+
+if (!captured || typeof captured !== 'object') {
+    fatal('useModuleInterfaces was not called while rendering the component');
+}
+
+// Handlers may be class instances: their methods live on the prototypes
+function methodNames(handler) {
+    const names = new Set();
+    for (let obj = handler; obj && obj !== Object.prototype; obj = Object.getPrototypeOf(obj)) {
+        for (const name of Object.getOwnPropertyNames(obj)) {
+            if (name !== 'constructor' && typeof handler[name] === 'function') {
+                names.add(name);
+            }
+        }
+    }
+    return [...names];
+}
+
+const interfaces = {};
+for (const [name, handler] of Object.entries(captured)) {
+    interfaces[name] = handler && typeof handler === 'object' ? methodNames(handler) : [];
+}
+send({ ready: true, interfaces });
+
+function revive(arg) {
+    if (arg && typeof arg === 'object' && arg.$function === true) {
+        return () => {};
+    }
+    return arg;
+}
+
+function typeOf(value) {
+    if (value === null) return 'null';
+    if (Array.isArray(value)) return 'array';
+    if (value instanceof Date) return 'date';
+    return typeof value;
+}
+
+function serialize(value) {
+    try {
+        return JSON.parse(JSON.stringify(value, (key, v) => typeof v === 'function' ? '[function]' : v) ?? 'null');
+    } catch (err) {
+        return '[unserializable: ' + err.message + ']';
+    }
+}
+
+const rl = readline.createInterface({ input: process.stdin });
+const queue = [];
+let busy = false;
+
+async function drain() {
+    if (busy) return;
+    busy = true;
+    while (queue.length > 0) {
+        const call = queue.shift();
+        const handler = captured[call.interface];
+        try {
+            if (!handler || typeof handler[call.method] !== 'function') {
+                throw new Error(call.interface + '.' + call.method + ' is not a function');
+            }
+            let value = handler[call.method](...(call.args || []).map(revive));
+            const isAsync = !!value && typeof value.then === 'function';
+            value = await value;
+            send({ id: call.id, ok: true, async: isAsync, type: typeOf(value), value: serialize(value) });
+        } catch (err) {
+            send({ id: call.id, ok: false, error: String((err && err.message) || err) });
+        }
+    }
+    busy = false;
+}
+
+rl.on('line', (line) => {
+    if (line.trim() === '') return;
+    queue.push(JSON.parse(line));
+    drain();
+});
+
+// Modules may leave timers running
+rl.on('close', () => process.exit(0));
@@ -7,9 +7,29 @@ import (
 	"strings"
 )
 
-type InterfaceValidator struct{}
+// InterfaceValidator checks the handlers of Component.tsx against the
+// interface contracts of dashspace-lib.
+type InterfaceValidator struct {
+	contracts *InterfaceContracts
+}
+
+func (v *InterfaceValidator) contractsFor() *InterfaceContracts {
+	if v.contracts == nil {
+		v.contracts = LoadInterfaceContracts(".")
+		fmt.Printf("📚 Interface contracts: %s\n", v.contracts.Source)
+	}
+	return v.contracts
+}
 
 func (v *InterfaceValidator) ValidateImplementation(declaredInterfaces []string) error {
+	// The embedded contracts may predate an interface, the installed lib cannot
+	contracts := v.contractsFor()
+	for _, declared := range declaredInterfaces {
+		if _, _, ok := contracts.Lookup(declared); !ok && contracts.Installed {
+			return fmt.Errorf("interface %s is not defined by dashspace-lib %s", declared, contracts.LibVersion)
+		}
+	}
+
 	componentFile := findComponentFile()
 	if componentFile == "" {
 		return fmt.Errorf("Component.tsx not found")
@@ -20,43 +40,40 @@ func (v *InterfaceValidator) ValidateImplementation(declaredInterfaces []string)
 		return fmt.Errorf("failed to read component file: %w", err)
 	}
 
-	componentContent := string(content)
+	componentContent := blankComments(string(content))
 
 	if !strings.Contains(componentContent, "useModuleInterfaces") {
 		return fmt.Errorf("Component does not use useModuleInterfaces hook")
 	}
 
-	handlersRegex := regexp.MustCompile(`(?s)(?:const|let|var)\s+handlers\s*(?::\s*\w+\s*)?=\s*\{(.*?)\}\s*satisfies\s+InterfaceHandlers`)
-	handlersMatch := handlersRegex.FindStringSubmatch(componentContent)
-
-	if len(handlersMatch) < 2 {
-		handlersRegex = regexp.MustCompile(`(?s)(?:const|let|var)\s+handlers\s*(?::\s*\w+\s*)?=\s*\{(.*?)\}(?:\s*;|\s*\n|\s*$)`)
-		handlersMatch = handlersRegex.FindStringSubmatch(componentContent)
-	}
-
-	if len(handlersMatch) < 2 {
+	handlers, ok := findHandlersObject(componentContent)
+	if !ok {
 		return fmt.Errorf("handlers object not found in Component")
 	}
 
-	handlersContent := handlersMatch[1]
-	implementedInterfaces := v.extractImplementedInterfaces(handlersContent)
+	implemented := map[string]objectMember{}
+	for _, member := range handlers.members {
+		if strings.HasPrefix(member.Name, "I") {
+			implemented[member.Name] = member
+		}
+	}
 
 	for _, declared := range declaredInterfaces {
-		found := false
-		for _, implemented := range implementedInterfaces {
-			if declared == implemented || "I"+declared == implemented || declared == "I"+implemented {
-				found = true
-				break
-			}
+		_, found := implemented[declared]
+		if !found && !strings.HasPrefix(declared, "I") {
+			_, found = implemented["I"+declared]
 		}
 		if !found {
 			return fmt.Errorf("interface %s declared in Module.ts but not implemented in Component.tsx", declared)
 		}
 	}
 
-	for _, interfaceName := range implementedInterfaces {
-		if err := v.validateInterfaceMethods(handlersContent, interfaceName); err != nil {
-			return fmt.Errorf("interface %s validation failed: %w", interfaceName, err)
+	for _, member := range handlers.members {
+		if _, ok := implemented[member.Name]; !ok {
+			continue
+		}
+		if err := v.validateInterfaceMethods(componentContent, member); err != nil {
+			return fmt.Errorf("interface %s validation failed: %w", member.Name, err)
 		}
 	}
 
@@ -64,88 +81,180 @@ func (v *InterfaceValidator) ValidateImplementation(declaredInterfaces []string)
 	return nil
 }
 
-func (v *InterfaceValidator) extractImplementedInterfaces(handlersContent string) []string {
-	interfaces := []string{}
+func (v *InterfaceValidator) validateInterfaceMethods(source string, handler objectMember) error {
+	contracts := v.contractsFor()
+	_, contract, exists := contracts.Lookup(handler.Name)
+	if !exists {
+		if contracts.Installed {
+			return fmt.Errorf("not defined by dashspace-lib %s", contracts.LibVersion)
+		}
+		fmt.Printf("⚠️  Warning: Unknown interface %s, skipping method validation\n", handler.Name)
+		return nil
+	}
 
-	interfaceRegex := regexp.MustCompile(`(?m)^\s*["']?(\w+)["']?\s*:\s*\{`)
-	matches := interfaceRegex.FindAllStringSubmatch(handlersContent, -1)
+	open := objectLiteralAt(source, handler.Value)
+	if open < 0 {
+		// Built elsewhere (a variable, useMemo...): only the TypeScript probe can check it
+		return nil
+	}
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			interfaceName := match[1]
-			if strings.HasPrefix(interfaceName, "I") {
-				interfaces = append(interfaces, interfaceName)
-			}
+	defined := map[string]bool{}
+	for _, member := range objectMembers(source, open) {
+		defined[member.Name] = true
+	}
+
+	missingMethods := []string{}
+	for _, method := range contract.Methods {
+		if !defined[method] {
+			missingMethods = append(missingMethods, method)
 		}
 	}
 
-	return interfaces
+	if len(missingMethods) > 0 {
+		return fmt.Errorf("missing methods: %v", missingMethods)
+	}
+
+	return nil
 }
 
-func (v *InterfaceValidator) validateInterfaceMethods(handlersContent string, interfaceName string) error {
-	requiredMethods := map[string][]string{
-		"ISearchable":   {"search", "getSearchResults", "getSearchFilters", "translateUQLQuery", "getUQLCapabilities"},
-		"IRefreshable":  {"refresh", "getLastRefresh", "setAutoRefresh"},
-		"IExportable":   {"export", "getSupportedFormats", "exportData"},
-		"IFilterable":   {"applyFilter", "clearFilters", "getCurrentFilter", "translateUQLQuery", "getUQLCapabilities"},
-		"IThemeable":    {"applyTheme", "getThemeConfig", "getSupportedThemes"},
-		"INotifiable":   {"sendNotification", "subscribe", "unsubscribe"},
-		"IDataProvider": {"getData", "getDataSchema", "subscribe"},
-		"ISchedulable":  {"schedule", "unschedule", "getSchedule"},
-	}
+// handlersObject is the object literal assigned to `handlers` in Component.tsx.
+type handlersObject struct {
+	open, close int // offsets of its braces
+	end         int // offset just after the declaration statement
+	members     []objectMember
+}
 
-	methods, exists := requiredMethods[interfaceName]
-	if !exists {
-		fmt.Printf("⚠️  Warning: Unknown interface %s, skipping method validation\n", interfaceName)
-		return nil
-	}
+// objectMember is a property or method of an object literal.
+type objectMember struct {
+	Name   string
+	Offset int // offset of the key
+	Value  int // offset of the value, or of the key for methods and shorthands
+}
 
-	escapedName := regexp.QuoteMeta(interfaceName)
-	interfacePattern := fmt.Sprintf(`(?s)%s\s*:\s*\{(.*?)\}\s*satisfies\s+%s`, escapedName, escapedName)
-	interfaceBlockRegex := regexp.MustCompile(interfacePattern)
-	blockMatch := interfaceBlockRegex.FindStringSubmatch(handlersContent)
+var (
+	handlersDeclRegex  = regexp.MustCompile(`(?:const|let|var)\s+handlers\s*(?::\s*[\w$.<>, ]+\s*)?=\s*\{`)
+	statementTailRegex = regexp.MustCompile(`^(?:\s*(?:satisfies|as)\s+[\w$.]+(?:<[^;\n]*>)?)*\s*;?`)
+	memberKeyRegex     = regexp.MustCompile(`^(?:(?:async|get|set|static)\s+)*\*?\s*([A-Za-z_$][\w$]*|'[^']*'|"[^"]*")\s*`)
+)
 
-	if len(blockMatch) < 2 {
-		interfacePattern = fmt.Sprintf(`(?s)%s\s*:\s*\{(.*?)\}(?:\s*,|\s*\n)`, escapedName)
-		interfaceBlockRegex = regexp.MustCompile(interfacePattern)
-		blockMatch = interfaceBlockRegex.FindStringSubmatch(handlersContent)
+// findHandlersObject locates the handlers declaration in source, which must
+// have its comments blanked.
+func findHandlersObject(source string) (*handlersObject, bool) {
+	loc := handlersDeclRegex.FindStringIndex(source)
+	if loc == nil {
+		return nil, false
 	}
 
-	if len(blockMatch) < 2 {
-		return fmt.Errorf("could not find interface block for %s", interfaceName)
+	open := loc[1] - 1
+	close := matchingBrace(source, open)
+	if close < 0 {
+		return nil, false
 	}
 
-	interfaceBlock := blockMatch[1]
+	tail := statementTailRegex.FindStringIndex(source[close+1:])
+	return &handlersObject{
+		open:    open,
+		close:   close,
+		end:     close + 1 + tail[1],
+		members: objectMembers(source, open),
+	}, true
+}
 
-	missingMethods := []string{}
-	for _, method := range methods {
-		methodPattern := fmt.Sprintf(`\b%s\s*[:=]\s*(?:async\s+)?(?:\([^)]*\)\s*=>|\w)`, regexp.QuoteMeta(method))
-		methodRegex := regexp.MustCompile(methodPattern)
-		if !methodRegex.MatchString(interfaceBlock) {
-			missingMethods = append(missingMethods, method)
+// objectLiteralAt returns the offset of the object literal starting at
+// offset, looking through parentheses, or -1.
+func objectLiteralAt(source string, offset int) int {
+	for i := offset; i >= 0 && i < len(source); i++ {
+		switch source[i] {
+		case ' ', '\t', '\n', '\r', '(':
+			continue
+		case '{':
+			return i
 		}
+		return -1
 	}
+	return -1
+}
 
-	if len(missingMethods) > 0 {
-		return fmt.Errorf("missing methods: %v", missingMethods)
+// objectMembers lists the top-level members of the object literal whose
+// opening brace is at open. Spreads and computed keys are skipped.
+func objectMembers(source string, open int) []objectMember {
+	close := matchingBrace(source, open)
+	if close < 0 {
+		return nil
 	}
 
-	return nil
+	members := []objectMember{}
+	start := open + 1
+	depth := 0
+	for i := open + 1; i <= close; i++ {
+		switch c := source[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipString(source, i)
+		case i == close || (c == ',' && depth == 0):
+			if member, ok := parseObjectMember(source, start, i); ok {
+				members = append(members, member)
+			}
+			start = i + 1
+		case strings.IndexByte("([{", c) >= 0:
+			depth++
+		case strings.IndexByte(")]}", c) >= 0:
+			depth--
+		}
+	}
+	return members
 }
 
-func isKnownInterface(name string) bool {
-	knownInterfaces := []string{
-		"Searchable", "Refreshable", "Exportable", "Filterable",
-		"Configurable", "Themeable", "Notifiable", "DataProvider",
-		"Widget", "Schedulable",
+func parseObjectMember(source string, start, end int) (objectMember, bool) {
+	text := source[start:end]
+	trimmed := strings.TrimLeft(text, " \t\r\n")
+	offset := start + len(text) - len(trimmed)
+
+	m := memberKeyRegex.FindStringSubmatchIndex(trimmed)
+	if m == nil {
+		return objectMember{}, false
 	}
+	member := objectMember{
+		Name:   strings.Trim(trimmed[m[2]:m[3]], `'"`),
+		Offset: offset + m[2],
+	}
+
+	rest := trimmed[m[1]:]
+	switch {
+	case strings.HasPrefix(rest, ":"):
+		member.Value = offset + m[1] + 1
+	case strings.HasPrefix(rest, "("), strings.HasPrefix(rest, "<"), strings.TrimSpace(rest) == "":
+		member.Value = member.Offset
+	default:
+		return objectMember{}, false
+	}
+	return member, true
+}
 
-	for _, known := range knownInterfaces {
-		if name == known {
-			return true
+// blankComments replaces comments with spaces, keeping line breaks, so
+// offsets still match the original source.
+func blankComments(source string) string {
+	b := []byte(source)
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '\'' || b[i] == '"' || b[i] == '`':
+			i = skipString(source, i)
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '/':
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+			for ; i < len(b) && !(b[i] == '*' && i+1 < len(b) && b[i+1] == '/'); i++ {
+				if b[i] != '\n' {
+					b[i] = ' '
+				}
+			}
+			if i+1 < len(b) {
+				b[i], b[i+1] = ' ', ' '
+				i++
+			}
 		}
 	}
-	return false
+	return string(b)
 }
 
 func findComponentFile() string {
@@ -20,6 +20,11 @@ func NewParser(moduleFile string) *Parser {
 	}
 }
 
+// ModuleFile returns the path of the parsed module file.
+func (p *Parser) ModuleFile() string {
+	return p.moduleFile
+}
+
 func (p *Parser) loadContent() error {
 	if p.moduleContent != "" {
 		return nil
@@ -56,6 +61,8 @@ func (p *Parser) ExtractMetadata() (*DashspaceConfig, error) {
 			metadataContent := metadataMatch[1]
 			p.parseMetadataFields(metadataContent, config)
 		}
+
+		config.Size = extractWidgetSizes(factoryContent)
 	}
 
 	if config.ID == 0 {
@@ -172,6 +179,24 @@ func (p *Parser) ExtractProviders() ([]map[string]interface{}, error) {
 }
 
 func (p *Parser) ExtractInterfaces() ([]string, error) {
+	interfaces, err := p.DeclaredInterfaces()
+	if err != nil || len(interfaces) == 0 {
+		return interfaces, err
+	}
+
+	fmt.Printf("✅ Found %d interfaces: %v\n", len(interfaces), interfaces)
+
+	validator := &InterfaceValidator{}
+	if err := validator.ValidateImplementation(interfaces); err != nil {
+		return interfaces, fmt.Errorf("interface validation failed: %w", err)
+	}
+
+	return interfaces, nil
+}
+
+// DeclaredInterfaces returns the ModuleInterfaces passed to the module
+// constructor, without validating their implementation.
+func (p *Parser) DeclaredInterfaces() ([]string, error) {
 	if err := p.loadContent(); err != nil {
 		return nil, err
 	}
@@ -184,7 +209,6 @@ func (p *Parser) ExtractInterfaces() ([]string, error) {
 	}
 
 	interfacesVarName := strings.TrimSpace(constructorMatch[1])
-	fmt.Printf("📋 Found interfaces variable: %s\n", interfacesVarName)
 
 	varDefRegex := regexp.MustCompile(`(?s)(?:const|let|var)\s+` + regexp.QuoteMeta(interfacesVarName) + `[^=]*=\s*\[(.*?)\]`)
 	varDefMatch := varDefRegex.FindStringSubmatch(p.moduleContent)
@@ -193,25 +217,10 @@ func (p *Parser) ExtractInterfaces() ([]string, error) {
 		return nil, nil
 	}
 
-	interfacesContent := varDefMatch[1]
 	interfaces := []string{}
-
 	interfaceRegex := regexp.MustCompile(`ModuleInterfaces\.(\w+)`)
-	interfaceMatches := interfaceRegex.FindAllStringSubmatch(interfacesContent, -1)
-
-	for _, match := range interfaceMatches {
-		if len(match) > 1 {
-			interfaces = append(interfaces, match[1])
-		}
-	}
-
-	if len(interfaces) > 0 {
-		fmt.Printf("✅ Found %d interfaces: %v\n", len(interfaces), interfaces)
-
-		validator := &InterfaceValidator{}
-		if err := validator.ValidateImplementation(interfaces); err != nil {
-			return interfaces, fmt.Errorf("interface validation failed: %w", err)
-		}
+	for _, match := range interfaceRegex.FindAllStringSubmatch(varDefMatch[1], -1) {
+		interfaces = append(interfaces, match[1])
 	}
 
 	return interfaces, nil
@@ -312,6 +321,10 @@ func (p *Parser) ExtractPermissions() ([]string, error) {
 
 	permissionsRegex := regexp.MustCompile(`getPermissions\s*\(\)\s*\{[^}]*return\s*\[(.*?)\]`)
 	match := permissionsRegex.FindStringSubmatch(p.moduleContent)
+	if match == nil {
+		// Or in the metadata, permissions: [...]
+		match = regexp.MustCompile(`(?s)\bpermissions\s*:\s*\[(.*?)\]`).FindStringSubmatch(p.moduleContent)
+	}
 
 	if len(match) > 1 {
 		permContent := match[1]
@@ -327,3 +340,64 @@ func (p *Parser) ExtractPermissions() ([]string, error) {
 
 	return permissions, nil
 }
+
+// extractWidgetSizes reads `size: { min: {...}, default: {...}, max: {...} }`
+// (or a flat `size: { width, height }`) from the module metadata.
+func extractWidgetSizes(content string) *WidgetSizes {
+	sizeIdx := regexp.MustCompile(`\bsize:\s*\{`).FindStringIndex(content)
+	if sizeIdx == nil {
+		return nil
+	}
+
+	start := sizeIdx[1] - 1
+	braceCount := 0
+	end := -1
+	for i := start; i < len(content); i++ {
+		if content[i] == '{' {
+			braceCount++
+		} else if content[i] == '}' {
+			braceCount--
+			if braceCount == 0 {
+				end = i
+				break
+			}
+		}
+	}
+	if end == -1 {
+		return nil
+	}
+
+	sizeContent := content[start+1 : end]
+	sizes := &WidgetSizes{}
+
+	nestedRegex := regexp.MustCompile(`(min|default|max):\s*\{([^}]*)\}`)
+	for _, match := range nestedRegex.FindAllStringSubmatch(sizeContent, -1) {
+		size := parseWidgetSize(match[2])
+		switch match[1] {
+		case "min":
+			sizes.Min = size
+		case "default":
+			sizes.Default = size
+		case "max":
+			sizes.Max = size
+		}
+	}
+
+	if sizes.Default == nil && sizes.Min == nil && sizes.Max == nil {
+		sizes.Default = parseWidgetSize(sizeContent)
+	}
+
+	if sizes.Default == nil && sizes.Min == nil && sizes.Max == nil {
+		return nil
+	}
+	return sizes
+}
+
+func parseWidgetSize(content string) *WidgetSize {
+	width := extractNumberValue(content, "width")
+	height := extractNumberValue(content, "height")
+	if width == -1 || height == -1 {
+		return nil
+	}
+	return &WidgetSize{Width: width, Height: height}
+}
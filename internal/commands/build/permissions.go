@@ -0,0 +1,446 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// NetworkPermission allows requests to any external host. Appending
+// ":<host>" restricts it to one host, "*.<domain>" to the subdomains of a
+// domain.
+const NetworkPermission = "network:external"
+
+// permissionRule is a call of the module API or of a browser API that needs
+// a permission.
+type permissionRule struct {
+	permission string
+	pattern    *regexp.Regexp
+}
+
+var permissionRules = []permissionRule{
+	{"storage:read", regexp.MustCompile(`\b(?:getStoredData|useModuleStorage)\s*\(`)},
+	{"storage:read", regexp.MustCompile(`\b\w*[sS]torage\s*\.\s*(?:get|getItem|has|keys|key)\s*\(`)},
+	{"storage:write", regexp.MustCompile(`\b(?:storeData|removeStoredData)\s*\(`)},
+	{"storage:write", regexp.MustCompile(`\b\w*[sS]torage\s*\.\s*(?:set|setItem|remove|removeItem|clear)\s*\(`)},
+	{"ui:notifications", regexp.MustCompile(`\b(?:showNotification|useNotification)\s*\(`)},
+	{"ui:modals", regexp.MustCompile(`\b(?:showModal|openModal|useModal)\s*\(`)},
+	{"ui:modals", regexp.MustCompile(`\bwindow\s*\.\s*(?:alert|confirm|prompt)\s*\(`)},
+}
+
+var (
+	// requestRegexes match the calls sending requests, up to the URL argument
+	requestRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`\bfetch\s*\(`),
+		regexp.MustCompile(`\baxios(?:\s*\.\s*(?:get|post|put|patch|delete|head|request))?\s*\(`),
+		regexp.MustCompile(`\.open\s*\(\s*['"][A-Za-z]+['"]\s*,`), // XMLHttpRequest
+		regexp.MustCompile(`\bnew\s+(?:WebSocket|EventSource)\s*\(`),
+		regexp.MustCompile(`\bnavigator\s*\.\s*sendBeacon\s*\(`),
+	}
+	urlConstantRegex    = regexp.MustCompile(`\b([A-Za-z_$][\w$]*)\s*[:=]\s*['"` + "`" + `]((?:https?|wss?):)?//([^/'"` + "`" + `?#:$]+)`)
+	urlRegex            = regexp.MustCompile(`^(?:(?:https?|wss?):)?//([^/'"` + "`" + `?#:$]+)`)
+	absoluteURLRegex    = regexp.MustCompile(`^(?:(?:https?|wss?):)?//`)
+	identifierRegex     = regexp.MustCompile(`[A-Za-z_$][\w$]*`)
+	providerCallRegex   = regexp.MustCompile(`\b(?:callProvider|useProvider|providers\s*\.\s*call)\s*\(\s*(?:Provider\s*\.\s*(\w+)|['"]([\w-]+)['"])`)
+	permissionHostRegex = regexp.MustCompile(`^(?:\*\.)?[a-z0-9]([a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*(?::\d+)?$`)
+)
+
+// PermissionUsage is a place where the module code needs a permission.
+type PermissionUsage struct {
+	Permission string
+	// Host is the host of a network request
+	Host     string
+	Call     string
+	Location string
+}
+
+func (u PermissionUsage) String() string {
+	return fmt.Sprintf("%s at %s", u.Call, u.Location)
+}
+
+// PermissionAudit compares the declared permissions and providers with what
+// the code does.
+type PermissionAudit struct {
+	Usages []PermissionUsage
+	// Missing are usages no declared permission covers
+	Missing []PermissionUsage
+	// Unused are declared permissions no usage needs
+	Unused []string
+	// Narrower suggests host permissions replacing network:external
+	Narrower []string
+	// DynamicRequests are requests to URLs computed at runtime
+	DynamicRequests []PermissionUsage
+	// UndeclaredProviders are calls to providers missing from the providers
+	UndeclaredProviders []PermissionUsage
+	UnusedProviders     []string
+}
+
+// PermissionAuditor scans the module sources and its bundle.
+type PermissionAuditor struct {
+	files  []string
+	bundle string
+}
+
+// NewPermissionAuditor audits Module.ts, the component, the hooks and the
+// files they import, and the compiled bundle, whose dependencies may use
+// APIs the sources do not.
+func NewPermissionAuditor(bundle string) *PermissionAuditor {
+	return &PermissionAuditor{files: auditedSourceFiles(), bundle: bundle}
+}
+
+// auditedSourceFiles returns Module.ts, the component, the hooks and the
+// files they import.
+func auditedSourceFiles() []string {
+	entries := hooksFiles()
+	if componentFile := findComponentFile(); componentFile != "" {
+		entries = append([]string{componentFile}, entries...)
+	}
+	if moduleFile := findModuleFile(); moduleFile != "" {
+		entries = append([]string{moduleFile}, entries...)
+	}
+	return moduleSourceFiles(entries)
+}
+
+// Audit checks permissions and the names of the declared providers against
+// the code.
+func (a *PermissionAuditor) Audit(permissions []string, providers []string) (*PermissionAudit, error) {
+	audit := &PermissionAudit{}
+	seen := map[string]bool{}
+	dynamicCalls := map[string]bool{}
+	calledProviders := map[string]bool{}
+
+	scan := func(source, file string) {
+		for _, usage := range scanPermissionUsages(source, file) {
+			key := usage.Permission + "|" + usage.Host + "|" + usage.Call
+			if usage.Permission == NetworkPermission && usage.Host == "" {
+				// Every request of the sources to a computed URL is listed, the
+				// bundle only adds the kinds of requests the sources do not send
+				if file == "bundle.js" && dynamicCalls[usage.Call] {
+					continue
+				}
+				dynamicCalls[usage.Call] = true
+				key += "|" + usage.Location
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			audit.Usages = append(audit.Usages, usage)
+		}
+		for _, call := range scanProviderCalls(source, file) {
+			if !calledProviders[call.Host] && !containsString(providers, call.Host) {
+				audit.UndeclaredProviders = append(audit.UndeclaredProviders, call)
+			}
+			calledProviders[call.Host] = true
+		}
+	}
+
+	for _, file := range a.files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		scan(blankComments(string(content)), file)
+	}
+	if a.bundle != "" {
+		scan(a.bundle, "bundle.js")
+	}
+
+	for _, provider := range providers {
+		if !calledProviders[provider] {
+			audit.UnusedProviders = append(audit.UnusedProviders, provider)
+		}
+	}
+
+	audit.compare(permissions)
+	return audit, nil
+}
+
+// compare sorts the usages into missing permissions and finds the unused ones.
+func (audit *PermissionAudit) compare(permissions []string) {
+	used := map[string]bool{}
+	hosts := []string{}
+	missingKeys := map[string]bool{}
+
+	for _, usage := range audit.Usages {
+		if usage.Permission == NetworkPermission {
+			if usage.Host == "" {
+				audit.DynamicRequests = append(audit.DynamicRequests, usage)
+				continue
+			}
+			hosts = appendUnique(hosts, usage.Host)
+		}
+
+		covering := coveringPermissions(permissions, usage)
+		for _, permission := range covering {
+			used[permission] = true
+		}
+		if len(covering) == 0 && !missingKeys[usage.Permission+usage.Host] {
+			missingKeys[usage.Permission+usage.Host] = true
+			audit.Missing = append(audit.Missing, usage)
+		}
+	}
+
+	networkDeclared := false
+	for _, permission := range permissions {
+		if permission == NetworkPermission || strings.HasPrefix(permission, NetworkPermission+":") {
+			networkDeclared = true
+		}
+	}
+	if len(audit.DynamicRequests) > 0 {
+		if !networkDeclared {
+			audit.Missing = append(audit.Missing, audit.DynamicRequests[0])
+		}
+		// The requests may go to any declared host
+		for _, permission := range permissions {
+			if permission == NetworkPermission || strings.HasPrefix(permission, NetworkPermission+":") {
+				used[permission] = true
+			}
+		}
+	}
+
+	for _, permission := range permissions {
+		if !used[permission] {
+			audit.Unused = append(audit.Unused, permission)
+		}
+	}
+
+	if containsString(permissions, NetworkPermission) && len(audit.DynamicRequests) == 0 && len(hosts) > 0 {
+		sort.Strings(hosts)
+		for _, host := range hosts {
+			audit.Narrower = append(audit.Narrower, NetworkPermission+":"+host)
+		}
+	}
+}
+
+func containsUsage(usages []PermissionUsage, usage PermissionUsage) bool {
+	for _, u := range usages {
+		if u == usage {
+			return true
+		}
+	}
+	return false
+}
+
+// coveringPermissions returns the declared permissions allowing a usage.
+func coveringPermissions(permissions []string, usage PermissionUsage) []string {
+	covering := []string{}
+	for _, permission := range permissions {
+		if usage.Permission != NetworkPermission {
+			if permission == usage.Permission {
+				covering = append(covering, permission)
+			}
+			continue
+		}
+		if permission == NetworkPermission || (strings.HasPrefix(permission, NetworkPermission+":") &&
+			hostMatches(strings.TrimPrefix(permission, NetworkPermission+":"), usage.Host)) {
+			covering = append(covering, permission)
+		}
+	}
+	return covering
+}
+
+// hostMatches reports whether host is allowed by pattern, a host or
+// *.domain for its subdomains.
+func hostMatches(pattern, host string) bool {
+	pattern, host = strings.ToLower(pattern), strings.ToLower(host)
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return pattern == host
+}
+
+// Err returns an error when the code needs undeclared permissions or
+// providers.
+func (audit *PermissionAudit) Err() error {
+	problems := []string{}
+	if len(audit.Missing) > 0 {
+		problems = append(problems, fmt.Sprintf("%d undeclared permissions", len(audit.Missing)))
+	}
+	if len(audit.UndeclaredProviders) > 0 {
+		problems = append(problems, fmt.Sprintf("%d undeclared providers", len(audit.UndeclaredProviders)))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the module code uses %s", strings.Join(problems, " and "))
+}
+
+// Print reports the audit.
+func (audit *PermissionAudit) Print() {
+	for _, usage := range audit.Missing {
+		if usage.Permission == NetworkPermission && usage.Host != "" {
+			fmt.Printf("❌ Missing permission %s:%s (or %s): %s\n", NetworkPermission, usage.Host, NetworkPermission, usage)
+		} else {
+			fmt.Printf("❌ Missing permission %s: %s\n", usage.Permission, usage)
+		}
+	}
+	for _, call := range audit.UndeclaredProviders {
+		fmt.Printf("❌ Provider '%s' is called but not declared in the module providers: %s\n", call.Host, call)
+	}
+
+	for _, permission := range audit.Unused {
+		fmt.Printf("⚠️  Warning: permission '%s' is declared but the code never needs it\n", permission)
+	}
+	for _, provider := range audit.UnusedProviders {
+		fmt.Printf("⚠️  Warning: provider '%s' is declared but never called\n", provider)
+	}
+	if len(audit.DynamicRequests) > 0 && !containsUsage(audit.Missing, audit.DynamicRequests[0]) {
+		fmt.Printf("⚠️  Warning: %d requests go to URLs computed at runtime, check that the declared hosts cover them:\n", len(audit.DynamicRequests))
+		for _, usage := range audit.DynamicRequests {
+			fmt.Printf("   - %s\n", usage)
+		}
+	}
+	if len(audit.Narrower) > 0 {
+		fmt.Printf("💡 %s allows any host, the code only calls %d: declare %s instead\n",
+			NetworkPermission, len(audit.Narrower), strings.Join(audit.Narrower, ", "))
+	}
+
+	if len(audit.Missing) == 0 && len(audit.UndeclaredProviders) == 0 && len(audit.Unused) == 0 && len(audit.UnusedProviders) == 0 {
+		fmt.Println("✅ Declared permissions match the code")
+	}
+}
+
+// auditPermissions reports the permissions and providers the code needs but
+// does not declare, and the declared ones it does not need.
+func auditPermissions(permissions []string, providers []map[string]interface{}, bundle string) error {
+	fmt.Println("🔍 Auditing permissions against the code...")
+
+	names := []string{}
+	for _, provider := range providers {
+		if name, ok := provider["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+
+	audit, err := NewPermissionAuditor(bundle).Audit(permissions, names)
+	if err != nil {
+		return err
+	}
+	audit.Print()
+	return audit.Err()
+}
+
+// scanPermissionUsages finds the API calls and requests of a source whose
+// comments are blanked.
+func scanPermissionUsages(source, file string) []PermissionUsage {
+	usages := []PermissionUsage{}
+	for _, rule := range permissionRules {
+		for _, loc := range rule.pattern.FindAllStringIndex(source, -1) {
+			usages = append(usages, PermissionUsage{
+				Permission: rule.permission,
+				Call:       strings.TrimSpace(strings.TrimSuffix(source[loc[0]:loc[1]], "(")) + "()",
+				Location:   sourceLocation(source, file, loc[0]),
+			})
+		}
+	}
+
+	constants := map[string]string{}
+	for _, match := range urlConstantRegex.FindAllStringSubmatch(source, -1) {
+		constants[match[1]] = match[3]
+	}
+
+	for _, requestRegex := range requestRegexes {
+		for _, loc := range requestRegex.FindAllStringIndex(source, -1) {
+			call := strings.TrimSpace(source[loc[0]:loc[1]])
+			if i := strings.IndexAny(call, "(,"); i >= 0 {
+				call = strings.TrimSpace(call[:i])
+			}
+			call = strings.TrimPrefix(call, ".") + "()"
+
+			host, external := requestHost(source[loc[1]:], constants)
+			if !external {
+				continue // same origin
+			}
+			usages = append(usages, PermissionUsage{
+				Permission: NetworkPermission,
+				Host:       host,
+				Call:       call,
+				Location:   sourceLocation(source, file, loc[0]),
+			})
+		}
+	}
+	return usages
+}
+
+// requestHost reads the URL argument starting args. It returns the host of
+// an absolute URL, "" when the URL is computed, and external false for
+// relative URLs.
+func requestHost(args string, constants map[string]string) (string, bool) {
+	args = strings.TrimLeft(args, " \t\r\n")
+	if args == "" {
+		return "", true
+	}
+
+	if quote := args[0]; quote == '\'' || quote == '"' || quote == '`' {
+		literal := args[1:]
+		if end := strings.IndexByte(literal, quote); end >= 0 {
+			literal = literal[:end]
+		}
+		if match := urlRegex.FindStringSubmatch(literal); match != nil {
+			return strings.ToLower(match[1]), true
+		}
+		if absoluteURLRegex.MatchString(literal) {
+			return "", true // the host is computed
+		}
+		if quote == '`' && strings.HasPrefix(literal, "${") {
+			return constantHost(literal, constants), true
+		}
+		return "", false
+	}
+
+	argument := args
+	if end := strings.IndexAny(args, ",)"); end >= 0 {
+		argument = args[:end]
+	}
+	return constantHost(argument, constants), true
+}
+
+// constantHost returns the host of the first URL constant an expression
+// uses, or "".
+func constantHost(expression string, constants map[string]string) string {
+	for _, name := range identifierRegex.FindAllString(expression, -1) {
+		if host, ok := constants[name]; ok {
+			return strings.ToLower(host)
+		}
+	}
+	return ""
+}
+
+// scanProviderCalls finds callProvider, useProvider and providers.call
+// calls. Host holds the provider name.
+func scanProviderCalls(source, file string) []PermissionUsage {
+	calls := []PermissionUsage{}
+	for _, match := range providerCallRegex.FindAllStringSubmatchIndex(source, -1) {
+		name := ""
+		if match[2] >= 0 {
+			name = source[match[2]:match[3]]
+		} else {
+			name = source[match[4]:match[5]]
+		}
+		call := source[match[0]:match[1]]
+		calls = append(calls, PermissionUsage{
+			Host:     strings.ToLower(name),
+			Call:     strings.TrimSpace(call[:strings.IndexByte(call, '(')]) + "()",
+			Location: sourceLocation(source, file, match[0]),
+		})
+	}
+	return calls
+}
+
+func sourceLocation(source, file string, offset int) string {
+	return fmt.Sprintf("%s:%d", file, strings.Count(source[:offset], "\n")+1)
+}
+
+// validPermission reports whether permission is known, checking the host
+// of network:external:<host>.
+func validPermission(permission string) bool {
+	switch permission {
+	case "storage:read", "storage:write", "ui:notifications", "ui:modals", NetworkPermission:
+		return true
+	}
+	host := strings.TrimPrefix(permission, NetworkPermission+":")
+	return host != permission && permissionHostRegex.MatchString(host)
+}
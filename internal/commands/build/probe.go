@@ -0,0 +1,255 @@
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// probeMarker starts the declarations appended to an interface probe.
+const probeMarker = "// --- dashspace interface probe (generated by dashspace build, do not edit) ---"
+
+// probeCall is inserted right after the handlers declaration, in its scope.
+const probeCall = "; __dashspaceCheckHandlers(handlers);"
+
+// probeRoot mirrors the project layout in the cache directory: the probe of
+// src/Component.tsx is probeRoot/src/Component.tsx. With the project and
+// probeRoot as rootDirs, its relative imports resolve to the project files.
+const probeRoot = buildCacheDir + "/interface-check"
+
+// interfaceProbe is a shadow copy of Component.tsx that type-checks the
+// handlers object against the interfaces declared in Module.ts. It is written
+// under probeRoot, out of the project's sources, and keeps the component's
+// line numbers so diagnostics map back to it.
+type interfaceProbe struct {
+	componentFile string
+	path          string
+	source        string
+	handlers      *handlersObject
+	interfaces    []string
+
+	callLine    int // 1-based line of the inserted call
+	callColumn  int // 0-based UTF-16 column of the inserted call
+	sourceLines int
+}
+
+func newInterfaceProbe(componentFile, source string, interfaces []string) (*interfaceProbe, error) {
+	handlers, ok := findHandlersObject(blankComments(source))
+	if !ok {
+		return nil, fmt.Errorf("handlers object not found in %s", componentFile)
+	}
+
+	line, column := position(source, handlers.end)
+	return &interfaceProbe{
+		componentFile: componentFile,
+		path:          filepath.Join(filepath.FromSlash(probeRoot), componentFile),
+		source:        source,
+		handlers:      handlers,
+		interfaces:    interfaces,
+		callLine:      line,
+		callColumn:    column - 1,
+		sourceLines:   strings.Count(source, "\n") + 1,
+	}, nil
+}
+
+// content returns the probe source: the component with the check call, then
+// the declarations requiring every declared interface with the signatures
+// of dashspace-lib.
+func (p *interfaceProbe) content() string {
+	var b strings.Builder
+	b.WriteString(p.source[:p.handlers.end])
+	b.WriteString(probeCall)
+	b.WriteString(p.source[p.handlers.end:])
+
+	b.WriteString("\n\n" + probeMarker + "\n")
+
+	imports := make([]string, len(p.interfaces))
+	for i, name := range p.interfaces {
+		imports[i] = fmt.Sprintf("%s as __Dashspace_%s", name, name)
+	}
+	fmt.Fprintf(&b, "import type { %s } from 'dashspace-lib';\n\n", strings.Join(imports, ", "))
+
+	b.WriteString("interface __DashspaceDeclaredInterfaces {\n")
+	for _, name := range p.interfaces {
+		fmt.Fprintf(&b, "    %s: __Dashspace_%s;\n", name, name)
+	}
+	b.WriteString("}\n\n")
+
+	// A missing or mismatched interface resolves to the lib's type, so the
+	// error names the interface and the method at fault
+	b.WriteString(`type __DashspaceImplemented<H> = {
+    [K in keyof __DashspaceDeclaredInterfaces]: K extends keyof H
+        ? H[K] extends __DashspaceDeclaredInterfaces[K]
+            ? H[K]
+            : __DashspaceDeclaredInterfaces[K]
+        : __DashspaceDeclaredInterfaces[K];
+};
+
+declare function __dashspaceCheckHandlers<H>(handlers: H & __DashspaceImplemented<H>): void;
+`)
+
+	return b.String()
+}
+
+// owns reports whether a diagnostic comes from the probe's own code rather
+// than from the copied component, which tsc already checked.
+func (p *interfaceProbe) owns(d tsDiagnostic) bool {
+	if filepath.Clean(d.File) != filepath.Clean(p.path) {
+		return false
+	}
+	if d.Line > p.sourceLines {
+		return true
+	}
+	callLength := len(utf16.Encode([]rune(probeCall)))
+	return d.Line == p.callLine && d.Column-1 >= p.callColumn && d.Column-1 < p.callColumn+callLength
+}
+
+var (
+	probePropertyRegex  = regexp.MustCompile(`(?i)property '([\w$]+)'`)
+	probeNoExportRegex  = regexp.MustCompile(`has no exported member(?: named)? '([\w$]+)'`)
+	probeInterfaceRegex = regexp.MustCompile(`__Dashspace_([\w$]+)`)
+)
+
+// locate maps a probe diagnostic to the handler it is about: the method when
+// the message names one, else the interface entry, else the handlers object.
+func (p *interfaceProbe) locate(d tsDiagnostic) tsDiagnostic {
+	located := tsDiagnostic{File: p.componentFile, Code: d.Code, Severity: d.Severity}
+
+	message := probeInterfaceRegex.ReplaceAllString(d.Message, "$1")
+	if m := probeNoExportRegex.FindStringSubmatch(message); m != nil {
+		message = fmt.Sprintf("dashspace-lib does not export interface %s", m[1])
+	}
+	located.Message = message
+
+	iface, method := "", ""
+	for _, m := range probePropertyRegex.FindAllStringSubmatch(message, -1) {
+		switch {
+		case iface == "" && containsString(p.interfaces, m[1]):
+			iface = m[1]
+		case iface != "" && method == "":
+			method = m[1]
+		}
+	}
+	if iface == "" {
+		if m := probeNoExportRegex.FindStringSubmatch(d.Message); m != nil {
+			iface = m[1]
+		}
+	}
+
+	offset := p.handlers.open
+	for _, member := range p.handlers.members {
+		if member.Name != iface {
+			continue
+		}
+		offset = member.Offset
+		if open := objectLiteralAt(blankComments(p.source), member.Value); open >= 0 && method != "" {
+			for _, m := range objectMembers(blankComments(p.source), open) {
+				if m.Name == method {
+					offset = m.Offset
+				}
+			}
+		}
+	}
+
+	located.Line, located.Column = position(p.source, offset)
+	located.Subject = iface
+	if iface != "" && method != "" {
+		located.Subject = iface + "." + method
+	}
+	return located
+}
+
+// probeDiagnostics keeps the diagnostics of the probe's own code, mapped
+// back to the component, in source order.
+func (p *interfaceProbe) diagnostics(all []tsDiagnostic) []tsDiagnostic {
+	mapped := []tsDiagnostic{}
+	for _, d := range all {
+		if p.owns(d) {
+			mapped = append(mapped, p.locate(d))
+		}
+	}
+	sort.SliceStable(mapped, func(i, j int) bool {
+		if mapped[i].Line != mapped[j].Line {
+			return mapped[i].Line < mapped[j].Line
+		}
+		return mapped[i].Column < mapped[j].Column
+	})
+	return mapped
+}
+
+// tsDiagnostic is one diagnostic of `tsc --pretty false`.
+type tsDiagnostic struct {
+	File     string
+	Line     int // 1-based
+	Column   int // 1-based, in UTF-16 units like tsc
+	Severity string
+	Code     int
+	Message  string // first line, then the indented elaboration chain
+	Subject  string // the interface or method a probe diagnostic is about
+}
+
+func (d tsDiagnostic) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+	}
+	if location != "" {
+		location += " - "
+	}
+	subject := ""
+	if d.Subject != "" {
+		subject = d.Subject + ": "
+	}
+	return fmt.Sprintf("%s%sTS%d: %s", location, subject, d.Code, strings.ReplaceAll(d.Message, "\n", "\n     "))
+}
+
+var tsDiagnosticRegex = regexp.MustCompile(`^(.+?)\((\d+),(\d+)\): (error|warning|message) TS(\d+): (.*)$`)
+
+// tsGlobalDiagnosticRegex matches diagnostics without a location, such as
+// config errors.
+var tsGlobalDiagnosticRegex = regexp.MustCompile(`^(error|warning|message) TS(\d+): (.*)$`)
+
+// parseTSDiagnostics parses `tsc --pretty false` output, with or without
+// --build. Indented lines continue the message of the diagnostic before
+// them.
+func parseTSDiagnostics(output string) []tsDiagnostic {
+	diagnostics := []tsDiagnostic{}
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		if m := tsDiagnosticRegex.FindStringSubmatch(line); m != nil {
+			lineNumber, _ := strconv.Atoi(m[2])
+			column, _ := strconv.Atoi(m[3])
+			code, _ := strconv.Atoi(m[5])
+			diagnostics = append(diagnostics, tsDiagnostic{
+				File:     m[1],
+				Line:     lineNumber,
+				Column:   column,
+				Severity: m[4],
+				Code:     code,
+				Message:  m[6],
+			})
+			continue
+		}
+		if m := tsGlobalDiagnosticRegex.FindStringSubmatch(line); m != nil {
+			code, _ := strconv.Atoi(m[2])
+			diagnostics = append(diagnostics, tsDiagnostic{Severity: m[1], Code: code, Message: m[3]})
+			continue
+		}
+		if len(diagnostics) > 0 && strings.TrimSpace(line) != "" && (line[0] == ' ' || line[0] == '\t') {
+			last := &diagnostics[len(diagnostics)-1]
+			last.Message += "\n" + strings.TrimSpace(line)
+		}
+	}
+	return diagnostics
+}
+
+// position converts a byte offset to a 1-based line and UTF-16 column.
+func position(source string, offset int) (int, int) {
+	before := source[:offset]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return line, len(utf16.Encode([]rune(before[lineStart:]))) + 1
+}
@@ -0,0 +1,501 @@
+package build
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/devlyspace/dashspace-cli/internal/utils"
+)
+
+// providersFS holds the scope catalog of each provider, one <provider>.json
+// per provider.
+//
+//go:embed providers
+var providersFS embed.FS
+
+// ProviderCatalog lists the OAuth scopes of a provider and the scopes its
+// API endpoints need.
+type ProviderCatalog struct {
+	Provider string `json:"provider"`
+	// Scopes maps each scope to a description
+	Scopes map[string]string `json:"scopes"`
+	// Implies lists the narrower scopes a scope grants
+	Implies map[string][]string `json:"implies,omitempty"`
+	// BasePaths are prefixes the endpoints may be called with
+	BasePaths []string           `json:"basePaths,omitempty"`
+	Endpoints []ProviderEndpoint `json:"endpoints"`
+}
+
+// ProviderEndpoint is an API endpoint of a provider. Any of its scopes, or
+// a scope implying one, allows calling it; they are listed narrowest first
+// and the endpoint needs none when there are none. Path segments in braces
+// match any segment and a final "*" the rest of the path. An empty method
+// matches every method.
+type ProviderEndpoint struct {
+	Method string   `json:"method,omitempty"`
+	Path   string   `json:"path"`
+	Scopes []string `json:"scopes"`
+}
+
+// CatalogProviders returns the providers that have a bundled scope catalog.
+func CatalogProviders() []string {
+	entries, err := fs.ReadDir(providersFS, "providers")
+	if err != nil {
+		return nil
+	}
+
+	providers := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			providers = append(providers, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// LoadProviderCatalog returns the bundled scope catalog of a provider.
+func LoadProviderCatalog(provider string) (*ProviderCatalog, error) {
+	data, err := providersFS.ReadFile(path.Join("providers", strings.ToLower(provider)+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("no scope catalog bundled for provider '%s' (available: %s)", provider, strings.Join(CatalogProviders(), ", "))
+	}
+
+	catalog := &ProviderCatalog{}
+	if err := json.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("invalid %s scope catalog: %w", provider, err)
+	}
+	return catalog, nil
+}
+
+// ScopeNames returns the scopes of the provider, sorted.
+func (c *ProviderCatalog) ScopeNames() []string {
+	names := make([]string, 0, len(c.Scopes))
+	for name := range c.Scopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Grants returns scope and the scopes it implies.
+func (c *ProviderCatalog) Grants(scope string) []string {
+	granted := []string{scope}
+	for i := 0; i < len(granted); i++ {
+		for _, implied := range c.Implies[granted[i]] {
+			granted = appendUnique(granted, implied)
+		}
+	}
+	return granted
+}
+
+// Allows reports whether any of scopes allows calling endpoint.
+func (c *ProviderCatalog) Allows(scopes []string, endpoint *ProviderEndpoint) bool {
+	if len(endpoint.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		for _, granted := range c.Grants(scope) {
+			if containsString(endpoint.Scopes, granted) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Suggest returns the scope closest to an unknown scope, or "" when none is
+// close enough to be a typo.
+func (c *ProviderCatalog) Suggest(scope string) string {
+	best, bestDistance := "", 3
+	for _, name := range c.ScopeNames() {
+		if d := utils.EditDistance(strings.ToLower(scope), strings.ToLower(name)); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// Endpoint returns the catalog endpoint matching a call, preferring the one
+// with the most literal segments, or nil.
+func (c *ProviderCatalog) Endpoint(method, endpoint string) *ProviderEndpoint {
+	candidates := [][]string{pathSegments(endpoint)}
+	for _, base := range c.BasePaths {
+		if rest, ok := trimSegments(candidates[0], pathSegments(base)); ok {
+			candidates = append(candidates, rest)
+		}
+	}
+
+	var best *ProviderEndpoint
+	bestScore := -1
+	for i := range c.Endpoints {
+		e := &c.Endpoints[i]
+		if e.Method != "" && !strings.EqualFold(e.Method, method) {
+			continue
+		}
+		pattern := pathSegments(e.Path)
+		for _, segments := range candidates {
+			if score, ok := matchSegments(pattern, segments); ok && score > bestScore {
+				best, bestScore = e, score
+			}
+		}
+	}
+	return best
+}
+
+func pathSegments(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return []string{}
+	}
+	return strings.Split(p, "/")
+}
+
+// matchSegments matches the segments of a call against a catalog path and
+// returns the number of literal segments matched.
+func matchSegments(pattern, segments []string) (int, bool) {
+	score := 0
+	for i, want := range pattern {
+		if want == "*" && i == len(pattern)-1 {
+			return score, len(segments) > i
+		}
+		if i >= len(segments) {
+			return 0, false
+		}
+		switch {
+		case strings.HasPrefix(want, "{") && strings.HasSuffix(want, "}"):
+		case want == segments[i]:
+			score++
+		default:
+			return 0, false
+		}
+	}
+	return score, len(segments) == len(pattern)
+}
+
+// trimSegments removes a base path matched with matchSegments.
+func trimSegments(segments, base []string) ([]string, bool) {
+	if len(segments) <= len(base) {
+		return nil, false
+	}
+	if _, ok := matchSegments(base, segments[:len(base)]); !ok {
+		return nil, false
+	}
+	return segments[len(base):], true
+}
+
+var (
+	providerVariableRegex = regexp.MustCompile(`\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*useProvider\s*\(\s*(?:Provider\s*\.\s*(\w+)|['"]([\w-]+)['"])\s*\)`)
+	templateExprRegex     = regexp.MustCompile(`\$\{[^}]*\}`)
+)
+
+// ProviderCall is a call of a provider API in the module sources.
+type ProviderCall struct {
+	Provider string
+	Method   string
+	// Endpoint is the called path, with "{}" for computed segments, or ""
+	// when the endpoint is computed
+	Endpoint string
+	Call     string
+	Location string
+}
+
+func (c ProviderCall) String() string {
+	return fmt.Sprintf("%s at %s", c.Call, c.Location)
+}
+
+// scanProviderEndpoints finds the endpoints a source calls with
+// callProvider(Provider.X, options), providers.call and the call method of
+// a variable holding useProvider(Provider.X). Other uses of useProvider are
+// returned without an endpoint.
+func scanProviderEndpoints(source, file string) []ProviderCall {
+	calls := []ProviderCall{}
+	assigned := map[int]bool{}
+
+	for _, match := range providerVariableRegex.FindAllStringSubmatchIndex(source, -1) {
+		variable := source[match[2]:match[3]]
+		provider := submatch(source, match, 2)
+		assigned[strings.Index(source[match[0]:match[1]], "useProvider")+match[0]] = true
+
+		callRegex := regexp.MustCompile(`(?:^|[^\w$.])` + regexp.QuoteMeta(variable) + `\s*\.\s*call\s*\(`)
+		for _, loc := range callRegex.FindAllStringIndex(source, -1) {
+			method, endpoint := providerCallEndpoint(callArguments(source, loc[1]-1))
+			calls = append(calls, ProviderCall{
+				Provider: provider,
+				Method:   method,
+				Endpoint: endpoint,
+				Call:     variable + ".call()",
+				Location: sourceLocation(source, file, loc[1]-1),
+			})
+		}
+	}
+
+	for _, match := range providerCallRegex.FindAllStringSubmatchIndex(source, -1) {
+		call := source[match[0]:match[1]]
+		open := match[0] + strings.IndexByte(call, '(')
+		name := strings.TrimSpace(call[:strings.IndexByte(call, '(')])
+
+		method, endpoint := "GET", ""
+		if strings.HasPrefix(name, "useProvider") {
+			if assigned[match[0]] {
+				continue
+			}
+		} else if args := callArguments(source, open); len(args) > 1 {
+			method, endpoint = providerCallEndpoint(args[1:])
+		}
+		calls = append(calls, ProviderCall{
+			Provider: submatch(source, match, 1),
+			Method:   method,
+			Endpoint: endpoint,
+			Call:     name + "()",
+			Location: sourceLocation(source, file, match[0]),
+		})
+	}
+	return calls
+}
+
+// submatch returns the provider name captured by a Provider.X or quoted
+// name alternative starting at group.
+func submatch(source string, match []int, group int) string {
+	if match[2*group] >= 0 {
+		return strings.ToLower(source[match[2*group]:match[2*group+1]])
+	}
+	return strings.ToLower(source[match[2*group+2]:match[2*group+3]])
+}
+
+// callArguments splits the arguments of the call whose parenthesis opens
+// at open.
+func callArguments(source string, open int) []string {
+	end := matchingBracket(source, open, '(', ')')
+	if end < 0 {
+		return nil
+	}
+	return splitArguments(source[open+1 : end])
+}
+
+// providerCallEndpoint reads the method and endpoint of a provider call
+// from its arguments, an endpoint string or an options object with an
+// endpoint, path or url and a method, optionally followed by options.
+func providerCallEndpoint(args []string) (string, string) {
+	method, endpoint := "GET", ""
+	for i, arg := range args {
+		body := objectBody(arg)
+		if body == "" {
+			if i == 0 {
+				endpoint = endpointLiteral(arg)
+			}
+			continue
+		}
+		for _, property := range splitArguments(body) {
+			key, value, ok := strings.Cut(property, ":")
+			if !ok {
+				continue
+			}
+			switch strings.Trim(strings.TrimSpace(key), `'"`) {
+			case "endpoint", "path", "url":
+				endpoint = endpointLiteral(value)
+			case "method":
+				if m, ok := stringLiteral(value); ok && m != "" {
+					method = strings.ToUpper(m)
+				}
+			}
+		}
+	}
+	return method, endpoint
+}
+
+// endpointLiteral returns the path of a string or template literal, with
+// "{}" for template expressions, or "" when the value is computed.
+func endpointLiteral(value string) string {
+	literal, ok := stringLiteral(value)
+	if !ok {
+		return ""
+	}
+
+	if match := urlRegex.FindString(literal); match != "" {
+		literal = literal[len(match):]
+	} else if strings.HasPrefix(literal, "{}/") {
+		literal = literal[2:] // a base URL
+	}
+	if i := strings.IndexAny(literal, "?#"); i >= 0 {
+		literal = literal[:i]
+	}
+	if literal == "" || literal == "{}" {
+		return ""
+	}
+	return "/" + strings.Trim(literal, "/")
+}
+
+// stringLiteral returns the content of a string or template literal, with
+// "{}" for template expressions.
+func stringLiteral(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || strings.IndexByte(`'"`+"`", value[0]) < 0 || value[len(value)-1] != value[0] {
+		return "", false
+	}
+	literal := value[1 : len(value)-1]
+	if value[0] == '`' {
+		literal = templateExprRegex.ReplaceAllString(literal, "{}")
+	}
+	return literal, true
+}
+
+// ScopeReplacement suggests narrower scopes for a requested scope.
+type ScopeReplacement struct {
+	Scope    string
+	Narrower []string
+}
+
+// ScopeAudit compares the scopes requested for a provider with its catalog
+// and the endpoints the code calls.
+type ScopeAudit struct {
+	Provider string
+	// Unknown are requested scopes missing from the catalog
+	Unknown []string
+	// Missing are calls no requested scope allows
+	Missing []ProviderCall
+	// Narrower are requested scopes broader than the calls need
+	Narrower []ScopeReplacement
+
+	catalog *ProviderCatalog
+}
+
+// AuditScopes checks the requested scopes against the catalog and the calls
+// to the provider. Narrower scopes are only suggested when every call has
+// an endpoint found in the catalog.
+func (c *ProviderCatalog) AuditScopes(requested []string, calls []ProviderCall) *ScopeAudit {
+	audit := &ScopeAudit{Provider: c.Provider, catalog: c}
+	for _, scope := range requested {
+		if _, ok := c.Scopes[scope]; !ok {
+			audit.Unknown = append(audit.Unknown, scope)
+		}
+	}
+
+	endpoints := []*ProviderEndpoint{}
+	resolved := len(calls) > 0
+	for _, call := range calls {
+		endpoint := (*ProviderEndpoint)(nil)
+		if call.Endpoint != "" {
+			endpoint = c.Endpoint(call.Method, call.Endpoint)
+		}
+		if endpoint == nil {
+			resolved = false
+			continue
+		}
+		endpoints = append(endpoints, endpoint)
+		if !c.Allows(requested, endpoint) {
+			audit.Missing = append(audit.Missing, call)
+		}
+	}
+	if !resolved {
+		return audit
+	}
+
+	for _, scope := range requested {
+		if len(c.Implies[scope]) == 0 {
+			continue
+		}
+		others := []string{}
+		for _, other := range requested {
+			if other != scope {
+				others = append(others, other)
+			}
+		}
+
+		granted := c.Grants(scope)
+		narrower := []string{}
+		for _, endpoint := range endpoints {
+			if !c.Allows([]string{scope}, endpoint) || c.Allows(others, endpoint) {
+				continue
+			}
+			for _, candidate := range endpoint.Scopes {
+				if containsString(granted, candidate) {
+					narrower = appendUnique(narrower, candidate)
+					break
+				}
+			}
+		}
+		if len(narrower) > 0 && !containsString(narrower, scope) {
+			audit.Narrower = append(audit.Narrower, ScopeReplacement{Scope: scope, Narrower: narrower})
+		}
+	}
+	return audit
+}
+
+// Print reports the audit.
+func (audit *ScopeAudit) Print() {
+	for _, scope := range audit.Unknown {
+		if suggestion := audit.catalog.Suggest(scope); suggestion != "" {
+			fmt.Printf("❌ Unknown %s scope '%s', did you mean '%s'?\n", audit.Provider, scope, suggestion)
+		} else {
+			fmt.Printf("❌ Unknown %s scope '%s' (known scopes: %s)\n", audit.Provider, scope, strings.Join(audit.catalog.ScopeNames(), ", "))
+		}
+	}
+	for _, call := range audit.Missing {
+		needed := audit.catalog.Endpoint(call.Method, call.Endpoint).Scopes
+		scopes := "the " + needed[0] + " scope"
+		if len(needed) > 1 {
+			scopes = "one of the scopes " + strings.Join(needed, ", ")
+		}
+		fmt.Printf("⚠️  Warning: %s %s %s needs %s, which the module does not request: %s\n",
+			audit.Provider, call.Method, call.Endpoint, scopes, call)
+	}
+	for _, replacement := range audit.Narrower {
+		fmt.Printf("💡 %s scope '%s' is broader than the endpoints the code calls need: request %s instead\n",
+			audit.Provider, replacement.Scope, strings.Join(replacement.Narrower, ", "))
+	}
+}
+
+// auditProviderScopes checks the scopes of the declared providers against
+// their catalog and the endpoints the module sources call.
+func auditProviderScopes(providers []map[string]interface{}) error {
+	if len(providers) == 0 {
+		return nil
+	}
+	fmt.Println("🔍 Checking provider scopes...")
+
+	calls := map[string][]ProviderCall{}
+	for _, file := range auditedSourceFiles() {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		for _, call := range scanProviderEndpoints(blankComments(string(content)), file) {
+			calls[call.Provider] = append(calls[call.Provider], call)
+		}
+	}
+
+	unknown, reported := 0, false
+	for _, provider := range providers {
+		name, _ := provider["name"].(string)
+		scopes, _ := provider["scopes"].([]string)
+
+		catalog, err := LoadProviderCatalog(name)
+		if err != nil {
+			if len(scopes) > 0 {
+				fmt.Printf("ℹ️  No scope catalog for %s, its scopes are not checked\n", name)
+			}
+			continue
+		}
+
+		audit := catalog.AuditScopes(scopes, calls[name])
+		audit.Print()
+		unknown += len(audit.Unknown)
+		reported = reported || len(audit.Unknown)+len(audit.Missing)+len(audit.Narrower) > 0
+	}
+
+	if unknown > 0 {
+		return fmt.Errorf("the module requests %d unknown provider scopes", unknown)
+	}
+	if !reported {
+		fmt.Println("✅ Provider scopes match the endpoints the code calls")
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package build
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// buildCacheDir holds the build caches of a module, such as the tsBuildInfo of
+// incremental type checks. Module templates ignore it in .gitignore.
+const buildCacheDir = ".dashspace/cache"
+
+// defaultTsConfig are the compiler options of the tsconfig.json created by
+// --init, also used to type-check projects without one.
+var defaultTsConfig = map[string]interface{}{
+	"target":                           "ES2020",
+	"module":                           "ESNext",
+	"lib":                              []string{"ES2020", "DOM", "DOM.Iterable"},
+	"jsx":                              "react",
+	"strict":                           true,
+	"esModuleInterop":                  true,
+	"skipLibCheck":                     true,
+	"forceConsistentCasingInFileNames": true,
+	"moduleResolution":                 "node",
+	"resolveJsonModule":                true,
+	"noEmit":                           true,
+	"types":                            []string{"react", "react-dom", "node"},
+}
+
+// tsConfig is the part of a tsconfig.json the type check reads.
+type tsConfig struct {
+	// Extends is a path or, since TypeScript 5.0, a list of paths
+	Extends         interface{}            `json:"extends,omitempty"`
+	CompilerOptions map[string]interface{} `json:"compilerOptions,omitempty"`
+	Files           []string               `json:"files,omitempty"`
+	Include         []string               `json:"include,omitempty"`
+	Exclude         []string               `json:"exclude,omitempty"`
+	References      []struct {
+		Path string `json:"path"`
+	} `json:"references,omitempty"`
+}
+
+var trailingCommaRegex = regexp.MustCompile(`,(\s*[}\]])`)
+
+// readTsConfig reads a tsconfig.json, which may hold comments and trailing
+// commas, and merges the compiler options of the configs it extends. Files,
+// include and exclude missing from it are inherited, relative to its
+// directory. References are not inherited, like in tsc.
+func readTsConfig(path string) (*tsConfig, error) {
+	return readTsConfigDepth(path, 0)
+}
+
+func readTsConfigDepth(path string, depth int) (*tsConfig, error) {
+	if depth > 10 {
+		return nil, fmt.Errorf("%s: too many nested extends", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tsConfig{}
+	content := trailingCommaRegex.ReplaceAllString(blankComments(string(data)), "$1")
+	if err := json.Unmarshal([]byte(content), config); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	extends := []string{}
+	switch value := config.Extends.(type) {
+	case string:
+		extends = append(extends, value)
+	case []interface{}:
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				extends = append(extends, s)
+			}
+		}
+	}
+
+	options := map[string]interface{}{}
+	inherited := tsConfig{}
+	for _, parent := range extends {
+		parentPath := resolveTsConfigExtends(filepath.Dir(path), parent)
+		if parentPath == "" {
+			continue // tsc reports the missing config
+		}
+		parentConfig, err := readTsConfigDepth(parentPath, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		dir, parentDir := filepath.Dir(path), filepath.Dir(parentPath)
+		for name, value := range parentConfig.CompilerOptions {
+			options[name] = rebasePathOption(name, value, parentDir, dir)
+		}
+
+		// The last config of the list wins, like for compiler options
+		if config.Files == nil && parentConfig.Files != nil {
+			inherited.Files = rebasePaths(parentConfig.Files, parentDir, dir)
+		}
+		if config.Include == nil && parentConfig.Include != nil {
+			inherited.Include = rebasePaths(parentConfig.Include, parentDir, dir)
+		}
+		if config.Exclude == nil && parentConfig.Exclude != nil {
+			inherited.Exclude = rebasePaths(parentConfig.Exclude, parentDir, dir)
+		}
+	}
+	if config.Files == nil {
+		config.Files = inherited.Files
+	}
+	if config.Include == nil {
+		config.Include = inherited.Include
+	}
+	if config.Exclude == nil {
+		config.Exclude = inherited.Exclude
+	}
+	for name, value := range config.CompilerOptions {
+		options[name] = value
+	}
+	config.CompilerOptions = options
+	return config, nil
+}
+
+// rebasePaths makes paths relative to the directory from relative to the
+// directory to instead. Absolute paths are kept.
+func rebasePaths(paths []string, from, to string) []string {
+	if paths == nil {
+		return nil
+	}
+	rebased := make([]string, len(paths))
+	for i, path := range paths {
+		rebased[i] = path
+		if filepath.IsAbs(path) {
+			continue
+		}
+		if rel, err := filepath.Rel(to, filepath.Join(from, path)); err == nil {
+			rebased[i] = filepath.ToSlash(rel)
+		}
+	}
+	return rebased
+}
+
+// tsPathOptions are the compiler options tsc resolves relative to the config
+// that sets them.
+var tsPathOptions = map[string]bool{
+	"baseUrl": true, "declarationDir": true, "outDir": true, "rootDir": true,
+	"rootDirs": true, "tsBuildInfoFile": true, "typeRoots": true,
+}
+
+// rebasePathOption rebases the value of a path option set in the directory
+// from onto the directory to. Other options are returned as is.
+func rebasePathOption(name string, value interface{}, from, to string) interface{} {
+	if !tsPathOptions[name] {
+		return value
+	}
+	switch value := value.(type) {
+	case string:
+		return rebasePaths([]string{value}, from, to)[0]
+	case []interface{}:
+		rebased := make([]interface{}, len(value))
+		for i, item := range value {
+			if path, ok := item.(string); ok {
+				rebased[i] = rebasePaths([]string{path}, from, to)[0]
+			} else {
+				rebased[i] = item
+			}
+		}
+		return rebased
+	}
+	return value
+}
+
+// resolveTsConfigExtends returns the file of an extends entry, a relative
+// path or a package in node_modules, or "" when it does not exist.
+func resolveTsConfigExtends(dir, extends string) string {
+	candidates := []string{}
+	if strings.HasPrefix(extends, ".") || filepath.IsAbs(extends) {
+		path := extends
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		candidates = append(candidates, path, path+".json")
+	} else {
+		for current := dir; ; current = filepath.Dir(current) {
+			path := filepath.Join(current, "node_modules", extends)
+			candidates = append(candidates, path, path+".json", filepath.Join(path, "tsconfig.json"))
+			if filepath.Dir(current) == current {
+				break
+			}
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// declinedConfigs are the config files the user chose not to create, so
+// watch mode does not ask again on every rebuild.
+var declinedConfigs = map[string]bool{}
+
+// shouldCreateConfig reports whether a missing config file may be created:
+// with --init, or when the user agrees in a terminal. Config files are never
+// written otherwise.
+func shouldCreateConfig(name string, init bool) bool {
+	if init {
+		return true
+	}
+	if declinedConfigs[name] || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return false
+	}
+
+	fmt.Printf("📝 No %s found. Create one with the defaults? [y/N] ", name)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "y" || answer == "yes" {
+		return true
+	}
+	declinedConfigs[name] = true
+	return false
+}
+
+func writeJSONConfig(path string, config interface{}) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// resolveTsConfig returns the tsconfig to type-check projectPath with,
+// relative to it. Without a tsconfig.json, one is created with --init or
+// when the user agrees; otherwise the defaults are written to the cache
+// directory, with paths pointing back at the project.
+func resolveTsConfig(projectPath string, init bool) (string, error) {
+	if fileExists(filepath.Join(projectPath, "tsconfig.json")) {
+		return "tsconfig.json", nil
+	}
+
+	if shouldCreateConfig("tsconfig.json", init) {
+		config := tsConfig{
+			CompilerOptions: defaultTsConfig,
+			Include:         []string{"**/*.ts", "**/*.tsx"},
+			Exclude:         []string{"node_modules", "dist", "build"},
+		}
+		if err := writeJSONConfig(filepath.Join(projectPath, "tsconfig.json"), config); err != nil {
+			return "", fmt.Errorf("failed to write tsconfig.json: %w", err)
+		}
+		fmt.Println("✅ Created tsconfig.json")
+		return "tsconfig.json", nil
+	}
+
+	dir, err := ensureCacheDir(projectPath)
+	if err != nil {
+		return "", err
+	}
+	// The cache is two levels below the project
+	config := tsConfig{
+		CompilerOptions: defaultTsConfig,
+		Include:         []string{"../../**/*.ts", "../../**/*.tsx"},
+		Exclude:         []string{"../../node_modules", "../../dist", "../../build", "../../.dashspace"},
+	}
+	path := filepath.Join(dir, "tsconfig.defaults.json")
+	if err := writeJSONConfig(path, config); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Println("ℹ️  No tsconfig.json, type-checking with the default options (run 'dashspace build --init' to create one)")
+	return filepath.Join(buildCacheDir, "tsconfig.defaults.json"), nil
+}
+
+// writeCheckTsConfig writes the tsconfig of the type check to the cache
+// directory and returns its path relative to projectPath. It extends the
+// project's tsconfig, so tsc applies its options and paths, and adds probe to
+// its files. Options tsc resolves relative to the config that sets them are
+// rebased onto the cache directory; rootDirs maps the probe's relative
+// imports to the project.
+func writeCheckTsConfig(projectPath, configPath string, config *tsConfig, probe string, reportUnused bool) (string, error) {
+	cacheDir, err := ensureCacheDir(projectPath)
+	if err != nil {
+		return "", err
+	}
+	if cacheDir, err = filepath.Abs(cacheDir); err != nil {
+		return "", err
+	}
+	projectDir, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", err
+	}
+	configDir := filepath.Dir(filepath.Join(projectDir, configPath))
+	rebase := func(paths ...string) []string { return rebasePaths(paths, configDir, cacheDir) }
+	// fromCache returns the path of a project file relative to the cache
+	fromCache := func(path string) string {
+		return rebasePaths([]string{path}, projectDir, cacheDir)[0]
+	}
+
+	options := map[string]interface{}{
+		"noEmit":          true,
+		"incremental":     true,
+		"tsBuildInfoFile": "./tsconfig.tsbuildinfo",
+		// tsc refuses composite projects that emit nothing
+		"composite": false,
+	}
+	if reportUnused {
+		options["noUnusedLocals"] = true
+		options["noUnusedParameters"] = true
+	}
+
+	check := tsConfig{
+		Extends:         fromCache(configPath),
+		CompilerOptions: options,
+		Files:           rebase(config.Files...),
+		Include:         rebase(config.Include...),
+		Exclude:         rebase(config.Exclude...),
+	}
+	// A path without ./ or ../ would name a package
+	if extends := check.Extends.(string); !strings.HasPrefix(extends, "../") {
+		check.Extends = "./" + extends
+	}
+	for _, reference := range config.References {
+		check.References = append(check.References, struct {
+			Path string `json:"path"`
+		}{rebase(reference.Path)[0]})
+	}
+
+	// Without files nor include, tsc checks every file of the config's
+	// directory; with the probe in files, it would check only the probe
+	if config.Files == nil && config.Include == nil {
+		check.Include = rebase("**/*")
+	}
+	// The default excludes are relative to the config that applies them
+	if config.Exclude == nil {
+		excludes := []string{"node_modules", "bower_components", "jspm_packages"}
+		if outDir, ok := config.CompilerOptions["outDir"].(string); ok {
+			excludes = append(excludes, outDir)
+		}
+		check.Exclude = rebase(excludes...)
+	}
+
+	if probe != "" {
+		rootDirs := []string{}
+		if existing, ok := config.CompilerOptions["rootDirs"].([]interface{}); ok {
+			for _, dir := range existing {
+				if dir, ok := dir.(string); ok {
+					rootDirs = append(rootDirs, rebase(dir)...)
+				}
+			}
+		}
+		options["rootDirs"] = append(rootDirs, fromCache("."), fromCache(probeRoot))
+
+		// tsc rejects files out of rootDir, even without emitting
+		if rootDir, ok := config.CompilerOptions["rootDir"].(string); ok {
+			if rel, err := filepath.Rel(projectDir, filepath.Join(configDir, rootDir)); err == nil && filepath.IsLocal(rel) {
+				options["rootDir"] = fromCache(".")
+			}
+		}
+
+		check.Files = append(check.Files, fromCache(probe))
+	}
+
+	path := filepath.Join(cacheDir, "tsconfig.check.json")
+	if err := writeJSONConfig(path, check); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return filepath.Join(buildCacheDir, "tsconfig.check.json"), nil
+}
+
+// ensureCacheDir creates the cache directory of projectPath.
+func ensureCacheDir(projectPath string) (string, error) {
+	dir := filepath.Join(projectPath, buildCacheDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return dir, nil
+}
@@ -0,0 +1,146 @@
+package build
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/devlyspace/dashspace-cli/test"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadTsConfigInheritsPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "configs", "base.json"), `{
+		// comments and trailing commas are allowed
+		"compilerOptions": { "strict": true, "outDir": "../dist", "rootDirs": ["../src", "../generated"], },
+		"include": ["../src"],
+		"exclude": ["../src/legacy"],
+	}`)
+	writeFile(t, filepath.Join(dir, "tsconfig.json"), `{
+		"extends": "./configs/base.json",
+		"compilerOptions": { "jsx": "react-jsx" },
+		"exclude": []
+	}`)
+
+	config, err := readTsConfig(filepath.Join(dir, "tsconfig.json"))
+	if err != nil {
+		t.Fatalf("readTsConfig: %v", err)
+	}
+	test.AssertEqual(t, config.CompilerOptions["strict"], true)
+	test.AssertEqual(t, config.CompilerOptions["jsx"], "react-jsx")
+	test.AssertEqual(t, config.CompilerOptions["outDir"], "dist")
+	test.AssertEqual(t, reflect.DeepEqual(config.CompilerOptions["rootDirs"], []interface{}{"src", "generated"}), true)
+	test.AssertEqual(t, reflect.DeepEqual(config.Include, []string{"src"}), true)
+	test.AssertEqual(t, len(config.Exclude), 0, "an empty exclude overrides the inherited one")
+	test.AssertEqual(t, config.Files == nil, true)
+}
+
+// readCheckTsConfig writes the check tsconfig of the project in dir and
+// reads it back as written.
+func readCheckTsConfig(t *testing.T, dir, probe string) map[string]interface{} {
+	t.Helper()
+	configPath, err := resolveTsConfig(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := readTsConfig(filepath.Join(dir, configPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := writeCheckTsConfig(dir, configPath, config, probe, true)
+	if err != nil {
+		t.Fatalf("writeCheckTsConfig: %v", err)
+	}
+	test.AssertEqual(t, path, filepath.Join(buildCacheDir, "tsconfig.check.json"))
+
+	data, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var check map[string]interface{}
+	if err := json.Unmarshal(data, &check); err != nil {
+		t.Fatal(err)
+	}
+	return check
+}
+
+func assertJSON(t *testing.T, got interface{}, expected string, name string) {
+	t.Helper()
+	var want interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		actual, _ := json.Marshal(got)
+		t.Errorf("%s = %s, expected %s", name, actual, expected)
+	}
+}
+
+func TestWriteCheckTsConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "configs", "base.json"), `{
+		"compilerOptions": { "baseUrl": "..", "paths": { "@/*": ["src/*"] }, "outDir": "../dist", "rootDir": "../src" },
+		"include": ["../src"]
+	}`)
+	writeFile(t, filepath.Join(dir, "tsconfig.json"), `{ "extends": "./configs/base.json" }`)
+
+	check := readCheckTsConfig(t, dir, filepath.Join(filepath.FromSlash(probeRoot), "src", "Component.tsx"))
+
+	// Project options come from the extended tsconfig, paths are rebased
+	// onto the cache directory
+	test.AssertEqual(t, check["extends"], "../../tsconfig.json")
+	assertJSON(t, check["include"], `["../../src"]`, "include")
+	assertJSON(t, check["exclude"], `["../../node_modules", "../../bower_components", "../../jspm_packages", "../../dist"]`, "exclude")
+	assertJSON(t, check["files"], `["interface-check/src/Component.tsx"]`, "files")
+	assertJSON(t, check["compilerOptions"], `{
+		"noEmit": true,
+		"incremental": true,
+		"tsBuildInfoFile": "./tsconfig.tsbuildinfo",
+		"composite": false,
+		"noUnusedLocals": true,
+		"noUnusedParameters": true,
+		"rootDirs": ["../..", "interface-check"],
+		"rootDir": "../.."
+	}`, "compilerOptions")
+}
+
+func TestWriteCheckTsConfigDefaultInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "tsconfig.json"), `{ "compilerOptions": { "rootDirs": ["src", "generated"] } }`)
+
+	// With the probe in files, tsc would otherwise check nothing else
+	check := readCheckTsConfig(t, dir, filepath.Join(filepath.FromSlash(probeRoot), "Component.tsx"))
+	assertJSON(t, check["include"], `["../../**/*"]`, "include")
+	assertJSON(t, check["compilerOptions"].(map[string]interface{})["rootDirs"],
+		`["../../src", "../../generated", "../..", "interface-check"]`, "rootDirs")
+}
+
+func TestWriteCheckTsConfigReferences(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "tsconfig.json"), `{ "files": [], "references": [{ "path": "./packages/ui" }] }`)
+
+	check := readCheckTsConfig(t, dir, "")
+	assertJSON(t, check["references"], `[{ "path": "../../packages/ui" }]`, "references")
+	test.AssertEqual(t, check["files"], nil, "the empty files list is inherited")
+	test.AssertEqual(t, check["include"], nil)
+}
+
+func TestWriteCheckTsConfigWithoutTsConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	check := readCheckTsConfig(t, dir, "")
+	test.AssertEqual(t, check["extends"], "./tsconfig.defaults.json")
+	assertJSON(t, check["include"], `["../../**/*.ts", "../../**/*.tsx"]`, "include")
+}
@@ -0,0 +1,232 @@
+package build
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tsSourceFile holds the declarations of a module source and the names it
+// imports or re-exports.
+type tsSourceFile struct {
+	decls map[string]*tsDecl
+	// imports maps local names to their declaration in another file, with
+	// an empty file for packages and unresolved paths
+	imports map[string]tsImport
+	// namespaces maps the names of `import * as` to their file
+	namespaces map[string]string
+	// reexports are the names of `export { A } from`, exportAll the files
+	// of `export * from`
+	reexports map[string]tsImport
+	exportAll []string
+}
+
+type tsImport struct {
+	file string
+	name string
+}
+
+// tsSources resolves type names as the file using them sees them: its own
+// declarations first, then the types it imports from other module sources.
+type tsSources struct {
+	files map[string]*tsSourceFile
+	// byName lists the declarations of every file by name, for types used
+	// without an import, such as global declarations
+	byName map[string][]*tsDecl
+}
+
+var (
+	namedImportRegex     = regexp.MustCompile(`\bimport\s+(?:type\s+)?(?:[\w$]+\s*,\s*)?\{([^}]*)\}\s*from\s*['"]([^'"]+)['"]`)
+	namespaceImportRegex = regexp.MustCompile(`\bimport\s+(?:type\s+)?(?:[\w$]+\s*,\s*)?\*\s*as\s+([\w$]+)\s+from\s*['"]([^'"]+)['"]`)
+	namedExportRegex     = regexp.MustCompile(`\bexport\s+(?:type\s+)?\{([^}]*)\}\s*from\s*['"]([^'"]+)['"]`)
+	exportAllRegex       = regexp.MustCompile(`\bexport\s+\*\s+from\s*['"]([^'"]+)['"]`)
+)
+
+func newTSSources() *tsSources {
+	return &tsSources{files: map[string]*tsSourceFile{}, byName: map[string][]*tsDecl{}}
+}
+
+// add reads the declarations and imports of a module source.
+func (s *tsSources) add(file, content string) {
+	file = filepath.Clean(file)
+	source := blankComments(content)
+	dir := filepath.Dir(file)
+	resolve := func(specifier string) string {
+		if !strings.HasPrefix(specifier, "./") && !strings.HasPrefix(specifier, "../") {
+			return ""
+		}
+		if resolved := resolveImport(dir, specifier); resolved != "" {
+			return filepath.Clean(resolved)
+		}
+		return ""
+	}
+
+	parsed := &tsSourceFile{
+		decls:      parseTSDeclarations(content),
+		imports:    map[string]tsImport{},
+		namespaces: map[string]string{},
+		reexports:  map[string]tsImport{},
+	}
+	for _, decl := range parsed.decls {
+		decl.file = file
+	}
+
+	for _, m := range namedImportRegex.FindAllStringSubmatch(source, -1) {
+		target := resolve(m[2])
+		for local, imported := range importSpecifiers(m[1]) {
+			parsed.imports[local] = tsImport{file: target, name: imported}
+		}
+	}
+	for _, m := range namespaceImportRegex.FindAllStringSubmatch(source, -1) {
+		parsed.namespaces[m[1]] = resolve(m[2])
+	}
+	for _, m := range namedExportRegex.FindAllStringSubmatch(source, -1) {
+		target := resolve(m[2])
+		for exported, name := range importSpecifiers(m[1]) {
+			parsed.reexports[exported] = tsImport{file: target, name: name}
+		}
+	}
+	for _, m := range exportAllRegex.FindAllStringSubmatch(source, -1) {
+		if target := resolve(m[1]); target != "" {
+			parsed.exportAll = append(parsed.exportAll, target)
+		}
+	}
+
+	s.files[file] = parsed
+	names := make([]string, 0, len(parsed.decls))
+	for name := range parsed.decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s.byName[name] = append(s.byName[name], parsed.decls[name])
+	}
+}
+
+// importSpecifiers maps the names an import or export list binds to the
+// names they come from: "A, type B as C" gives {A: A, C: B}.
+func importSpecifiers(list string) map[string]string {
+	names := map[string]string{}
+	for _, specifier := range strings.Split(list, ",") {
+		fields := strings.Fields(specifier)
+		if len(fields) > 0 && fields[0] == "type" {
+			fields = fields[1:]
+		}
+		switch {
+		case len(fields) == 1:
+			names[fields[0]] = fields[0]
+		case len(fields) == 3 && fields[1] == "as":
+			names[fields[2]] = fields[0]
+		}
+	}
+	return names
+}
+
+// resolve returns the declaration name refers to in file, or nil when it is
+// not declared in the module sources. A name neither declared nor imported
+// in file is looked up in every file, and is an error when several declare
+// it.
+func (s *tsSources) resolve(file, name string) (*tsDecl, error) {
+	decl, found := s.lookup(filepath.Clean(file), name)
+	if found {
+		return decl, nil
+	}
+
+	candidates := s.byName[name]
+	if len(candidates) > 1 {
+		files := make([]string, len(candidates))
+		for i, candidate := range candidates {
+			files[i] = candidate.file
+		}
+		return nil, fmt.Errorf("type '%s' used in %s is declared in %s; import it from the one it means", name, file, strings.Join(files, ", "))
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return nil, nil
+}
+
+// lookup resolves name through the declarations and imports of file. found
+// is false when file neither declares nor imports it.
+func (s *tsSources) lookup(file, name string) (decl *tsDecl, found bool) {
+	source, ok := s.files[file]
+	if !ok {
+		return nil, false
+	}
+
+	if namespace, member, ok := strings.Cut(name, "."); ok {
+		target, imported := source.namespaces[namespace]
+		if !imported {
+			return nil, false
+		}
+		return s.exported(target, member, 0), true
+	}
+	if decl, ok := source.decls[name]; ok {
+		return decl, true
+	}
+	if imported, ok := source.imports[name]; ok {
+		return s.exported(imported.file, imported.name, 0), true
+	}
+	return nil, false
+}
+
+// exported returns the declaration file exports as name, following
+// re-exports, or nil for files out of the module sources.
+func (s *tsSources) exported(file, name string, depth int) *tsDecl {
+	source, ok := s.files[file]
+	if !ok || depth > 10 {
+		return nil
+	}
+
+	if decl, ok := source.decls[name]; ok {
+		return decl
+	}
+	for _, forwarded := range []map[string]tsImport{source.reexports, source.imports} {
+		if target, ok := forwarded[name]; ok {
+			return s.exported(target.file, target.name, depth+1)
+		}
+	}
+	for _, target := range source.exportAll {
+		if decl := s.exported(target, name, depth+1); decl != nil {
+			return decl
+		}
+	}
+	return nil
+}
+
+// scoped returns t with its type names bound to file, so they keep their
+// meaning once substituted in a declaration of another file.
+func scoped(t *tsType, file string) *tsType {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	if copied.kind == "name" && copied.scope == "" {
+		copied.scope = file
+	}
+	copied.elem = scoped(t.elem, file)
+	copied.index = scoped(t.index, file)
+	copied.args = scopedTypes(t.args, file)
+	copied.types = scopedTypes(t.types, file)
+	if t.members != nil {
+		copied.members = make([]tsMember, len(t.members))
+		for i, member := range t.members {
+			member.typ = scoped(member.typ, file)
+			copied.members[i] = member
+		}
+	}
+	return &copied
+}
+
+func scopedTypes(types []*tsType, file string) []*tsType {
+	if types == nil {
+		return nil
+	}
+	bound := make([]*tsType, len(types))
+	for i, t := range types {
+		bound[i] = scoped(t, file)
+	}
+	return bound
+}
@@ -0,0 +1,157 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/devlyspace/dashspace-cli/test"
+)
+
+// loadSources writes files to a temporary directory and reads them as the
+// module sources. It returns the directory.
+func loadSources(t *testing.T, files map[string]string) (*tsSources, string) {
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, filepath.Join(dir, name), content)
+	}
+
+	sources := newTSSources()
+	for name := range files {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources.add(filepath.Join(dir, name), string(content))
+	}
+	return sources, dir
+}
+
+// inferSchema infers the schema of typeArg as used in file.
+func inferSchema(t *testing.T, sources *tsSources, file, typeArg string) (*JSONSchema, error) {
+	parser := &tsTypeParser{src: typeArg}
+	builder := newSchemaBuilder(sources, file)
+	schema := builder.root(parser.parseType())
+	if len(builder.errors) > 0 {
+		return nil, builder.errors[0]
+	}
+	return schema, nil
+}
+
+// propertyNames returns the property names of the items of schema, sorted.
+func propertyNames(schema *JSONSchema) string {
+	if schema.Items != nil {
+		schema = schema.Items
+	}
+	names := []string{}
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+var sameNameSources = map[string]string{
+	"src/github.ts":     `export interface Issue { number: number; title: string }`,
+	"src/linear.ts":     `export interface Issue { identifier: string; priority: number }`,
+	"src/Component.tsx": `import type { Issue } from './linear';`,
+	"src/hooks.ts": `import { Issue as GitHubIssue } from './github';
+		interface Issue { local: boolean }`,
+	"src/other.tsx": `import * as GitHub from './github';`,
+}
+
+func TestResolveThroughCallerImports(t *testing.T) {
+	sources, dir := loadSources(t, sameNameSources)
+
+	tests := []struct {
+		file       string
+		typeArg    string
+		properties string
+	}{
+		{"src/Component.tsx", "Issue[]", "identifier,priority"},
+		{"src/hooks.ts", "GitHubIssue[]", "number,title"},
+		{"src/hooks.ts", "Issue[]", "local"},
+		{"src/other.tsx", "GitHub.Issue[]", "number,title"},
+	}
+
+	for _, tt := range tests {
+		schema, err := inferSchema(t, sources, filepath.Join(dir, tt.file), tt.typeArg)
+		if err != nil {
+			t.Errorf("%s in %s: %v", tt.typeArg, tt.file, err)
+			continue
+		}
+		test.AssertEqual(t, propertyNames(schema), tt.properties, tt.typeArg, "in", tt.file)
+	}
+}
+
+func TestResolveAmbiguousName(t *testing.T) {
+	sources, dir := loadSources(t, sameNameSources)
+
+	// other.tsx neither declares nor imports Issue, which two files declare
+	_, err := inferSchema(t, sources, filepath.Join(dir, "src/other.tsx"), "Issue[]")
+	if err == nil || !strings.Contains(err.Error(), "type 'Issue'") {
+		t.Fatalf("expected an ambiguous name error, got %v", err)
+	}
+}
+
+func TestResolveGlobalAndReexportedNames(t *testing.T) {
+	sources, dir := loadSources(t, map[string]string{
+		"src/global.d.ts":   `interface Label { name: string }`,
+		"src/types/item.ts": `export interface Item { id: string; labels: Label[] }`,
+		"src/types/index.ts": `export * from './item';
+			export { Page as ItemPage } from './page';`,
+		"src/types/page.ts": `export interface Page<T> { items: T[]; next: string }`,
+		"src/Component.tsx": `import { Item, ItemPage } from './types';
+			interface Item { shadowed: true }`,
+		"src/hooks.ts": `import { Item, ItemPage } from './types';`,
+	})
+
+	// The argument of a generic keeps the meaning it has for the caller
+	schema, err := inferSchema(t, sources, filepath.Join(dir, "src/hooks.ts"), "ItemPage<Item>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertEqual(t, propertyNames(schema), "items,next")
+	test.AssertEqual(t, propertyNames(schema.Properties["items"]), "id,labels")
+	test.AssertEqual(t, propertyNames(schema.Properties["items"].Items.Properties["labels"]), "name")
+
+	schema, err = inferSchema(t, sources, filepath.Join(dir, "src/Component.tsx"), "ItemPage<Item>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertEqual(t, propertyNames(schema.Properties["items"]), "shadowed")
+}
+
+func TestResolvePackageImportLeftUntyped(t *testing.T) {
+	sources, dir := loadSources(t, map[string]string{
+		"src/Component.tsx": `import type { Issue } from '@octokit/types';`,
+		"src/local.ts":      `export interface Issue { local: boolean }`,
+	})
+
+	builder := newSchemaBuilder(sources, filepath.Join(dir, "src/Component.tsx"))
+	schema := builder.root((&tsTypeParser{src: "Issue"}).parseType())
+	test.AssertEqual(t, len(builder.errors), 0)
+	test.AssertEqual(t, propertyNames(schema), "")
+	test.AssertEqual(t, strings.Join(builder.unresolved, ","), "Issue")
+}
+
+func TestSameNameDefinitionsKeptApart(t *testing.T) {
+	sources, dir := loadSources(t, map[string]string{
+		"src/a.ts": `export interface Node { child?: Node; label: string }`,
+		"src/tree.ts": `import { Node as ANode } from './a';
+			import { Node as BNode } from './b';
+			export interface Tree { a: ANode; b: BNode }`,
+		"src/b.ts": `export interface Node { parent?: Node }`,
+	})
+
+	schema, err := inferSchema(t, sources, filepath.Join(dir, "src/tree.ts"), "Tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertEqual(t, schema.Properties["a"].Ref, "#/$defs/Node")
+	test.AssertEqual(t, schema.Properties["b"].Ref, "#/$defs/Node_2")
+	test.AssertEqual(t, propertyNames(schema.Defs["Node"]), "child,label")
+	test.AssertEqual(t, propertyNames(schema.Defs["Node_2"]), "parent")
+}
@@ -0,0 +1,460 @@
+package build
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tsType is a parsed TypeScript type expression.
+type tsType struct {
+	// kind is name, literal, object, array, tuple, union, intersection or
+	// unknown (a type the parser does not model, such as a function)
+	kind string
+	name string
+	// scope is the file a name is resolved in when it differs from the
+	// declaration being expanded, as for the arguments of a generic
+	scope   string
+	args    []*tsType
+	literal interface{}
+	members []tsMember
+	// index is the value type of an index signature
+	index *tsType
+	// elem is the element type of an array
+	elem  *tsType
+	types []*tsType
+}
+
+type tsMember struct {
+	name     string
+	optional bool
+	typ      *tsType
+	doc      string
+}
+
+// tsDecl is a named type declared in the module sources.
+type tsDecl struct {
+	name    string
+	file    string
+	doc     string
+	params  []string
+	typ     *tsType
+	extends []*tsType
+	// enum holds the values of an enum declaration
+	enum   []interface{}
+	isEnum bool
+}
+
+var (
+	typeDeclRegex   = regexp.MustCompile(`\btype\s+([A-Za-z_$][\w$]*)\s*(?:<[^=]*?>)?\s*=`)
+	enumDeclRegex   = regexp.MustCompile(`\benum\s+([A-Za-z_$][\w$]*)\s*\{`)
+	identRegex      = regexp.MustCompile(`^[A-Za-z_$][\w$]*`)
+	numberRegex     = regexp.MustCompile(`^-?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?`)
+	enumValueRegex  = regexp.MustCompile(`^([A-Za-z_$][\w$]*|'[^']*'|"[^"]*")\s*(?:=\s*(.+))?$`)
+	tupleLabelRegex = regexp.MustCompile(`^\s*[A-Za-z_$][\w$]*\??\s*:`)
+)
+
+// parseTSDeclarations reads the interfaces, type aliases and enums of a
+// source file. JSDoc comments become descriptions.
+func parseTSDeclarations(original string) map[string]*tsDecl {
+	source := blankComments(original)
+	decls := map[string]*tsDecl{}
+
+	for _, loc := range interfaceDeclRegex.FindAllStringSubmatchIndex(source, -1) {
+		p := &tsTypeParser{src: source, orig: original, pos: loc[1] - 1}
+		decl := &tsDecl{name: source[loc[2]:loc[3]], doc: docCommentBefore(original, loc[0]), params: typeParams(source, loc[3]), typ: p.parseObject()}
+		if loc[4] >= 0 {
+			for _, parent := range splitTopLevel(source[loc[4]:loc[5]], ",") {
+				pp := &tsTypeParser{src: strings.TrimSpace(parent)}
+				decl.extends = append(decl.extends, pp.parseType())
+			}
+		}
+		if existing, ok := decls[decl.name]; ok && existing.typ != nil && existing.typ.kind == "object" {
+			// Declaration merging
+			existing.typ.members = append(existing.typ.members, decl.typ.members...)
+			existing.extends = append(existing.extends, decl.extends...)
+			continue
+		}
+		decls[decl.name] = decl
+	}
+
+	for _, loc := range typeDeclRegex.FindAllStringSubmatchIndex(source, -1) {
+		p := &tsTypeParser{src: source, orig: original, pos: loc[1]}
+		name := source[loc[2]:loc[3]]
+		decls[name] = &tsDecl{name: name, doc: docCommentBefore(original, loc[0]), params: typeParams(source, loc[3]), typ: p.parseType()}
+	}
+
+	for _, loc := range enumDeclRegex.FindAllStringSubmatchIndex(source, -1) {
+		end := matchingBrace(source, loc[1]-1)
+		if end < 0 {
+			continue
+		}
+		decl := &tsDecl{name: source[loc[2]:loc[3]], doc: docCommentBefore(original, loc[0]), isEnum: true}
+		next := 0.0
+		for _, member := range splitTopLevel(source[loc[1]:end], ",") {
+			m := enumValueRegex.FindStringSubmatch(strings.TrimSpace(member))
+			if m == nil {
+				continue
+			}
+			value := strings.TrimSpace(m[2])
+			switch {
+			case value == "":
+				decl.enum = append(decl.enum, next)
+				next++
+			case strings.HasPrefix(value, "'") || strings.HasPrefix(value, `"`):
+				decl.enum = append(decl.enum, strings.Trim(value, `'"`))
+			default:
+				n, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					continue // computed member
+				}
+				decl.enum = append(decl.enum, n)
+				next = n + 1
+			}
+		}
+		decls[decl.name] = decl
+	}
+
+	return decls
+}
+
+// typeParams returns the names of the type parameters declared at offset,
+// as in Page<T, K extends string = string>.
+func typeParams(source string, offset int) []string {
+	p := &tsTypeParser{src: source, pos: offset}
+	if p.peek() != '<' {
+		return nil
+	}
+	end := matchingBracket(source, p.pos, '<', '>')
+	if end < 0 {
+		return nil
+	}
+	params := []string{}
+	for _, param := range splitTopLevel(source[p.pos+1:end], ",") {
+		if name := identRegex.FindString(strings.TrimSpace(param)); name != "" {
+			params = append(params, name)
+		}
+	}
+	return params
+}
+
+// docCommentBefore returns the text of the /** */ comment right before
+// offset, if any.
+func docCommentBefore(source string, offset int) string {
+	before := strings.TrimRight(source[:offset], " \t\r\n")
+	for _, keyword := range []string{"export", "declare", "readonly"} {
+		before = strings.TrimRight(strings.TrimSuffix(before, keyword), " \t\r\n")
+	}
+	if !strings.HasSuffix(before, "*/") {
+		return ""
+	}
+	start := strings.LastIndex(before, "/**")
+	if start < 0 {
+		return ""
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(before[start+3:len(before)-2], "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if strings.HasPrefix(line, "@") {
+			break
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+// tsTypeParser parses type expressions from src, whose comments are
+// blanked. orig is the source with comments, to read member docs.
+type tsTypeParser struct {
+	src  string
+	orig string
+	pos  int
+}
+
+func (p *tsTypeParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *tsTypeParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *tsTypeParser) accept(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *tsTypeParser) ident() string {
+	p.skipSpace()
+	name := identRegex.FindString(p.src[p.pos:])
+	p.pos += len(name)
+	return name
+}
+
+// parseType parses a union, the loosest type expression.
+func (p *tsTypeParser) parseType() *tsType {
+	p.accept("|")
+	types := []*tsType{p.parseIntersection()}
+	for p.peek() == '|' && !strings.HasPrefix(p.src[p.pos:], "||") {
+		p.pos++
+		types = append(types, p.parseIntersection())
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return &tsType{kind: "union", types: types}
+}
+
+func (p *tsTypeParser) parseIntersection() *tsType {
+	p.accept("&")
+	types := []*tsType{p.parsePostfix()}
+	for p.peek() == '&' && !strings.HasPrefix(p.src[p.pos:], "&&") {
+		p.pos++
+		types = append(types, p.parsePostfix())
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return &tsType{kind: "intersection", types: types}
+}
+
+func (p *tsTypeParser) parsePostfix() *tsType {
+	t := p.parsePrimary()
+	for p.peek() == '[' {
+		start := p.pos
+		p.pos++
+		if p.accept("]") {
+			t = &tsType{kind: "array", elem: t}
+			continue
+		}
+		// Indexed access, T['key']
+		p.pos = start
+		if end := matchingBracket(p.src, p.pos, '[', ']'); end > 0 {
+			p.pos = end + 1
+		}
+		t = &tsType{kind: "unknown"}
+	}
+	return t
+}
+
+func (p *tsTypeParser) parsePrimary() *tsType {
+	switch c := p.peek(); {
+	case c == '(':
+		start := p.pos
+		end := matchingBracket(p.src, start, '(', ')')
+		if end < 0 {
+			p.pos = len(p.src)
+			return &tsType{kind: "unknown"}
+		}
+		p.pos = end + 1
+		if p.accept("=>") {
+			// A function type
+			p.parseType()
+			return &tsType{kind: "unknown"}
+		}
+		inner := &tsTypeParser{src: p.src[:end], orig: p.orig, pos: start + 1}
+		return inner.parseType()
+
+	case c == '{':
+		return p.parseObject()
+
+	case c == '[':
+		end := matchingBracket(p.src, p.pos, '[', ']')
+		if end < 0 {
+			p.pos = len(p.src)
+			return &tsType{kind: "unknown"}
+		}
+		tuple := &tsType{kind: "tuple"}
+		for _, part := range splitTopLevel(p.src[p.pos+1:end], ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			// Named members, [start: Date, end: Date]
+			if m := tupleLabelRegex.FindString(part); m != "" {
+				part = part[len(m):]
+			}
+			element := &tsTypeParser{src: part}
+			tuple.types = append(tuple.types, element.parseType())
+		}
+		p.pos = end + 1
+		return tuple
+
+	case c == '\'' || c == '"' || c == '`':
+		end := skipString(p.src, p.pos)
+		value := p.src[p.pos+1 : min(end, len(p.src))]
+		p.pos = end + 1
+		if c == '`' && strings.Contains(value, "${") {
+			return &tsType{kind: "name", name: "string"}
+		}
+		return &tsType{kind: "literal", literal: value}
+
+	case c == '-' || (c >= '0' && c <= '9'):
+		text := numberRegex.FindString(p.src[p.pos:])
+		if text == "" {
+			p.pos++
+			return &tsType{kind: "unknown"}
+		}
+		p.pos += len(text)
+		n, _ := strconv.ParseFloat(text, 64)
+		return &tsType{kind: "literal", literal: n}
+	}
+
+	name := p.ident()
+	switch name {
+	case "":
+		if p.pos < len(p.src) {
+			p.pos++
+		}
+		return &tsType{kind: "unknown"}
+	case "true", "false":
+		return &tsType{kind: "literal", literal: name == "true"}
+	case "readonly", "keyof", "typeof", "unique":
+		operand := p.parsePostfix()
+		if name == "readonly" || name == "unique" {
+			return operand
+		}
+		if name == "keyof" {
+			return &tsType{kind: "name", name: "string"}
+		}
+		return &tsType{kind: "unknown"}
+	case "new":
+		p.parsePrimary()
+		return &tsType{kind: "unknown"}
+	}
+
+	for p.peek() == '.' {
+		p.pos++
+		name += "." + p.ident()
+	}
+
+	t := &tsType{kind: "name", name: name}
+	if p.peek() == '<' {
+		end := matchingBracket(p.src, p.pos, '<', '>')
+		if end < 0 {
+			p.pos = len(p.src)
+			return t
+		}
+		for _, arg := range splitTopLevel(p.src[p.pos+1:end], ",") {
+			argParser := &tsTypeParser{src: arg}
+			t.args = append(t.args, argParser.parseType())
+		}
+		p.pos = end + 1
+	}
+	return t
+}
+
+// parseObject parses the object type whose brace is at the current position.
+// Methods and call signatures are skipped: they are not data.
+func (p *tsTypeParser) parseObject() *tsType {
+	p.skipSpace()
+	open := p.pos
+	close := matchingBrace(p.src, open)
+	if close < 0 {
+		p.pos = len(p.src)
+		return &tsType{kind: "unknown"}
+	}
+
+	object := &tsType{kind: "object"}
+	body := &tsTypeParser{src: p.src[:close], orig: p.orig, pos: open + 1}
+	for {
+		for body.accept(";") || body.accept(",") {
+		}
+		if body.peek() == 0 {
+			break
+		}
+		start := body.pos
+		before := body.pos
+
+		if body.accept("readonly ") {
+			body.skipSpace()
+		}
+
+		if body.peek() == '[' {
+			// Index signature, [key: string]: T
+			end := matchingBracket(body.src, body.pos, '[', ']')
+			if end < 0 {
+				break
+			}
+			body.pos = end + 1
+			if body.accept(":") {
+				object.index = body.parseType()
+			}
+			continue
+		}
+
+		var name string
+		if c := body.peek(); c == '\'' || c == '"' {
+			end := skipString(body.src, body.pos)
+			name = body.src[body.pos+1 : min(end, len(body.src))]
+			body.pos = end + 1
+		} else {
+			name = body.ident()
+		}
+		optional := body.accept("?")
+
+		switch {
+		case name != "" && body.accept(":"):
+			member := tsMember{name: name, optional: optional, typ: body.parseType()}
+			if member.typ.kind == "unknown" && strings.Contains(body.src[start:body.pos], "=>") {
+				continue // a function property
+			}
+			if p.orig != "" {
+				member.doc = docCommentBefore(p.orig, start)
+			}
+			object.members = append(object.members, member)
+		case body.peek() == '<' || body.peek() == '(':
+			// A method, name<T>(args): T
+			if body.peek() == '<' {
+				body.pos = max(matchingBracket(body.src, body.pos, '<', '>'), body.pos) + 1
+			}
+			if body.peek() == '(' {
+				body.pos = max(matchingBracket(body.src, body.pos, '(', ')'), body.pos) + 1
+			}
+			if body.accept(":") {
+				body.parseType()
+			}
+		}
+
+		if body.pos == before {
+			body.pos++ // not a member, skip a character
+		}
+	}
+
+	p.pos = close + 1
+	return object
+}
+
+// matchingBracket returns the index of the bracket closing the one at open,
+// or -1.
+func matchingBracket(source string, open int, opening, closing byte) int {
+	depth := 0
+	for i := open; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipString(source, i)
+		case c == '=' && i+1 < len(source) && source[i+1] == '>':
+			i++
+		case c == opening:
+			depth++
+		case c == closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
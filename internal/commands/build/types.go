@@ -11,6 +11,7 @@ type DashspaceConfig struct {
 	Icon                  string                   `json:"icon,omitempty"`
 	Category              string                   `json:"category,omitempty"`
 	Tags                  []string                 `json:"tags,omitempty"`
+	Size                  *WidgetSizes             `json:"size,omitempty"`
 	Permissions           []string                 `json:"permissions,omitempty"`
 	RequiresSetup         bool                     `json:"requires_setup"`
 	ConfigurationSteps    string                   `json:"configuration_steps"`
@@ -19,3 +20,14 @@ type DashspaceConfig struct {
 	Checksum              string                   `json:"checksum"`
 	Timestamp             string                   `json:"timestamp"`
 }
+
+type WidgetSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type WidgetSizes struct {
+	Min     *WidgetSize `json:"min,omitempty"`
+	Default *WidgetSize `json:"default,omitempty"`
+	Max     *WidgetSize `json:"max,omitempty"`
+}
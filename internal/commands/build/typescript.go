@@ -1,6 +1,7 @@
 package build
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -11,44 +12,187 @@ import (
 )
 
 type TypeScriptValidator struct {
+	ctx         context.Context
 	projectPath string
+
+	// Init creates a missing tsconfig.json without asking
+	Init bool
+
+	// checked is set once the type check ran, diagnostics holds its results
+	checked     bool
+	diagnostics []tsDiagnostic
+	// reportUnused is set when the type check reports unused code the
+	// project's tsconfig does not turn into errors
+	reportUnused bool
+
+	// probe is the interface probe checked in the same run, nil when there
+	// is nothing to check: probeSkipped then says why, unless probeErr is set
+	probe            *interfaceProbe
+	probeSkipped     string
+	probeErr         error
+	probeDiagnostics []tsDiagnostic
 }
 
-func NewTypeScriptValidator(projectPath string) *TypeScriptValidator {
+func NewTypeScriptValidator(ctx context.Context, projectPath string) *TypeScriptValidator {
 	return &TypeScriptValidator{
+		ctx:         ctx,
 		projectPath: projectPath,
 	}
 }
 
+// tsUnusedCodes are the diagnostics of noUnusedLocals and noUnusedParameters.
+var tsUnusedCodes = map[int]bool{6133: true, 6138: true, 6192: true, 6196: true, 6198: true, 6199: true, 6205: true}
+
 func (t *TypeScriptValidator) Validate() error {
-	// Ensure tsconfig.json exists
-	if err := t.ensureTsConfig(); err != nil {
+	fmt.Println("🔍 Running TypeScript type checking...")
+	if err := t.typeCheck(); err != nil {
 		return err
 	}
 
-	// Run TypeScript compiler in check mode
-	fmt.Println("🔍 Running TypeScript type checking...")
-	cmd := exec.Command("npx", "tsc", "--noEmit", "--skipLibCheck")
+	errors := []tsDiagnostic{}
+	for _, d := range t.diagnostics {
+		if d.Severity == "error" && !(t.reportUnused && tsUnusedCodes[d.Code]) {
+			errors = append(errors, d)
+		}
+	}
+	if len(errors) > 0 {
+		fmt.Println("\n❌ TypeScript validation failed:")
+		for _, d := range errors {
+			fmt.Printf("   %s\n", d)
+		}
+		return fmt.Errorf("found %d TypeScript errors", len(errors))
+	}
+
+	fmt.Println("✅ TypeScript validation passed")
+	return nil
+}
+
+// typeCheck runs tsc once, with a tsconfig in the cache directory that
+// extends the project's and adds the interface probe. Projects with
+// references are checked with tsc --build, which builds the referenced
+// projects as their tsconfig says. Others are checked incrementally, keeping
+// the tsBuildInfo in the cache directory. The unused code checks are turned
+// on when the tsconfig enables neither.
+func (t *TypeScriptValidator) typeCheck() error {
+	if t.checked {
+		return nil
+	}
+
+	configPath, err := resolveTsConfig(t.projectPath, t.Init)
+	if err != nil {
+		return err
+	}
+	config, err := readTsConfig(filepath.Join(t.projectPath, configPath))
+	if err != nil {
+		return err
+	}
+
+	// Both report TS6133, so they are only added when the tsconfig enables
+	// neither
+	locals, _ := config.CompilerOptions["noUnusedLocals"].(bool)
+	parameters, _ := config.CompilerOptions["noUnusedParameters"].(bool)
+	t.reportUnused = !locals && !parameters
+
+	probePath := ""
+	if t.writeProbe(); t.probe != nil {
+		probePath = t.probe.path
+		defer os.Remove(filepath.Join(t.projectPath, probePath))
+	}
+	checkConfig, err := writeCheckTsConfig(t.projectPath, configPath, config, probePath, t.reportUnused)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"tsc", "--project", checkConfig, "--pretty", "false"}
+	if len(config.References) > 0 {
+		fmt.Printf("   Building %d project references with tsc --build\n", len(config.References))
+		args = []string{"tsc", "--build", checkConfig, "--pretty", "false"}
+	}
+
+	cmd := exec.CommandContext(t.ctx, "npx", args...)
 	cmd.Dir = t.projectPath
 
 	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Parse and format TypeScript errors
-		errors := t.parseTypeScriptErrors(string(output))
-		if len(errors) > 0 {
-			fmt.Println("\n❌ TypeScript validation failed:")
-			for _, e := range errors {
-				fmt.Printf("   %s\n", e)
-			}
-			return fmt.Errorf("found %d TypeScript errors", len(errors))
-		}
-		return fmt.Errorf("TypeScript validation failed: %s", output)
+	all := parseTSDiagnostics(string(output))
+	if err != nil && len(all) == 0 {
+		return fmt.Errorf("TypeScript validation failed: %s", strings.TrimSpace(string(output)))
 	}
 
-	fmt.Println("✅ TypeScript validation passed")
+	// The probe copies the component, so only its own diagnostics are kept,
+	// for the interface step
+	t.diagnostics = []tsDiagnostic{}
+	for _, d := range all {
+		if !t.isProbeFile(d.File) {
+			t.diagnostics = append(t.diagnostics, d)
+		}
+	}
+	if t.probe != nil {
+		t.probeDiagnostics = t.probe.diagnostics(all)
+	}
+	t.checked = true
 	return nil
 }
 
+// writeProbe writes the interface probe of the component, for typeCheck to
+// check with the project.
+func (t *TypeScriptValidator) writeProbe() {
+	parser, err := NewModuleParser()
+	if err != nil {
+		t.probeErr = err
+		return
+	}
+	declared, err := parser.DeclaredInterfaces()
+	if err != nil {
+		t.probeErr = err
+		return
+	}
+	if len(declared) == 0 {
+		t.probeSkipped = "ℹ️  No interfaces declared, skipping"
+		return
+	}
+
+	componentFile := findComponentFile()
+	if componentFile == "" {
+		t.probeSkipped = "⚠️  Warning: Component.tsx not found, skipping interface validation"
+		return
+	}
+
+	source, err := ioutil.ReadFile(componentFile)
+	if err != nil {
+		t.probeErr = fmt.Errorf("failed to read component file: %w", err)
+		return
+	}
+
+	// Probe the names dashspace-lib exports, whichever form Module.ts used
+	interfaces := LoadInterfaceContracts(t.projectPath).Canonical(declared)
+
+	probe, err := newInterfaceProbe(componentFile, string(source), interfaces)
+	if err != nil {
+		t.probeErr = err
+		return
+	}
+	path := filepath.Join(t.projectPath, probe.path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.probeErr = fmt.Errorf("failed to create interface probe: %w", err)
+		return
+	}
+	if err := ioutil.WriteFile(path, []byte(probe.content()), 0644); err != nil {
+		t.probeErr = fmt.Errorf("failed to create interface probe: %w", err)
+		return
+	}
+	t.probe = probe
+}
+
+// isProbeFile reports whether a diagnostic's file, relative to the project
+// like in tsc's output, is under probeRoot.
+func (t *TypeScriptValidator) isProbeFile(file string) bool {
+	if file == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.FromSlash(probeRoot), file)
+	return err == nil && filepath.IsLocal(rel)
+}
+
 func (t *TypeScriptValidator) ValidateDashspaceLibTypes() error {
 	fmt.Println("🔍 Validating dashspace-lib usage...")
 
@@ -104,192 +248,61 @@ func (t *TypeScriptValidator) ValidateDashspaceLibTypes() error {
 	return nil
 }
 
-func (t *TypeScriptValidator) ensureTsConfig() error {
-	tsconfigPath := filepath.Join(t.projectPath, "tsconfig.json")
-
-	if fileExists(tsconfigPath) {
-		return nil
-	}
-
-	fmt.Println("📝 Creating tsconfig.json...")
-
-	tsconfig := map[string]interface{}{
-		"compilerOptions": map[string]interface{}{
-			"target":                           "ES2020",
-			"module":                           "ESNext",
-			"lib":                              []string{"ES2020", "DOM", "DOM.Iterable"},
-			"jsx":                              "react",
-			"strict":                           true,
-			"esModuleInterop":                  true,
-			"skipLibCheck":                     true,
-			"forceConsistentCasingInFileNames": true,
-			"moduleResolution":                 "node",
-			"resolveJsonModule":                true,
-			"noEmit":                           true,
-			"types":                            []string{"react", "react-dom", "node"},
-		},
-		"include": []string{
-			"**/*.ts",
-			"**/*.tsx",
-		},
-		"exclude": []string{
-			"node_modules",
-			"dist",
-			"build",
-		},
-	}
-
-	jsonData, err := json.MarshalIndent(tsconfig, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to create tsconfig.json: %w", err)
-	}
-
-	if err := ioutil.WriteFile(tsconfigPath, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write tsconfig.json: %w", err)
-	}
-
-	fmt.Println("✅ Created tsconfig.json")
-	return nil
-}
-
-func (t *TypeScriptValidator) parseTypeScriptErrors(output string) []string {
-	lines := strings.Split(output, "\n")
-	var errors []string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Skip non-error lines
-		if strings.Contains(line, "error TS") {
-			// Format: file.ts(line,col): error TS2322: Message
-			errors = append(errors, line)
-		} else if strings.HasPrefix(line, "src/") || strings.HasPrefix(line, "./") {
-			// Include file references
-			if len(errors) > 0 && !strings.Contains(errors[len(errors)-1], line) {
-				errors[len(errors)-1] = line + " - " + errors[len(errors)-1]
-			}
-		}
-	}
-
-	return errors
-}
-
+// ValidateInterfaceImplementation reports the errors the type check found in
+// the interface probe, at the handlers they concern.
 func (t *TypeScriptValidator) ValidateInterfaceImplementation() error {
 	fmt.Println("🔍 Validating interface implementations with TypeScript...")
-
-	// Create a temporary test file to validate interface implementations
-	testFile := filepath.Join(t.projectPath, ".interface-check.ts")
-	defer os.Remove(testFile)
-
-	// Read Module.ts to get declared interfaces
-	moduleFile := findModuleFile()
-	if moduleFile == "" {
-		return fmt.Errorf("Module.ts not found")
-	}
-
-	_, err := ioutil.ReadFile(moduleFile)
-	if err != nil {
+	if err := t.typeCheck(); err != nil {
 		return err
 	}
-
-	// Read Component.tsx
-	componentFile := findComponentFile()
-	if componentFile == "" {
-		fmt.Printf("⚠️  Warning: Component.tsx not found, skipping interface validation\n")
+	if t.probeErr != nil {
+		return t.probeErr
+	}
+	if t.probe == nil {
+		fmt.Println(t.probeSkipped)
 		return nil
 	}
 
-	// Create a test that imports both and validates
-	testContent := `
-import { InterfaceHandlers } from 'dashspace-lib';
-import Component from './Component';
-
-// This file is auto-generated to validate interface implementations
-// If this compiles without errors, the interfaces are properly implemented
-`
-
-	if err := ioutil.WriteFile(testFile, []byte(testContent), 0644); err != nil {
-		return fmt.Errorf("failed to create interface test file: %w", err)
+	// Errors in the component itself are reported by the type check step
+	if len(t.probeDiagnostics) == 0 {
+		fmt.Printf("✅ %d interface implementations validated by TypeScript\n", len(t.probe.interfaces))
+		return nil
 	}
 
-	// Détecter le type de jsx depuis tsconfig.json
-	jsxMode := t.getJSXMode()
-
-	// Run TypeScript compiler on the test file avec les bonnes options jsx
-	cmd := exec.Command("npx", "tsc", testFile, "--noEmit", "--skipLibCheck", "--jsx", jsxMode, "--esModuleInterop", "--moduleResolution", "node")
-	cmd.Dir = t.projectPath
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Clean up error output to show only relevant parts
-		if strings.Contains(string(output), "error") {
-			// Filtrer les erreurs non pertinentes
-			errorLines := strings.Split(string(output), "\n")
-			var relevantErrors []string
-			for _, line := range errorLines {
-				// Ignorer les erreurs liées au jsx qui sont déjà gérées
-				if strings.Contains(line, "--jsx") {
-					continue
-				}
-				if strings.TrimSpace(line) != "" {
-					relevantErrors = append(relevantErrors, line)
-				}
-			}
-			if len(relevantErrors) > 0 {
-				return fmt.Errorf("interface implementation errors:\n%s", strings.Join(relevantErrors, "\n"))
-			}
-		}
+	fmt.Println("\n❌ Interface implementation errors:")
+	for _, d := range t.probeDiagnostics {
+		fmt.Printf("   %s\n", d)
 	}
-
-	fmt.Println("✅ Interface implementations validated by TypeScript")
-	return nil
+	return fmt.Errorf("found %d interface implementation errors", len(t.probeDiagnostics))
 }
 
-func (t *TypeScriptValidator) getJSXMode() string {
-	// Lire tsconfig.json pour récupérer le mode jsx
-	tsconfigPath := filepath.Join(t.projectPath, "tsconfig.json")
-
-	if !fileExists(tsconfigPath) {
-		return "react" // Default
-	}
-
-	data, err := ioutil.ReadFile(tsconfigPath)
-	if err != nil {
-		return "react" // Default
+// CheckUnusedImports reports the unused code the type check found, as
+// warnings unless the project's tsconfig makes them errors.
+func (t *TypeScriptValidator) CheckUnusedImports() error {
+	fmt.Println("🔍 Checking for unused imports...")
+	if err := t.typeCheck(); err != nil {
+		return err
 	}
-
-	var tsconfig map[string]interface{}
-	if err := json.Unmarshal(data, &tsconfig); err != nil {
-		return "react" // Default
+	if !t.reportUnused {
+		fmt.Println("ℹ️  Unused code follows the noUnusedLocals and noUnusedParameters of the tsconfig")
+		return nil
 	}
 
-	if compilerOptions, ok := tsconfig["compilerOptions"].(map[string]interface{}); ok {
-		if jsx, ok := compilerOptions["jsx"].(string); ok {
-			return jsx
+	unused := []tsDiagnostic{}
+	for _, d := range t.diagnostics {
+		if tsUnusedCodes[d.Code] {
+			unused = append(unused, d)
 		}
 	}
-
-	return "react" // Default
-}
-
-func (t *TypeScriptValidator) CheckUnusedImports() error {
-	fmt.Println("🔍 Checking for unused imports...")
-
-	// Use TypeScript compiler with additional checks
-	cmd := exec.Command("npx", "tsc", "--noEmit", "--noUnusedLocals", "--noUnusedParameters")
-	cmd.Dir = t.projectPath
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		if strings.Contains(string(output), "is declared but") {
-			fmt.Printf("⚠️  Warning: Found unused code:\n%s\n", output)
-			// Not a fatal error, just a warning
-		}
+	if len(unused) == 0 {
+		fmt.Println("✅ No unused code found")
+		return nil
 	}
 
+	fmt.Printf("⚠️  Warning: Found %d unused declarations:\n", len(unused))
+	for _, d := range unused {
+		fmt.Printf("   %s\n", d)
+	}
 	return nil
 }
 
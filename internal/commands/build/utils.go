@@ -1,6 +1,7 @@
 package build
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -57,10 +58,10 @@ func sanitizeSlug(name string) string {
 	return slug
 }
 
-func ensureDependencies() error {
+func ensureDependencies(ctx context.Context) error {
 	if _, err := os.Stat("node_modules"); err != nil {
 		fmt.Println("📦 Installing dependencies...")
-		cmd := exec.Command("npm", "install")
+		cmd := exec.CommandContext(ctx, "npm", "install")
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		if err := cmd.Run(); err != nil {
@@ -69,3 +70,12 @@ func ensureDependencies() error {
 	}
 	return nil
 }
+
+// NewModuleParser returns a Parser for the module file of the current directory.
+func NewModuleParser() (*Parser, error) {
+	moduleFile := findModuleFile()
+	if moduleFile == "" {
+		return nil, fmt.Errorf("Module.ts or Module.tsx not found")
+	}
+	return NewParser(moduleFile), nil
+}
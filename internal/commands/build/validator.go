@@ -47,16 +47,8 @@ func (v *Validator) ValidateMetadata(config *DashspaceConfig) error {
 }
 
 func (v *Validator) ValidatePermissions(permissions []string) error {
-	validPermissions := map[string]bool{
-		"storage:read":     true,
-		"storage:write":    true,
-		"ui:notifications": true,
-		"ui:modals":        true,
-		"network:external": true,
-	}
-
 	for _, perm := range permissions {
-		if !validPermissions[perm] {
+		if !validPermission(perm) {
 			fmt.Printf("⚠️  Warning: Unknown permission '%s'\n", perm)
 		}
 	}
@@ -1,68 +1,391 @@
 package build
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+// DefaultDebounce is how long the watcher waits for changes to settle before rebuilding.
+const DefaultDebounce = 300 * time.Millisecond
+
+// alwaysIgnored directories are never watched, whatever .gitignore says.
+var alwaysIgnored = map[string]bool{
+	"node_modules":      true,
+	"dashspace_modules": true,
+	"dist":              true,
+	".dev":              true,
+	".dashspace":        true,
+	".git":              true,
+}
+
+// Watcher recursively watches source roots and runs a build when files are
+// created, modified, renamed or deleted. Paths matched by .gitignore, the
+// alwaysIgnored directories, explicitly ignored paths and the interface
+// probes the build writes are skipped.
+//
+// Changes are debounced; when new changes arrive while a build is running,
+// the build's context is cancelled and a new build starts once it returns.
 type Watcher struct {
+	Debounce time.Duration
+
+	roots   []string
+	ignored map[string]bool
+	rules   map[string][]ignoreRule
 	watcher *fsnotify.Watcher
 }
 
-func NewWatcher() *Watcher {
-	return &Watcher{}
+// BuildFunc runs one build. ctx is cancelled when newer changes supersede it.
+type BuildFunc func(ctx context.Context, changed []string)
+
+// NewWatcher creates a watcher for roots (the current directory if none).
+func NewWatcher(roots ...string) *Watcher {
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	w := &Watcher{
+		Debounce: DefaultDebounce,
+		ignored:  map[string]bool{},
+		rules:    map[string][]ignoreRule{},
+	}
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		w.roots = append(w.roots, root)
+		w.rules[root] = loadGitignore(filepath.Join(root, ".gitignore"))
+	}
+	return w
+}
+
+// Ignore excludes paths (files or directories) from watching, e.g. the build
+// output directory or files generated by the build itself.
+func (w *Watcher) Ignore(paths ...string) {
+	for _, path := range paths {
+		if path != "" {
+			w.ignored[filepath.Clean(path)] = true
+		}
+	}
 }
 
-func (w *Watcher) Watch(buildFunc func()) error {
+// Watch blocks until ctx is done, calling build for every settled batch of
+// changes. It returns once the last build has finished.
+func (w *Watcher) Watch(ctx context.Context, build BuildFunc) error {
 	var err error
 	w.watcher, err = fsnotify.NewWatcher()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to create watcher: %w", err)
 	}
 	defer w.watcher.Close()
 
-	done := make(chan bool)
-	var debounceTimer *time.Timer
+	for _, root := range w.roots {
+		if err := w.addTree(root); err != nil {
+			return err
+		}
+	}
+
+	var (
+		pending = map[string]bool{}
+		timer   = time.NewTimer(time.Hour)
+		running context.CancelFunc
+		done    chan struct{}
+		// changes of a cancelled build are carried into the next one
+		mu       sync.Mutex
+		inFlight []string
+	)
+	timer.Stop()
 
-	go func() {
-		for {
-			select {
-			case event, ok := <-w.watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					if debounceTimer != nil {
-						debounceTimer.Stop()
-					}
-					debounceTimer = time.AfterFunc(300*time.Millisecond, func() {
-						fmt.Printf("\n🔄 File changed: %s\n", event.Name)
-						buildFunc()
-					})
+	start := func() {
+		mu.Lock()
+		for _, path := range inFlight {
+			pending[path] = true
+		}
+		changed := make([]string, 0, len(pending))
+		for path := range pending {
+			changed = append(changed, path)
+		}
+		sort.Strings(changed)
+		inFlight = changed
+		mu.Unlock()
+		pending = map[string]bool{}
+
+		if len(changed) == 1 {
+			fmt.Printf("\n🔄 File changed: %s\n", changed[0])
+		} else {
+			fmt.Printf("\n🔄 %d files changed\n", len(changed))
+		}
+
+		buildCtx, cancel := context.WithCancel(ctx)
+		running = cancel
+		done = make(chan struct{})
+
+		go func(finished chan struct{}) {
+			defer close(finished)
+			build(buildCtx, changed)
+			if buildCtx.Err() == nil {
+				mu.Lock()
+				inFlight = nil
+				mu.Unlock()
+			}
+		}(done)
+	}
+
+	// stop cancels the running build, if any, and waits for it to return
+	stop := func() {
+		if running != nil {
+			running()
+			<-done
+			running = nil
+		}
+	}
+	defer stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !w.handle(event) {
+				continue
+			}
+			pending[event.Name] = true
+			// Drain a tick that already fired so it can't start a stale build
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
 				}
-			case err, ok := <-w.watcher.Errors:
-				if !ok {
-					return
+			}
+			timer.Reset(w.Debounce)
+
+			// Supersede the running build right away rather than after the debounce
+			if running != nil {
+				select {
+				case <-done:
+				default:
+					fmt.Printf("⏹️  Cancelling build, %s changed\n", event.Name)
 				}
+				stop()
+			}
+
+		case <-timer.C:
+			stop()
+			start()
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Println("Watch error:", err)
+		}
+	}
+}
+
+// handle updates the watch list for event and reports whether it should
+// trigger a build.
+func (w *Watcher) handle(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod || w.isIgnored(event.Name) || isTemporaryFile(event.Name) {
+		return false
+	}
+
+	if event.Op&fsnotify.Create == fsnotify.Create {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := w.addTree(event.Name); err != nil {
 				fmt.Println("Watch error:", err)
 			}
 		}
-	}()
+	}
 
-	w.watcher.Add(".")
-	if fileExists("src") {
-		w.watcher.Add("./src")
+	// Removed and renamed directories drop out of the fsnotify watch list on their own
+	return true
+}
+
+func (w *Watcher) addTree(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// The directory may have disappeared between the event and the walk
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && w.isIgnored(path) {
+			return filepath.SkipDir
+		}
+		if err := w.watcher.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+func (w *Watcher) isIgnored(path string) bool {
+	path = filepath.Clean(path)
+	if w.ignored[path] {
+		return true
 	}
-	if fileExists("hooks") {
-		w.watcher.Add("./hooks")
+
+	for _, root := range w.roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		for i, part := range parts {
+			if alwaysIgnored[part] || w.ignored[filepath.Join(root, filepath.Join(parts[:i+1]...))] {
+				return true
+			}
+		}
+
+		info, statErr := os.Stat(path)
+		isDir := statErr == nil && info.IsDir()
+		if matchIgnoreRules(w.rules[root], parts, isDir) {
+			return true
+		}
 	}
-	if fileExists("components") {
-		w.watcher.Add("./components")
+	return false
+}
+
+// isTemporaryFile reports editor swap and backup files.
+func isTemporaryFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasSuffix(base, "~") ||
+		strings.HasSuffix(base, ".swp") ||
+		strings.HasSuffix(base, ".swx") ||
+		strings.HasPrefix(base, ".#") ||
+		base == "4913"
+}
+
+// ignoreRule is one .gitignore pattern compiled to a regexp over slash paths.
+type ignoreRule struct {
+	pattern  *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+func loadGitignore(path string) []ignoreRule {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
 	}
+	defer file.Close()
+	return parseIgnoreRules(file)
+}
+
+// parseIgnoreRules compiles the patterns of a .gitignore.
+func parseIgnoreRules(r io.Reader) []ignoreRule {
+	rules := []ignoreRule{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
 
-	fmt.Println("\n👀 Watching for changes...")
-	<-done
-	return nil
+		rule := ignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+
+		re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+		if err != nil {
+			continue
+		}
+		rule.pattern = re
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// matchIgnoreRules applies gitignore semantics: the last matching rule wins,
+// and a path is ignored when it or one of its parent directories is.
+func matchIgnoreRules(rules []ignoreRule, parts []string, isDir bool) bool {
+	if len(rules) == 0 {
+		return false
+	}
+
+	for i := range parts {
+		partIsDir := isDir || i < len(parts)-1
+		rel := strings.Join(parts[:i+1], "/")
+
+		ignored := false
+		for _, rule := range rules {
+			if rule.dirOnly && !partIsDir {
+				continue
+			}
+			target := parts[i]
+			if rule.anchored {
+				target = rel
+			}
+			if rule.pattern.MatchString(target) {
+				ignored = !rule.negate
+			}
+		}
+		if ignored {
+			return true
+		}
+	}
+	return false
+}
+
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					sb.WriteString("(.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
 }
@@ -0,0 +1,96 @@
+package build
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob    string
+		match   []string
+		noMatch []string
+	}{
+		{"*.log", []string{"debug.log", ".log"}, []string{"debug.log.txt", "logs/debug.log"}},
+		{"debug?.txt", []string{"debug1.txt"}, []string{"debug.txt", "debug12.txt", "debug/.txt"}},
+		{"**/cache", []string{"cache", "a/cache", "a/b/cache"}, []string{"acache", "cache/a"}},
+		{"docs/**", []string{"docs/a", "docs/a/b"}, []string{"docs", "other/a"}},
+		{"a/**/b", []string{"a/b", "a/x/b", "a/x/y/b"}, []string{"a/xb", "b"}},
+		{"file[0-9].ts", []string{"file1.ts"}, []string{"filea.ts"}},
+		{"file[!0-9].ts", []string{"filea.ts"}, []string{"file1.ts"}},
+		{"a+b(c).ts", []string{"a+b(c).ts"}, []string{"aab(c).ts"}},
+		{"[unclosed", []string{"[unclosed"}, []string{"u"}},
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile("^" + globToRegexp(tt.glob) + "$")
+		for _, path := range tt.match {
+			if !re.MatchString(path) {
+				t.Errorf("%q should match %q", tt.glob, path)
+			}
+		}
+		for _, path := range tt.noMatch {
+			if re.MatchString(path) {
+				t.Errorf("%q should not match %q", tt.glob, path)
+			}
+		}
+	}
+}
+
+func TestMatchIgnoreRules(t *testing.T) {
+	gitignore := `
+# comments and blank lines are skipped
+
+*.log
+!keep.log
+build/
+/root-only.ts
+src/generated/*.ts
+!src/generated/index.ts
+tmp/
+!tmp/keep.txt
+`
+	rules := parseIgnoreRules(strings.NewReader(gitignore))
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"debug.log", false, true},
+		{"logs/debug.log", false, true},
+		{"keep.log", false, false},
+		{"logs/keep.log", false, false},
+
+		// Directory-only patterns skip files of the same name, not directories
+		{"build", true, true},
+		{"build", false, false},
+		{"build/index.js", false, true},
+		{"src/build/index.js", false, true},
+
+		// Patterns with a slash are anchored to the root
+		{"root-only.ts", false, true},
+		{"src/root-only.ts", false, false},
+		{"src/generated/types.ts", false, true},
+		{"src/generated/index.ts", false, false},
+		{"other/src/generated/types.ts", false, false},
+
+		// A file can't be re-included when its directory is ignored
+		{"tmp/keep.txt", false, true},
+
+		{"src/Component.tsx", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := matchIgnoreRules(rules, strings.Split(tt.path, "/"), tt.isDir); got != tt.ignored {
+			t.Errorf("%s (dir: %v): ignored = %v, expected %v", tt.path, tt.isDir, got, tt.ignored)
+		}
+	}
+}
+
+func TestMatchIgnoreRulesWithoutRules(t *testing.T) {
+	if matchIgnoreRules(nil, []string{"a.log"}, false) {
+		t.Error("nothing is ignored without rules")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"regexp"
 	"strings"
+
+	"github.com/devlyspace/dashspace-cli/internal/webhooks"
 )
 
 type WebhookValidator struct{}
@@ -38,15 +40,14 @@ func (v *WebhookValidator) ValidateWebhookConfiguration(webhooks map[string]inte
 		return fmt.Errorf("webhook events array is required and cannot be empty")
 	}
 
-	// Validate event format
 	for _, event := range events {
 		if event == "" {
 			return fmt.Errorf("empty event found in webhooks.events")
 		}
-		// Events should be lowercase with underscores (e.g., "issues", "pull_request")
-		if !v.isValidEventFormat(event) {
-			fmt.Printf("⚠️  Warning: event '%s' should use lowercase with underscores (e.g., 'pull_request', 'issues')\n", event)
-		}
+	}
+
+	if err := v.validateEvents(strings.TrimPrefix(provider, "Provider."), events); err != nil {
+		return err
 	}
 
 	// Validate configFields (required for GitHub-like providers)
@@ -144,7 +145,7 @@ func (v *WebhookValidator) ValidateWebhookImplementation(webhooks map[string]int
 	for _, event := range events {
 		// Convert event name to method name (issues -> Issues, pull_request -> PullRequest)
 		methodName := v.eventToMethodName(event)
-		handlerMethodPattern := regexp.MustCompile(fmt.Sprintf(`handle%sEvent\s*\([^)]*(?:event|webhook)[^)]*:\s*WebhookEvent`, methodName))
+		handlerMethodPattern := regexp.MustCompile(fmt.Sprintf(`handle%sEvent\s*\([^)]*(?:event|webhook)[^)]*:\s*\w*WebhookEvent\b`, methodName))
 
 		if !handlerMethodPattern.MatchString(moduleContent) {
 			fmt.Printf("⚠️  Warning: Expected handler method 'handle%sEvent(event: WebhookEvent)' not found\n", methodName)
@@ -267,6 +268,38 @@ func (v *WebhookValidator) ValidateWebhookSecurity() error {
 
 // Helper functions
 
+// validateEvents checks events against the bundled payload schemas of the
+// provider. Only likely typos of a bundled event fail; events the schemas do
+// not cover are reported. Events of providers without schemas are only
+// checked for format.
+func (v *WebhookValidator) validateEvents(provider string, events []string) error {
+	schema, err := webhooks.LoadSchema(provider)
+	if err != nil {
+		for _, event := range events {
+			// Events should be lowercase with underscores (e.g., "issues", "pull_request")
+			if !v.isValidEventFormat(event) {
+				fmt.Printf("⚠️  Warning: event '%s' should use lowercase with underscores (e.g., 'pull_request', 'issues')\n", event)
+			}
+		}
+		return nil
+	}
+
+	typos, unschemaed := schema.CheckEvents(events)
+	for _, event := range unschemaed {
+		fmt.Printf("⚠️  Warning: no bundled %s schema for '%s' events, their payloads are not checked\n", schema.Title, event)
+	}
+	if len(typos) > 0 {
+		for _, err := range typos {
+			fmt.Printf("❌ %v\n", err)
+		}
+		return fmt.Errorf("%d unknown %s webhook events", len(typos), schema.Title)
+	}
+	if len(unschemaed) == 0 {
+		fmt.Printf("✅ All %d events are known %s events\n", len(events), schema.Title)
+	}
+	return nil
+}
+
 func (v *WebhookValidator) isValidProviderName(provider string) bool {
 	validProviders := map[string]bool{
 		"github":   true,
@@ -290,8 +323,8 @@ func (v *WebhookValidator) isValidEventFormat(event string) bool {
 }
 
 func (v *WebhookValidator) eventToMethodName(event string) string {
-	// Convert "issues" -> "Issues", "pull_request" -> "PullRequest"
-	parts := strings.Split(event, "_")
+	// Convert "issues" -> "Issues", "pull_request" -> "PullRequest", "invoice.paid" -> "InvoicePaid"
+	parts := strings.FieldsFunc(event, func(r rune) bool { return r == '_' || r == '.' })
 	result := ""
 	for _, part := range parts {
 		if len(part) > 0 {
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/devlyspace/dashspace-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and change CLI settings",
+		Long: `Read and change CLI settings.
+
+KEYS:
+  profile            Active profile (same as 'dashspace profile use')
+  api_url            Modly API URL of the active profile
+  credential_store   keyring, file, env or auto
+  debug              true or false
+  timeout            How long to wait for the API, e.g. 30s or 2m
+
+Values are resolved in this order: command-line flags (--profile, --api-url,
+--debug), environment variables (DASHSPACE_PROFILE, DASHSPACE_API_URL,
+DASHSPACE_DEBUG, DASHSPACE_TIMEOUT, DASHSPACE_TOKEN), the profile in ~/.dashspace/config.json,
+then built-in defaults.`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			setting, err := config.Get(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(setting.Value)
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Save a setting in the config file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.Set(args[0], args[1]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ %s = %s\n", args[0], args[1])
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List all settings with where their value comes from",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, key := range config.Keys() {
+				setting, err := config.Get(key)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("%-18s %-40s (%s)\n", setting.Key, setting.Value, setting.Source)
+			}
+			return nil
+		},
+	})
+
+	return cmd
+}
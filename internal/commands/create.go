@@ -418,7 +418,7 @@ const ChartComponent: React.FC = () => {
                         <div key={index} className="flex-1 flex flex-col items-center">
                             <div
                                 className="bg-blue-500 w-full rounded-t"
-                                style={{ height: `+"`${(value / maxValue) * 100}%`"+` }}
+                                style={{ height: `+"`${(value / maxValue) * 100}%%`"+` }}
                             ></div>
                             <span className="text-xs mt-1">{chartData.labels[index]}</span>
                         </div>
@@ -580,7 +580,10 @@ export default ListComponent;`, className, name, strings.Title(strings.ReplaceAl
 
 func generateGitignore() string {
 	return `node_modules/
+dashspace_modules/
 dist/
+.dashspace/
+.dev/
 build/
 *.log
 .DS_Store
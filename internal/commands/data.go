@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+	"github.com/spf13/cobra"
+)
+
+func NewDataCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "data",
+		Short: "Inspect the data modules expose",
+	}
+
+	cmd.AddCommand(newDataDiffCmd())
+
+	return cmd
+}
+
+func newDataDiffCmd() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <old-manifest> <new-manifest>",
+		Short: "Classify the data schema changes between two builds",
+		Long: `Compare the data_schema of two dashspace.json manifests (or build directories)
+and classify each change, from the point of view of modules consuming the old
+data:
+
+  breaking       removed providers, fields, capabilities or computed metrics,
+                 changed types or data type, fields that became nullable or
+                 optional, new enum values
+  non-breaking   added providers and fields, narrower types, removed enum values
+
+'dashspace publish' refuses breaking changes unless the major version is bumped.
+
+EXAMPLES:
+  dashspace data diff old/dashspace.json dist/dashspace.json
+  dashspace data diff v1.4.0/ dist/ --json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diffDataSchemas(args[0], args[1], jsonOutput)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the changes as JSON")
+
+	return cmd
+}
+
+func diffDataSchemas(oldPath, newPath string, jsonOutput bool) error {
+	previous, err := build.ReadManifestDataSchema(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to read old manifest: %v", err)
+	}
+	current, err := build.ReadManifestDataSchema(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to read new manifest: %v", err)
+	}
+
+	changes := build.DiffDataSchemas(previous, current)
+
+	if jsonOutput {
+		output, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(output))
+		return nil
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("✅ No data schema changes")
+		return nil
+	}
+	printDataSchemaChanges(changes)
+	return nil
+}
+
+func printDataSchemaChanges(changes []build.DataSchemaChange) {
+	breaking := build.BreakingChanges(changes)
+	if len(breaking) > 0 {
+		fmt.Printf("❌ %d breaking data schema changes:\n", len(breaking))
+		for _, change := range breaking {
+			fmt.Printf("   - %s\n", change)
+		}
+	}
+
+	if compatible := len(changes) - len(breaking); compatible > 0 {
+		fmt.Printf("✅ %d non-breaking data schema changes:\n", compatible)
+		for _, change := range changes {
+			if !change.Breaking {
+				fmt.Printf("   - %s\n", change)
+			}
+		}
+	}
+}
@@ -1,22 +1,25 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"html/template"
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
-	"time"
+	"strings"
 
-	"github.com/fsnotify/fsnotify"
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+	"github.com/devlyspace/dashspace-cli/internal/webhooks"
 	"github.com/spf13/cobra"
 )
 
 func NewDevCmd() *cobra.Command {
 	var (
-		port   string
-		noOpen bool
+		opts      devOptions
+		workspace bool
 	)
 
 	cmd := &cobra.Command{
@@ -24,87 +27,57 @@ func NewDevCmd() *cobra.Command {
 		Short: "Start development server with hot-reload",
 		Long:  "Start a local development server to test your module with live reloading",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runDevServer(port, noOpen)
+			if workspace {
+				return runWorkspaceDevServer(opts)
+			}
+			return runDevServer(opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&port, "port", "p", "3000", "Port to run the dev server")
-	cmd.Flags().BoolVar(&noOpen, "no-open", false, "Don't open browser automatically")
+	cmd.Flags().StringVarP(&opts.Port, "port", "p", "3000", "Port to run the dev server (the next free port is used if taken)")
+	cmd.Flags().StringVar(&opts.Host, "host", "localhost", "Host to bind the dev server to (use 0.0.0.0 to expose it on your network)")
+	cmd.Flags().BoolVar(&opts.NoOpen, "no-open", false, "Don't open browser automatically")
+	cmd.Flags().BoolVar(&opts.HTTPS, "https", false, "Serve over HTTPS with a self-signed certificate")
+	cmd.Flags().BoolVar(&workspace, "workspace", false, "Serve every module found in the current directory on one dashboard")
+	cmd.Flags().StringVar(&opts.WebhookSecret, "webhook-secret", webhooks.DefaultSecret, "Secret used to verify simulated webhook deliveries")
 
 	return cmd
 }
 
-func runDevServer(port string, noOpen bool) error {
+func runDevServer(opts devOptions) error {
 	fmt.Println("🚀 Starting Dashspace development server...")
 
-	// Check if esbuild is installed, if not install it
-	if _, err := exec.LookPath("esbuild"); err != nil {
-		fmt.Println("📦 Installing esbuild...")
-		installCmd := exec.Command("npm", "install", "-D", "esbuild")
-		installCmd.Stdout = os.Stdout
-		installCmd.Stderr = os.Stderr
-		if err := installCmd.Run(); err != nil {
-			return fmt.Errorf("failed to install esbuild: %v", err)
-		}
+	ctx, stop := devContext()
+	defer stop()
+
+	if err := ensureEsbuild(); err != nil {
+		return err
 	}
 
 	// Initial build
-	if err := buildForDev(); err != nil {
+	if err := buildForDev(ctx, singleDevTarget); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
 		return fmt.Errorf("initial build failed: %v", err)
 	}
 
-	// Start file watcher
-	watcher, err := fsnotify.NewWatcher()
+	server, err := listenDev(opts)
 	if err != nil {
-		return fmt.Errorf("failed to create watcher: %v", err)
-	}
-	defer watcher.Close()
-
-	// Watch source files
-	watchFiles := []string{
-		"Module.ts",
-		"Module.tsx",
-		"Component.tsx",
-		"types.ts",
-	}
-
-	for _, file := range watchFiles {
-		if _, err := os.Stat(file); err == nil {
-			if err := watcher.Add(file); err != nil {
-				fmt.Printf("⚠️  Failed to watch %s: %v\n", file, err)
-			}
-		}
+		return err
 	}
 
-	// Start rebuild goroutine
-	go func() {
-		var lastBuild time.Time
-		for {
-			select {
-			case event, ok := <-watcher.Events:
-				if !ok {
-					return
-				}
-				if event.Op&fsnotify.Write == fsnotify.Write {
-					if time.Since(lastBuild) > 100*time.Millisecond {
-						fmt.Printf("📝 File changed: %s\n", event.Name)
-						fmt.Println("🔄 Rebuilding...")
-						if err := buildForDev(); err != nil {
-							fmt.Printf("❌ Build failed: %v\n", err)
-						} else {
-							fmt.Println("✅ Build successful")
-						}
-						lastBuild = time.Now()
-					}
-				}
-			case err, ok := <-watcher.Errors:
-				if !ok {
-					return
-				}
-				fmt.Printf("❌ Watcher error: %v\n", err)
+	// Rebuild on any source change, cancelling a rebuild that is superseded
+	watchDone := watchDevTargets(ctx, []devTarget{singleDevTarget}, func(ctx context.Context, target devTarget, changed []string) {
+		fmt.Println("🔄 Rebuilding...")
+		if err := buildForDev(ctx, target); err != nil {
+			if ctx.Err() == nil {
+				fmt.Printf("❌ Build failed: %v\n", err)
 			}
+			return
 		}
-	}()
+		fmt.Println("✅ Build successful")
+	})
 
 	// Setup HTTP server
 	mux := http.NewServeMux()
@@ -115,50 +88,137 @@ func runDevServer(port string, noOpen bool) error {
 		http.ServeFile(w, r, ".dev/bundle.js")
 	})
 
+	// Simulated webhook deliveries are pushed to the page over a websocket
+	hub := newDevHub()
+	mux.HandleFunc("/__dashspace/ws", hub.handleWS)
+	mux.HandleFunc("/__dashspace/webhooks", webhookHandler(hub, opts.WebhookSecret))
+
+	// Manifest extracted from Module.ts, used to render the setup wizard
+	mux.HandleFunc("/__dashspace/manifest", manifestHandler)
+
 	// Serve main HTML page
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		serveDevHTML(w, r)
 	})
 
-	serverURL := fmt.Sprintf("http://localhost:%s", port)
-	fmt.Printf("\n✨ Development server running at %s\n", serverURL)
+	fmt.Printf("\n✨ Development server running at %s\n", server.URL)
 	fmt.Println("👀 Watching for file changes...")
+	fmt.Printf("🪝 Webhook endpoint: %s/__dashspace/webhooks (try 'dashspace webhook send <event>')\n", server.URL)
 	fmt.Println("\nPress Ctrl+C to stop")
 
-	// Open browser
-	if !noOpen {
-		go func() {
-			time.Sleep(1 * time.Second)
-			openBrowser(serverURL)
-		}()
+	// Start server; on shutdown wait for the watcher so a rebuild can remove its generated index.ts
+	return server.Serve(ctx, mux, func() {
+		<-watchDone
+	})
+}
+
+// watchDevTargets watches the source of every target and calls rebuild with
+// the targets affected by each batch of changes. The returned channel is
+// closed once ctx is done and the last rebuild has returned.
+func watchDevTargets(ctx context.Context, targets []devTarget, rebuild func(ctx context.Context, target devTarget, changed []string)) <-chan struct{} {
+	dirs := make([]string, 0, len(targets))
+	for _, target := range targets {
+		dirs = append(dirs, target.Dir)
+	}
+
+	watcher := build.NewWatcher(dirs...)
+	for _, target := range targets {
+		// index.ts is generated (and removed) by buildForDev when the module has none
+		indexPath := filepath.Join(target.Dir, "index.ts")
+		if _, err := os.Stat(indexPath); err != nil {
+			watcher.Ignore(indexPath)
+		}
+		watcher.Ignore(filepath.Dir(target.Outfile))
 	}
 
-	// Start server
-	return http.ListenAndServe(":"+port, mux)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := watcher.Watch(ctx, func(ctx context.Context, changed []string) {
+			for _, target := range targets {
+				if files := filesInDir(changed, target.Dir); len(files) > 0 {
+					rebuild(ctx, target, files)
+				}
+				if ctx.Err() != nil {
+					return
+				}
+			}
+		})
+		if err != nil {
+			fmt.Printf("❌ Watcher error: %v\n", err)
+		}
+	}()
+	return done
+}
+
+func filesInDir(paths []string, dir string) []string {
+	dir = filepath.Clean(dir)
+	files := []string{}
+	for _, path := range paths {
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// devTarget describes one module built by the dev server and where it mounts.
+type devTarget struct {
+	Dir     string
+	Outfile string
+	RootID  string
+	Slug    string
+}
+
+// singleDevTarget is the module in the current directory, mounted into #root.
+var singleDevTarget = devTarget{
+	Dir:     ".",
+	Outfile: filepath.Join(".dev", "bundle.js"),
+	RootID:  "root",
+}
+
+// ensureEsbuild installs esbuild in the current project if it is not on PATH.
+func ensureEsbuild() error {
+	if _, err := exec.LookPath("esbuild"); err != nil {
+		fmt.Println("📦 Installing esbuild...")
+		installCmd := exec.Command("npm", "install", "-D", "esbuild")
+		installCmd.Stdout = os.Stdout
+		installCmd.Stderr = os.Stderr
+		if err := installCmd.Run(); err != nil {
+			return fmt.Errorf("failed to install esbuild: %v", err)
+		}
+	}
+	return nil
 }
 
-func buildForDev() error {
-	// Create .dev directory if it doesn't exist
-	if err := os.MkdirAll(".dev", 0755); err != nil {
+func buildForDev(ctx context.Context, target devTarget) error {
+	outfile, err := filepath.Abs(target.Outfile)
+	if err != nil {
+		return err
+	}
+
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(outfile), 0755); err != nil {
 		return err
 	}
 
 	// Generate index.ts if needed
+	indexPath := filepath.Join(target.Dir, "index.ts")
 	indexGenerated := false
-	if _, err := os.Stat("index.ts"); err != nil {
-		if err := generateIndexFileForDev(); err != nil {
+	if _, err := os.Stat(indexPath); err != nil {
+		if err := generateIndexFileForDev(target); err != nil {
 			return fmt.Errorf("failed to generate index: %v", err)
 		}
 		indexGenerated = true
 		defer func() {
 			if indexGenerated {
-				os.Remove("index.ts")
+				os.Remove(indexPath)
 			}
 		}()
 	}
 
 	// Bundle with esbuild (handles TypeScript, JSX, and ES modules automatically)
-	cmd := exec.Command("npx", "esbuild",
+	cmd := exec.CommandContext(ctx, "npx", "esbuild",
 		"index.ts",
 		"--bundle",
 		"--format=esm",
@@ -167,12 +227,13 @@ func buildForDev() error {
 		"--jsx=automatic",
 		"--loader:.ts=ts",
 		"--loader:.tsx=tsx",
-		"--outfile=.dev/bundle.js",
+		"--outfile="+outfile,
 		"--sourcemap",
 		"--external:react",
 		"--external:react-dom",
 		"--external:react-dom/client",
 	)
+	cmd.Dir = target.Dir
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -182,19 +243,19 @@ func buildForDev() error {
 	return nil
 }
 
-func generateIndexFileForDev() error {
+func generateIndexFileForDev(target devTarget) error {
 	moduleFile := ""
-	if _, err := os.Stat("Module.ts"); err == nil {
+	if _, err := os.Stat(filepath.Join(target.Dir, "Module.ts")); err == nil {
 		moduleFile = "Module"
-	} else if _, err := os.Stat("Module.tsx"); err == nil {
+	} else if _, err := os.Stat(filepath.Join(target.Dir, "Module.tsx")); err == nil {
 		moduleFile = "Module"
 	} else {
-		return fmt.Errorf("Module.ts or Module.tsx not found")
+		return fmt.Errorf("Module.ts or Module.tsx not found in %s", target.Dir)
 	}
 
 	hasComponent := false
 	componentFile := ""
-	if _, err := os.Stat("Component.tsx"); err == nil {
+	if _, err := os.Stat(filepath.Join(target.Dir, "Component.tsx")); err == nil {
 		hasComponent = true
 		componentFile = "Component"
 	}
@@ -203,8 +264,8 @@ func generateIndexFileForDev() error {
 	indexContent := ""
 
 	if hasComponent {
-		indexContent = fmt.Sprintf(`import ModuleClass from './%s';
-import Component from './%s';
+		indexContent = fmt.Sprintf(`import ModuleClass from './%[1]s';
+import Component from './%[2]s';
 import React from 'react';
 import { createRoot } from 'react-dom/client';
 
@@ -219,13 +280,13 @@ if (typeof window !== 'undefined') {
         
         // Initialize module
         if (window.DashspaceLib) {
-            window.DashspaceLib.autoInitialize(ModuleClass);
+            window.DashspaceLib.autoInitialize(ModuleClass, %[3]q);
         }
         
         // Mount React component
-        const rootElement = document.getElementById('root');
+        const rootElement = document.getElementById(%[4]q);
         if (rootElement) {
-            console.log('Mounting React component to #root');
+            console.log('Mounting React component to #%[4]s');
             try {
                 const root = createRoot(rootElement);
                 root.render(React.createElement(Component));
@@ -246,18 +307,18 @@ if (typeof window !== 'undefined') {
         setTimeout(init, 100); // Small delay to ensure everything is ready
     }
 }
-`, moduleFile, componentFile)
+`, moduleFile, componentFile, target.Slug, target.RootID)
 	} else {
 		indexContent = fmt.Sprintf(`import ModuleClass from './%s';
 export default ModuleClass;
 
 if (typeof window !== 'undefined' && window.DashspaceLib) {
-    window.DashspaceLib.autoInitialize(ModuleClass);
+    window.DashspaceLib.autoInitialize(ModuleClass, %q);
 }
-`, moduleFile)
+`, moduleFile, target.Slug)
 	}
 
-	return os.WriteFile("index.ts", []byte(indexContent), 0644)
+	return os.WriteFile(filepath.Join(target.Dir, "index.ts"), []byte(indexContent), 0644)
 }
 
 func serveDevHTML(w http.ResponseWriter, r *http.Request) {
@@ -325,6 +386,73 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
             border-radius: 0.25rem;
             font-size: 0.875rem;
         }
+        .config-field input[type="checkbox"] {
+            width: 1.25rem;
+            height: 1.25rem;
+        }
+        .config-field .field-description {
+            font-size: 0.75rem;
+            color: #6b7280;
+            margin-top: 0.25rem;
+        }
+        .config-field .field-error {
+            font-size: 0.75rem;
+            color: #dc2626;
+            margin-top: 0.25rem;
+        }
+        .config-field.invalid input, .config-field.invalid select {
+            border-color: #dc2626;
+        }
+        .required-mark {
+            color: #dc2626;
+            margin-left: 0.125rem;
+        }
+        .wizard-steps {
+            display: flex;
+            gap: 0.5rem;
+            max-width: 1200px;
+            margin: 0 auto 1rem auto;
+            flex-wrap: wrap;
+        }
+        .wizard-step {
+            padding: 0.25rem 0.75rem;
+            border-radius: 9999px;
+            background: #e5e7eb;
+            color: #374151;
+            font-size: 0.75rem;
+            cursor: pointer;
+        }
+        .wizard-step.active {
+            background: #6366f1;
+            color: white;
+        }
+        .wizard-step.done {
+            background: #c7d2fe;
+        }
+        .wizard-step-header {
+            max-width: 1200px;
+            margin: 0 auto 1rem auto;
+        }
+        .wizard-step-header p {
+            color: #6b7280;
+            font-size: 0.875rem;
+        }
+        .wizard-actions {
+            display: flex;
+            gap: 0.5rem;
+            max-width: 1200px;
+            margin: 1rem auto 0 auto;
+        }
+        .secondary-btn {
+            margin-top: 1rem;
+            background: #e5e7eb;
+            color: #374151;
+            padding: 0.5rem 1.5rem;
+            border-radius: 0.25rem;
+            border: none;
+            cursor: pointer;
+            font-weight: 500;
+        }
         .apply-btn {
             margin-top: 1rem;
             background: #6366f1;
@@ -359,13 +487,17 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
     
     <div id="config-panel" class="config-panel">
         <div style="max-width: 1200px; margin: 0 auto;">
-            <h3 style="margin: 0 0 1rem 0; font-weight: 600;">Module Configuration</h3>
+            <h3 style="margin: 0 0 1rem 0; font-weight: 600;">Module Setup</h3>
+            <div id="wizard-steps" class="wizard-steps"></div>
+            <div id="wizard-step-header" class="wizard-step-header"></div>
             <div id="config-fields" class="config-grid">
                 <!-- Fields will be dynamically generated here -->
             </div>
-            <button class="apply-btn" onclick="applyConfig()">
-                Apply Configuration
-            </button>
+            <div class="wizard-actions">
+                <button id="wizard-back" class="secondary-btn" onclick="previousStep()">Back</button>
+                <button id="wizard-skip" class="secondary-btn" onclick="skipStep()">Skip</button>
+                <button id="wizard-next" class="apply-btn" onclick="nextStep()">Next</button>
+            </div>
         </div>
     </div>
     
@@ -383,6 +515,8 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
     }
     </script>
     
+    <script>` + devSharedScript + `</script>
+    
     <script>
         // Configuration management
         let currentConfig = {};
@@ -393,67 +527,360 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
             panel.style.display = panel.style.display === 'none' ? 'block' : 'none';
         }
         
-        function renderConfigFields(fields) {
-            const container = document.getElementById('config-fields');
-            container.innerHTML = '';
+        // Setup wizard, built from the manifest extracted by the Go build
+        // (falls back to module.getConfigurationSteps() when extraction fails)
+        let wizardSteps = [];
+        let wizardIndex = 0;
+        
+        const manifestReady = fetch('/__dashspace/manifest')
+            .then(res => res.ok ? res.json() : null)
+            .catch(() => null);
+        
+        function normalizeField(field) {
+            const validation = field.validation || {};
+            return {
+                name: field.name,
+                label: field.label || field.name,
+                type: field.type || 'text',
+                description: field.description,
+                placeholder: field.placeholder,
+                defaultValue: field.defaultValue,
+                required: !!validation.required,
+                pattern: validation.pattern,
+                customMessage: validation.customMessage,
+                min: validation.min !== undefined ? validation.min : field.min,
+                max: validation.max !== undefined ? validation.max : field.max,
+                step: field.step,
+                options: validation.options || field.options || [],
+                multiple: !!field.multiple || field.type === 'multiselect'
+            };
+        }
+        
+        function normalizeSteps(steps) {
+            return (steps || [])
+                .map((step, index) => ({
+                    id: step.id || 'step-' + index,
+                    title: step.title || 'Step ' + (index + 1),
+                    description: step.description,
+                    order: step.order !== undefined ? step.order : index,
+                    optional: !!step.optional,
+                    fields: (step.fields || []).map(normalizeField),
+                    skipped: false
+                }))
+                .sort((a, b) => a.order - b.order);
+        }
+        
+        function setupWizard(manifest, module) {
+            const runtimeSteps = module.getConfigurationSteps ? module.getConfigurationSteps() : [];
+            const extractedSteps = manifest && manifest.configuration_steps;
             
-            fields.forEach(field => {
-                const div = document.createElement('div');
-                div.className = 'config-field';
-                
-                const label = document.createElement('label');
-                label.textContent = field.label || field.name;
-                div.appendChild(label);
-                
-                let input;
-                if (field.type === 'select' || field.type === 'multiselect') {
+            if (extractedSteps && extractedSteps.length) {
+                if (runtimeSteps.length !== extractedSteps.length) {
+                    console.warn('Extracted manifest has ' + extractedSteps.length +
+                        ' configuration steps but the module returns ' + runtimeSteps.length +
+                        ' at runtime - the store will show the extracted ones');
+                }
+                wizardSteps = normalizeSteps(extractedSteps);
+            } else {
+                wizardSteps = normalizeSteps(runtimeSteps);
+            }
+            
+            moduleFields = [];
+            wizardSteps.forEach(step => moduleFields.push(...step.fields));
+            wizardIndex = 0;
+            renderWizard();
+        }
+        
+        function renderWizard() {
+            const stepsContainer = document.getElementById('wizard-steps');
+            const header = document.getElementById('wizard-step-header');
+            stepsContainer.innerHTML = '';
+            header.innerHTML = '';
+            
+            if (!wizardSteps.length) {
+                document.getElementById('config-fields').innerHTML =
+                    '<p style="color: #6b7280;">This module has no configuration steps.</p>';
+                ['wizard-back', 'wizard-skip', 'wizard-next'].forEach(id =>
+                    document.getElementById(id).style.display = 'none');
+                return;
+            }
+            
+            wizardSteps.forEach((step, index) => {
+                const pill = document.createElement('span');
+                pill.className = 'wizard-step' + (index === wizardIndex ? ' active' : index < wizardIndex ? ' done' : '');
+                pill.textContent = (index + 1) + '. ' + step.title + (step.optional ? ' (optional)' : '');
+                pill.onclick = () => {
+                    if (index < wizardIndex || validateStep(wizardSteps[wizardIndex])) {
+                        wizardIndex = index;
+                        renderWizard();
+                    }
+                };
+                stepsContainer.appendChild(pill);
+            });
+            
+            const step = wizardSteps[wizardIndex];
+            const title = document.createElement('h4');
+            title.style.fontWeight = '600';
+            title.textContent = step.title;
+            header.appendChild(title);
+            if (step.description) {
+                const description = document.createElement('p');
+                description.textContent = step.description;
+                header.appendChild(description);
+            }
+            
+            renderConfigFields(step.fields);
+            
+            const isLast = wizardIndex === wizardSteps.length - 1;
+            document.getElementById('wizard-back').style.display = wizardIndex > 0 ? '' : 'none';
+            document.getElementById('wizard-skip').style.display = step.optional ? '' : 'none';
+            document.getElementById('wizard-next').style.display = '';
+            document.getElementById('wizard-next').textContent = isLast ? 'Finish Setup' : 'Next';
+        }
+        
+        function createInput(field) {
+            let input;
+            switch (field.type) {
+                case 'select':
+                case 'multiselect':
                     input = document.createElement('select');
-                    if (field.options) {
-                        field.options.forEach(opt => {
-                            const option = document.createElement('option');
-                            option.value = opt.value;
-                            option.textContent = opt.label;
-                            input.appendChild(option);
-                        });
+                    input.multiple = field.multiple;
+                    if (!field.required && !field.multiple) {
+                        const empty = document.createElement('option');
+                        empty.value = '';
+                        empty.textContent = '—';
+                        input.appendChild(empty);
                     }
-                } else if (field.type === 'boolean') {
+                    field.options.forEach(opt => {
+                        const option = document.createElement('option');
+                        option.value = opt.value;
+                        option.textContent = opt.label || opt.value;
+                        input.appendChild(option);
+                    });
+                    break;
+                case 'boolean':
                     input = document.createElement('input');
                     input.type = 'checkbox';
-                } else if (field.type === 'number') {
+                    break;
+                case 'number':
                     input = document.createElement('input');
                     input.type = 'number';
                     if (field.min !== undefined) input.min = field.min;
                     if (field.max !== undefined) input.max = field.max;
                     if (field.step !== undefined) input.step = field.step;
-                } else {
+                    break;
+                case 'password':
+                case 'url':
+                case 'date':
+                case 'color':
+                case 'email':
+                    input = document.createElement('input');
+                    input.type = field.type;
+                    break;
+                default:
                     input = document.createElement('input');
                     input.type = 'text';
+            }
+            
+            if (field.placeholder && input.tagName === 'INPUT') {
+                input.placeholder = field.placeholder;
+            }
+            if (field.required) {
+                input.required = true;
+            }
+            return input;
+        }
+        
+        function renderConfigFields(fields) {
+            const container = document.getElementById('config-fields');
+            container.innerHTML = '';
+            
+            fields.forEach(field => {
+                const div = document.createElement('div');
+                div.className = 'config-field';
+                div.id = 'config-field-' + field.name;
+                
+                const label = document.createElement('label');
+                label.textContent = field.label;
+                if (field.required) {
+                    const mark = document.createElement('span');
+                    mark.className = 'required-mark';
+                    mark.textContent = '*';
+                    label.appendChild(mark);
                 }
+                div.appendChild(label);
                 
+                const input = createInput(field);
                 input.id = 'config-' + field.name;
-                input.value = currentConfig[field.name] || field.defaultValue || '';
+                
+                const value = currentConfig[field.name] !== undefined ? currentConfig[field.name] : field.defaultValue;
                 if (field.type === 'boolean') {
-                    input.checked = currentConfig[field.name] || field.defaultValue || false;
+                    input.checked = !!value;
+                } else if (field.multiple && Array.isArray(value)) {
+                    Array.from(input.options).forEach(opt => opt.selected = value.includes(opt.value));
+                } else if (value !== undefined && value !== null) {
+                    input.value = value;
                 }
                 
+                input.addEventListener('change', () => validateField(field));
                 div.appendChild(input);
+                
+                if (field.description) {
+                    const description = document.createElement('div');
+                    description.className = 'field-description';
+                    description.textContent = field.description;
+                    div.appendChild(description);
+                }
+                
+                const error = document.createElement('div');
+                error.className = 'field-error';
+                div.appendChild(error);
+                
                 container.appendChild(div);
             });
         }
         
+        function readFieldValue(field) {
+            const input = document.getElementById('config-' + field.name);
+            if (!input) {
+                return currentConfig[field.name];
+            }
+            if (field.type === 'boolean') {
+                return input.checked;
+            }
+            if (field.multiple) {
+                return Array.from(input.selectedOptions).map(opt => opt.value);
+            }
+            if (field.type === 'number') {
+                return input.value === '' ? undefined : Number(input.value);
+            }
+            return input.value;
+        }
+        
+        function fieldError(field, value) {
+            const isEmpty = value === undefined || value === null || value === '' ||
+                (Array.isArray(value) && value.length === 0);
+            
+            if (isEmpty) {
+                return field.required ? field.label + ' is required' : null;
+            }
+            
+            if (field.type === 'number') {
+                if (Number.isNaN(value)) return field.label + ' must be a number';
+                if (field.min !== undefined && value < field.min) return field.label + ' must be at least ' + field.min;
+                if (field.max !== undefined && value > field.max) return field.label + ' must be at most ' + field.max;
+            }
+            
+            if (field.type === 'email' && !/^[^\s@]+@[^\s@]+\.[^\s@]+$/.test(value)) {
+                return field.customMessage || field.label + ' must be a valid email address';
+            }
+            
+            if (field.type === 'url') {
+                try {
+                    new URL(value);
+                } catch (e) {
+                    return field.customMessage || field.label + ' must be a valid URL';
+                }
+            }
+            
+            if (field.type === 'date' && Number.isNaN(Date.parse(value))) {
+                return field.label + ' must be a valid date';
+            }
+            
+            if (field.type === 'color' && !/^#[0-9a-fA-F]{6}$/.test(value)) {
+                return field.label + ' must be a hex color (#rrggbb)';
+            }
+            
+            if (field.pattern) {
+                let pattern;
+                try {
+                    pattern = new RegExp(field.pattern);
+                } catch (e) {
+                    console.warn('Invalid validation pattern for ' + field.name + ':', field.pattern);
+                }
+                if (pattern && !pattern.test(String(value))) {
+                    return field.customMessage || field.label + ' has an invalid format';
+                }
+            }
+            
+            return null;
+        }
+        
+        function validateField(field) {
+            const error = fieldError(field, readFieldValue(field));
+            const container = document.getElementById('config-field-' + field.name);
+            if (container) {
+                container.classList.toggle('invalid', !!error);
+                container.querySelector('.field-error').textContent = error || '';
+            }
+            return !error;
+        }
+        
+        function validateStep(step) {
+            let valid = true;
+            step.fields.forEach(field => {
+                if (!validateField(field)) {
+                    valid = false;
+                }
+            });
+            return valid;
+        }
+        
+        function collectStep(step) {
+            step.fields.forEach(field => {
+                const value = readFieldValue(field);
+                if (value !== undefined) {
+                    currentConfig[field.name] = value;
+                }
+            });
+        }
+        
+        function previousStep() {
+            collectStep(wizardSteps[wizardIndex]);
+            wizardIndex = Math.max(0, wizardIndex - 1);
+            renderWizard();
+        }
+        
+        function skipStep() {
+            const step = wizardSteps[wizardIndex];
+            step.skipped = true;
+            step.fields.forEach(field => delete currentConfig[field.name]);
+            advance();
+        }
+        
+        function nextStep() {
+            const step = wizardSteps[wizardIndex];
+            if (!validateStep(step)) {
+                return;
+            }
+            step.skipped = false;
+            collectStep(step);
+            advance();
+        }
+        
+        function advance() {
+            if (wizardIndex < wizardSteps.length - 1) {
+                wizardIndex++;
+                renderWizard();
+                return;
+            }
+            applyConfig();
+        }
+        
         function applyConfig() {
-            // Collect values from form
+            const missing = wizardSteps.filter(step => !step.optional && step.fields.some(field =>
+                fieldError(field, currentConfig[field.name]) !== null));
+            if (missing.length) {
+                wizardIndex = wizardSteps.indexOf(missing[0]);
+                renderWizard();
+                validateStep(missing[0]);
+                return;
+            }
+            
             const newConfig = {};
             moduleFields.forEach(field => {
-                const input = document.getElementById('config-' + field.name);
-                if (input) {
-                    if (field.type === 'boolean') {
-                        newConfig[field.name] = input.checked;
-                    } else if (field.type === 'number') {
-                        newConfig[field.name] = parseInt(input.value);
-                    } else {
-                        newConfig[field.name] = input.value;
-                    }
+                if (currentConfig[field.name] !== undefined) {
+                    newConfig[field.name] = currentConfig[field.name];
                 }
             });
             
@@ -469,6 +896,8 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
                         
                         // Hide the config panel
                         toggleConfig();
+                        wizardIndex = 0;
+                        renderWizard();
                         
                         // Trigger a re-render by dispatching a custom event
                         window.dispatchEvent(new CustomEvent('configUpdated', { 
@@ -536,18 +965,14 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
             instance: null,
             initialized: false,
             configApplied: false,
+            dataRegistry: createDataRegistry(),
             
             setModule(module) {
                 this.instance = module;
                 
-                // Extract fields from configuration steps for the UI
-                if (!moduleFields.length) {
-                    const steps = module.getConfigurationSteps();
-                    steps.forEach(step => {
-                        if (step.fields) {
-                            moduleFields.push(...step.fields);
-                        }
-                    });
+                // Build the setup wizard from the extracted manifest
+                manifestReady.then(manifest => {
+                    setupWizard(manifest, module);
                     
                     // Set default values if no config saved
                     if (Object.keys(currentConfig).length === 0) {
@@ -564,10 +989,7 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
                             this.configApplied = true;
                         }
                     }
-                    
-                    // Render config fields in UI
-                    renderConfigFields(moduleFields);
-                }
+                });
             },
             
             isSetupCompleted() {
@@ -680,6 +1102,13 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
                 return module;
             },
             
+            dispatchWebhookEvent(event) {
+                console.log('DashspaceLib: webhook received', event.provider + '/' + event.type, event);
+                
+                window.dispatchEvent(new CustomEvent('dashspace:webhook', { detail: event }));
+                dispatchWebhookToModule(this.instance, event);
+            },
+            
             async autoInitialize(ModuleClass) {
                 console.log('DashspaceLib: autoInitialize called');
                 if (typeof window !== 'undefined' && !this.initialized) {
@@ -721,6 +1150,118 @@ func serveDevHTML(w http.ResponseWriter, r *http.Request) {
 	tmpl.Execute(w, nil)
 }
 
+// devSharedScript is loaded by both the single-module and the workspace dev
+// pages: the data registry shared between modules, webhook dispatch and the
+// dev server channel.
+const devSharedScript = `
+        function createDataRegistry() {
+            const providers = new Map();
+            const listeners = new Map();
+            
+            const notify = (id) => {
+                const entry = providers.get(id);
+                [id, '*'].forEach(key => {
+                    (listeners.get(key) || new Set()).forEach(callback => {
+                        try {
+                            callback(entry ? entry.data : undefined, entry);
+                        } catch (err) {
+                            console.error('dataRegistry: subscriber failed:', err);
+                        }
+                    });
+                });
+            };
+            
+            return {
+                register(id, payload, metadata) {
+                    const data = payload && payload.data !== undefined ? payload.data : payload;
+                    providers.set(id, {
+                        id,
+                        data,
+                        schema: payload && payload.schema,
+                        metadata: metadata || {},
+                        updatedAt: Date.now()
+                    });
+                    console.log('dataRegistry: registered', id);
+                    notify(id);
+                },
+                update(id, data) {
+                    const entry = providers.get(id);
+                    if (!entry) {
+                        this.register(id, { data });
+                        return;
+                    }
+                    entry.data = data;
+                    entry.updatedAt = Date.now();
+                    notify(id);
+                },
+                unregister(id) {
+                    providers.delete(id);
+                    notify(id);
+                },
+                get(id) {
+                    return providers.get(id);
+                },
+                getData(id) {
+                    const entry = providers.get(id);
+                    return entry ? entry.data : undefined;
+                },
+                list() {
+                    return Array.from(providers.values());
+                },
+                findByType(type) {
+                    return this.list().filter(entry => entry.metadata.type === type);
+                },
+                subscribe(id, callback) {
+                    if (!listeners.has(id)) {
+                        listeners.set(id, new Set());
+                    }
+                    listeners.get(id).add(callback);
+                    return () => listeners.get(id).delete(callback);
+                }
+            };
+        }
+        
+        function dispatchWebhookToModule(instance, event) {
+            if (!instance) {
+                console.warn('DashspaceLib: webhook received before the module was initialized');
+                return;
+            }
+            
+            if (typeof instance.handleWebhookEvent === 'function') {
+                instance.handleWebhookEvent(event);
+                return;
+            }
+            
+            // Fall back to the handle<Event>Event naming convention
+            const methodName = 'handle' + event.type.split(/[_.]/).map(part =>
+                part.charAt(0).toUpperCase() + part.slice(1)
+            ).join('') + 'Event';
+            
+            if (typeof instance[methodName] === 'function') {
+                Promise.resolve(instance[methodName](event)).catch(err =>
+                    console.error('DashspaceLib: webhook handler failed:', err)
+                );
+            } else {
+                console.warn('DashspaceLib: no handler for webhook event', event.type);
+            }
+        }
+        
+        // Dev server channel (simulated webhooks)
+        (function connectDevChannel() {
+            const protocol = location.protocol === 'https:' ? 'wss://' : 'ws://';
+            const socket = new WebSocket(protocol + location.host + '/__dashspace/ws');
+            
+            socket.onmessage = (message) => {
+                const payload = JSON.parse(message.data);
+                if (payload.type === 'webhook' && payload.event && window.DashspaceLib) {
+                    window.DashspaceLib.dispatchWebhookEvent(payload.event);
+                }
+            };
+            
+            socket.onclose = () => setTimeout(connectDevChannel, 1000);
+        })();
+`
+
 func openBrowser(url string) {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
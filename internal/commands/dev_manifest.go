@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+)
+
+// manifestHandler exposes the manifest the build would extract from Module.ts,
+// so the dev page renders the same setup wizard end users will see.
+func manifestHandler(w http.ResponseWriter, r *http.Request) {
+	parser, err := build.NewModuleParser()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	manifest := map[string]interface{}{}
+	warnings := []string{}
+
+	if metadata, err := parser.ExtractMetadata(); err != nil {
+		warnings = append(warnings, err.Error())
+	} else {
+		manifest["id"] = metadata.ID
+		manifest["slug"] = metadata.Slug
+		manifest["name"] = metadata.Name
+		manifest["version"] = metadata.Version
+		manifest["description"] = metadata.Description
+	}
+
+	if steps, err := parser.ExtractConfigurationSteps(); err != nil {
+		warnings = append(warnings, "configuration steps: "+err.Error())
+	} else {
+		manifest["configuration_steps"] = steps
+		manifest["requires_setup"] = len(steps) > 0
+	}
+
+	if providers, err := parser.ExtractProviders(); err == nil && len(providers) > 0 {
+		manifest["providers"] = providers
+	}
+
+	if webhooks, err := parser.ExtractWebhooks(); err == nil && webhooks != nil {
+		manifest["webhooks"] = webhooks
+	}
+
+	if permissions, err := parser.ExtractPermissions(); err == nil && len(permissions) > 0 {
+		manifest["permissions"] = permissions
+	}
+
+	if len(warnings) > 0 {
+		manifest["warnings"] = warnings
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(manifest)
+}
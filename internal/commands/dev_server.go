@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// devPortAttempts is how many consecutive ports are tried when the requested one is taken.
+const devPortAttempts = 20
+
+// devServerFile records where the running dev server listens, so that
+// 'dashspace webhook send' finds it on a fallback port or another host.
+var devServerFile = filepath.Join(".dev", "server.json")
+
+// devServerInfo is the content of devServerFile.
+type devServerInfo struct {
+	URL string `json:"url"`
+	PID int    `json:"pid"`
+}
+
+// devOptions holds the flags shared by the single module and workspace dev servers.
+type devOptions struct {
+	Host          string
+	Port          string
+	NoOpen        bool
+	HTTPS         bool
+	WebhookSecret string
+}
+
+// devServer is a running dev server bound to a free port.
+type devServer struct {
+	opts     devOptions
+	listener net.Listener
+	URL      string
+}
+
+// listenDev binds the dev server to opts.Host, moving on to the next port
+// when the requested one is already in use.
+func listenDev(opts devOptions) (*devServer, error) {
+	port, err := strconv.Atoi(opts.Port)
+	if err != nil || port < 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid port '%s'", opts.Port)
+	}
+
+	var listener net.Listener
+	for attempt := 0; attempt < devPortAttempts; attempt++ {
+		listener, err = net.Listen("tcp", net.JoinHostPort(opts.Host, strconv.Itoa(port+attempt)))
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EADDRINUSE) || port == 0 {
+			return nil, fmt.Errorf("failed to listen on %s: %v", net.JoinHostPort(opts.Host, strconv.Itoa(port+attempt)), err)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no free port found between %d and %d", port, port+devPortAttempts-1)
+	}
+
+	boundPort := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	if boundPort != opts.Port {
+		fmt.Printf("⚠️  Port %s is in use, using %s instead\n", opts.Port, boundPort)
+	}
+
+	scheme := "http"
+	if opts.HTTPS {
+		scheme = "https"
+	}
+
+	return &devServer{
+		opts:     opts,
+		listener: listener,
+		URL:      fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(displayHost(opts.Host), boundPort)),
+	}, nil
+}
+
+// devContext is cancelled on SIGINT/SIGTERM. It is set up before the first
+// build so Ctrl+C never kills the process halfway through a build.
+func devContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// Serve runs handler until ctx is cancelled, then shuts the server down
+// gracefully and runs cleanup before returning.
+func (s *devServer) Serve(ctx context.Context, handler http.Handler, cleanup func()) error {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if s.opts.HTTPS {
+		cert, err := selfSignedCertificate(s.opts.Host)
+		if err != nil {
+			s.listener.Close()
+			return fmt.Errorf("failed to generate certificate: %v", err)
+		}
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		fmt.Println("🔒 Serving over HTTPS with a self-signed certificate - accept it in your browser")
+	}
+
+	if err := writeDevServerInfo(s.URL); err != nil {
+		fmt.Printf("⚠️  Warning: %v\n", err)
+	}
+	defer removeDevServerInfo()
+
+	if !s.opts.NoOpen {
+		go func() {
+			time.Sleep(1 * time.Second)
+			openBrowser(s.URL)
+		}()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if s.opts.HTTPS {
+			errCh <- server.ServeTLS(s.listener, "", "")
+		} else {
+			errCh <- server.Serve(s.listener)
+		}
+	}()
+
+	var serveErr error
+	select {
+	case serveErr = <-errCh:
+	case <-ctx.Done():
+		fmt.Println("\n👋 Shutting down dev server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		serveErr = server.Shutdown(shutdownCtx)
+	}
+
+	if cleanup != nil {
+		cleanup()
+	}
+
+	if errors.Is(serveErr, http.ErrServerClosed) {
+		return nil
+	}
+	return serveErr
+}
+
+func writeDevServerInfo(url string) error {
+	data, err := json.Marshal(devServerInfo{URL: url, PID: os.Getpid()})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(devServerFile), 0755); err != nil {
+		return fmt.Errorf("failed to record dev server address: %v", err)
+	}
+	if err := os.WriteFile(devServerFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to record dev server address: %v", err)
+	}
+	return nil
+}
+
+// removeDevServerInfo removes devServerFile unless another dev server has
+// replaced it since.
+func removeDevServerInfo() {
+	if info, err := readDevServerInfo(); err == nil && info.PID == os.Getpid() {
+		os.Remove(devServerFile)
+	}
+}
+
+// readDevServerInfo returns the address recorded by the dev server running
+// in the current directory.
+func readDevServerInfo() (*devServerInfo, error) {
+	data, err := os.ReadFile(devServerFile)
+	if err != nil {
+		return nil, err
+	}
+	info := &devServerInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, err
+	}
+	if info.URL == "" {
+		return nil, fmt.Errorf("no address recorded in %s", devServerFile)
+	}
+	return info, nil
+}
+
+// displayHost is the host used in printed and opened URLs.
+func displayHost(host string) string {
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		return "localhost"
+	}
+	return host
+}
+
+// selfSignedCertificate creates an in-memory certificate for localhost and host.
+func selfSignedCertificate(host string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"Dashspace Dev"}, CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(30 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else if host != "" && host != "localhost" {
+		template.DNSNames = append(template.DNSNames, host)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+	"github.com/devlyspace/dashspace-cli/internal/webhooks"
+	"github.com/gorilla/websocket"
+)
+
+// devHub fans dev server messages out to every connected dev page.
+type devHub struct {
+	mu       sync.Mutex
+	clients  map[*websocket.Conn]bool
+	upgrader websocket.Upgrader
+}
+
+type devMessage struct {
+	Type  string          `json:"type"`
+	Event *webhooks.Event `json:"event,omitempty"`
+}
+
+func newDevHub() *devHub {
+	return &devHub{
+		clients: make(map[*websocket.Conn]bool),
+	}
+}
+
+func (h *devHub) handleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	h.clients[conn] = true
+	h.mu.Unlock()
+
+	// Drain incoming frames so close messages are processed.
+	go func() {
+		defer h.remove(conn)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func (h *devHub) remove(conn *websocket.Conn) {
+	h.mu.Lock()
+	delete(h.clients, conn)
+	h.mu.Unlock()
+	conn.Close()
+}
+
+// broadcast sends msg to all connected pages and returns how many received it.
+func (h *devHub) broadcast(msg devMessage) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delivered := 0
+	for conn := range h.clients {
+		if err := conn.WriteJSON(msg); err != nil {
+			delete(h.clients, conn)
+			conn.Close()
+			continue
+		}
+		delivered++
+	}
+	return delivered
+}
+
+// webhookHandler receives simulated deliveries and forwards them to the dev page.
+func webhookHandler(hub *devHub, secret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeWebhookInfo(w)
+		case http.MethodPost:
+			deliverWebhook(w, r, hub, secret)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func writeWebhookInfo(w http.ResponseWriter) {
+	info := map[string]interface{}{}
+
+	if parser, err := build.NewModuleParser(); err == nil {
+		if declared, err := parser.ExtractWebhooks(); err == nil && declared != nil {
+			info["webhooks"] = declared
+			if provider, ok := declared["provider"].(string); ok {
+				info["samples"] = webhooks.SampleEvents(provider)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+func deliverWebhook(w http.ResponseWriter, r *http.Request, hub *devHub, secret string) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	provider := webhooks.DetectProvider(r.Header)
+	if provider == "" {
+		http.Error(w, "unable to detect webhook provider from headers", http.StatusBadRequest)
+		return
+	}
+
+	event, err := webhooks.NewEvent(provider, webhooks.EventName(provider, r.Header), r.Header, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := webhooks.Verify(provider, secret, r.Header, body); err != nil {
+		fmt.Printf("⚠️  Webhook %s/%s signature check failed: %v\n", provider, event.Type, err)
+	} else {
+		event.Verified = true
+	}
+
+	delivered := hub.broadcast(devMessage{Type: "webhook", Event: event})
+	fmt.Printf("🪝 Webhook %s/%s delivered to %d page(s)\n", provider, event.Type, delivered)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":        event.ID,
+		"provider":  provider,
+		"event":     event.Type,
+		"verified":  event.Verified,
+		"delivered": delivered,
+	})
+}
@@ -0,0 +1,668 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+	"github.com/devlyspace/dashspace-cli/internal/modules"
+)
+
+const workspaceFile = "dashspace.workspace.json"
+
+// workspaceModule is one module served by `dashspace dev --workspace`.
+type workspaceModule struct {
+	Dir      string                 `json:"dir"`
+	Slug     string                 `json:"slug"`
+	Name     string                 `json:"name"`
+	Size     *build.WidgetSizes     `json:"size,omitempty"`
+	Bundle   string                 `json:"bundle"`
+	RootID   string                 `json:"rootId"`
+	Webhooks map[string]interface{} `json:"webhooks,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+
+	// Modules installed from the store are prebuilt, not built or watched
+	Installed bool   `json:"installed,omitempty"`
+	ModuleID  int    `json:"moduleId,omitempty"`
+	Version   string `json:"version,omitempty"`
+	entry     string
+}
+
+func (m *workspaceModule) target() devTarget {
+	return devTarget{
+		Dir:     m.Dir,
+		Outfile: filepath.Join(".dev", "workspace", m.Slug, "bundle.js"),
+		RootID:  m.RootID,
+		Slug:    m.Slug,
+	}
+}
+
+func runWorkspaceDevServer(opts devOptions) error {
+	fmt.Println("🚀 Starting Dashspace workspace development server...")
+
+	ctx, stop := devContext()
+	defer stop()
+
+	modules, err := discoverWorkspaceModules(".")
+	if err != nil {
+		return err
+	}
+	if len(modules) == 0 {
+		return fmt.Errorf("no modules found - add %s or run from a directory containing module folders", workspaceFile)
+	}
+
+	fmt.Printf("📦 Found %d modules:\n", len(modules))
+	sources := []*workspaceModule{}
+	for _, module := range modules {
+		if module.Installed {
+			fmt.Printf("   - %s v%s (installed)\n", module.Name, module.Version)
+			continue
+		}
+		fmt.Printf("   - %s (%s)\n", module.Name, module.Dir)
+		sources = append(sources, module)
+	}
+
+	if len(sources) > 0 {
+		if err := ensureEsbuild(); err != nil {
+			return err
+		}
+	}
+
+	var mu sync.Mutex
+	rebuild := func(ctx context.Context, module *workspaceModule) {
+		err := buildForDev(ctx, module.target())
+		if ctx.Err() != nil {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			module.Error = err.Error()
+			fmt.Printf("❌ %s: build failed: %v\n", module.Slug, err)
+			return
+		}
+		module.Error = ""
+		fmt.Printf("✅ %s built\n", module.Slug)
+	}
+
+	for _, module := range sources {
+		if ctx.Err() != nil {
+			return nil
+		}
+		rebuild(ctx, module)
+	}
+
+	server, err := listenDev(opts)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]devTarget, 0, len(sources))
+	byDir := map[string]*workspaceModule{}
+	for _, module := range sources {
+		targets = append(targets, module.target())
+		byDir[module.Dir] = module
+	}
+
+	watchDone := watchDevTargets(ctx, targets, func(ctx context.Context, target devTarget, changed []string) {
+		fmt.Printf("🔄 Rebuilding %s...\n", target.Slug)
+		rebuild(ctx, byDir[target.Dir])
+	})
+
+	mux := http.NewServeMux()
+
+	mux.Handle("/modules/", http.StripPrefix("/modules/", http.FileServer(http.Dir(filepath.Join(".dev", "workspace")))))
+
+	mux.HandleFunc("/installed/", func(w http.ResponseWriter, r *http.Request) {
+		slug, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/installed/"), "/")
+		for _, module := range modules {
+			if module.Installed && module.Slug == slug {
+				http.StripPrefix("/installed/"+slug, http.FileServer(http.Dir(module.Dir))).ServeHTTP(w, r)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+
+	mux.HandleFunc("/__dashspace/workspace", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(map[string]interface{}{"modules": modules})
+	})
+
+	hub := newDevHub()
+	mux.HandleFunc("/__dashspace/ws", hub.handleWS)
+	mux.HandleFunc("/__dashspace/webhooks", webhookHandler(hub, opts.WebhookSecret))
+
+	mux.HandleFunc("/", serveWorkspaceHTML)
+
+	fmt.Printf("\n✨ Workspace server running at %s\n", server.URL)
+	fmt.Println("👀 Watching for file changes...")
+	fmt.Println("\nPress Ctrl+C to stop")
+
+	return server.Serve(ctx, mux, func() {
+		<-watchDone
+	})
+}
+
+// discoverWorkspaceModules lists the modules of a workspace, either from the
+// dashspace.workspace.json file or by looking for Module.ts in subdirectories,
+// followed by the modules installed with `dashspace install`.
+func discoverWorkspaceModules(root string) ([]*workspaceModule, error) {
+	dirs, err := workspaceModuleDirs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := []*workspaceModule{}
+	slugs := map[string]int{}
+
+	for _, dir := range dirs {
+		moduleFile := devModuleFile(dir)
+		if moduleFile == "" {
+			fmt.Printf("⚠️  Skipping %s: Module.ts or Module.tsx not found\n", dir)
+			continue
+		}
+
+		module := &workspaceModule{
+			Dir:  dir,
+			Name: filepath.Base(dir),
+			Slug: strings.ToLower(filepath.Base(dir)),
+		}
+
+		parser := build.NewParser(moduleFile)
+		if metadata, err := parser.ExtractMetadata(); err != nil {
+			fmt.Printf("⚠️  %s: %v\n", dir, err)
+		} else {
+			module.Name = metadata.Name
+			module.Slug = metadata.Slug
+			module.Size = metadata.Size
+		}
+		if webhooks, err := parser.ExtractWebhooks(); err == nil {
+			module.Webhooks = webhooks
+		}
+
+		modules = append(modules, module)
+	}
+
+	installed, err := installedWorkspaceModules(root)
+	if err != nil {
+		return nil, err
+	}
+	modules = append(modules, installed...)
+
+	for _, module := range modules {
+		// Two modules may share a slug (e.g. copies of a template)
+		slugs[module.Slug]++
+		if slugs[module.Slug] > 1 {
+			module.Slug = fmt.Sprintf("%s-%d", module.Slug, slugs[module.Slug])
+		}
+
+		module.RootID = "root-" + module.Slug
+		if module.Installed {
+			module.Bundle = "/installed/" + module.Slug + "/" + filepath.ToSlash(module.entry)
+		} else {
+			module.Bundle = "/modules/" + module.Slug + "/bundle.js"
+		}
+	}
+
+	return modules, nil
+}
+
+// installedWorkspaceModules lists the modules locked in dashspace-lock.json
+// from their unpacked dashspace.json.
+func installedWorkspaceModules(root string) ([]*workspaceModule, error) {
+	lock, err := modules.LoadLockfile(filepath.Join(root, modules.LockfileName))
+	if err != nil {
+		return nil, err
+	}
+
+	installed := []*workspaceModule{}
+	for _, slug := range lock.Slugs() {
+		dir := filepath.Join(root, lock.Dir, slug)
+
+		data, err := os.ReadFile(filepath.Join(dir, "dashspace.json"))
+		if err != nil {
+			fmt.Printf("⚠️  Skipping %s: not installed, run 'dashspace install'\n", slug)
+			continue
+		}
+
+		var manifest struct {
+			ID       int                    `json:"id"`
+			Name     string                 `json:"name"`
+			Version  string                 `json:"version"`
+			Entry    string                 `json:"entry"`
+			Size     *build.WidgetSizes     `json:"size"`
+			Webhooks map[string]interface{} `json:"webhooks"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			fmt.Printf("⚠️  Skipping %s: invalid dashspace.json: %v\n", slug, err)
+			continue
+		}
+
+		module := &workspaceModule{
+			Dir:       dir,
+			Slug:      slug,
+			Name:      manifest.Name,
+			Size:      manifest.Size,
+			Webhooks:  manifest.Webhooks,
+			Installed: true,
+			ModuleID:  manifest.ID,
+			Version:   manifest.Version,
+			entry:     manifest.Entry,
+		}
+		if module.Name == "" {
+			module.Name = slug
+		}
+		if module.ModuleID == 0 {
+			module.ModuleID = lock.Modules[slug].ID
+		}
+		if module.entry == "" || !filepath.IsLocal(module.entry) {
+			module.entry = "bundle.js"
+		}
+		installed = append(installed, module)
+	}
+
+	return installed, nil
+}
+
+func workspaceModuleDirs(root string) ([]string, error) {
+	if data, err := os.ReadFile(filepath.Join(root, workspaceFile)); err == nil {
+		var workspace struct {
+			Modules []string `json:"modules"`
+		}
+		if err := json.Unmarshal(data, &workspace); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", workspaceFile, err)
+		}
+
+		dirs := []string{}
+		for _, pattern := range workspace.Modules {
+			matches, err := filepath.Glob(filepath.Join(root, pattern))
+			if err != nil {
+				return nil, fmt.Errorf("invalid module pattern '%s' in %s: %v", pattern, workspaceFile, err)
+			}
+			dirs = append(dirs, matches...)
+		}
+		return dirs, nil
+	}
+
+	dirs := []string{}
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		if devModuleFile(dir) != "" {
+			dirs = append(dirs, dir)
+			return
+		}
+		if depth == 0 {
+			return
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || strings.HasPrefix(name, ".") || name == "node_modules" || name == modules.DefaultDir || name == "dist" {
+				continue
+			}
+			walk(filepath.Join(dir, name), depth-1)
+		}
+	}
+
+	walk(root, 2)
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+func devModuleFile(dir string) string {
+	for _, name := range []string{"Module.ts", "Module.tsx"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func serveWorkspaceHTML(w http.ResponseWriter, r *http.Request) {
+	htmlTemplate := `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Dashspace Workspace Dev</title>
+    <script src="https://cdn.tailwindcss.com"></script>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            background: #f3f4f6;
+        }
+        .dev-banner {
+            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+            color: white;
+            padding: 1rem;
+            text-align: center;
+            font-weight: 500;
+        }
+        .dashboard-grid {
+            display: grid;
+            grid-template-columns: repeat(12, minmax(0, 1fr));
+            grid-auto-rows: 80px;
+            gap: 1rem;
+            padding: 1rem;
+            max-width: 1600px;
+            margin: 0 auto;
+        }
+        .widget {
+            background: white;
+            border-radius: 0.5rem;
+            box-shadow: 0 1px 3px rgba(0,0,0,0.1);
+            overflow: hidden;
+            display: flex;
+            flex-direction: column;
+        }
+        .widget-header {
+            padding: 0.5rem 1rem;
+            border-bottom: 1px solid #e5e7eb;
+            font-size: 0.875rem;
+            font-weight: 600;
+            display: flex;
+            justify-content: space-between;
+        }
+        .widget-header span {
+            color: #9ca3af;
+            font-weight: 400;
+        }
+        .widget-body {
+            flex: 1;
+            overflow: auto;
+        }
+        .widget-error {
+            padding: 1rem;
+            color: #dc2626;
+            font-size: 0.75rem;
+            white-space: pre-wrap;
+        }
+    </style>
+</head>
+<body>
+    <div class="dev-banner" id="banner">
+        🚀 Dashspace Workspace Mode
+    </div>
+
+    <div id="grid" class="dashboard-grid"></div>
+
+    <script type="importmap">
+    {
+        "imports": {
+            "react": "https://esm.sh/react@18",
+            "react-dom": "https://esm.sh/react-dom@18",
+            "react-dom/client": "https://esm.sh/react-dom@18/client"
+        }
+    }
+    </script>
+
+    <script>` + devSharedScript + `</script>
+
+    <script>
+        let workspaceModules = [];
+
+        function defaultConfigFor(module) {
+            const config = {};
+            (module.getConfigurationSteps ? module.getConfigurationSteps() : []).forEach(step => {
+                (step.fields || []).forEach(field => {
+                    if (field.defaultValue !== undefined) {
+                        config[field.name] = field.defaultValue;
+                    }
+                });
+            });
+            return config;
+        }
+
+        // Mock Dashspace environment
+        window.modly = {
+            config: {
+                get: function(slug) {
+                    return window.DashspaceLib.getConfig(slug);
+                }
+            },
+            providers: {
+                call: async function(provider, request) {
+                    console.log('Provider call:', provider, request);
+
+                    if (provider === 'github' && request.endpoint) {
+                        try {
+                            const response = await fetch('https://api.github.com' + request.endpoint, {
+                                headers: { 'Accept': 'application/vnd.github.v3+json' }
+                            });
+                            return { data: await response.json() };
+                        } catch (err) {
+                            console.error('API call failed:', err);
+                            return { data: [] };
+                        }
+                    }
+
+                    return { data: [] };
+                },
+                isAuthenticated: function() { return false; },
+                list: function() { return []; }
+            }
+        };
+
+        // One DashspaceLib shared by every module, like the real dashboard:
+        // module instances are tracked per slug and share the data registry.
+        window.DashspaceLib = {
+            modules: {},
+            dataRegistry: createDataRegistry(),
+
+            get instance() {
+                const first = Object.values(this.modules)[0];
+                return first ? first.instance : null;
+            },
+
+            entry(slug) {
+                if (slug && this.modules[slug]) {
+                    return this.modules[slug];
+                }
+                return Object.values(this.modules)[0];
+            },
+
+            getConfig(slug) {
+                const entry = this.entry(slug);
+                return entry ? { ...entry.config } : {};
+            },
+
+            isSetupCompleted(slug) {
+                const entry = this.entry(slug);
+                return !!entry && entry.instance.isSetupCompleted() && Object.keys(entry.config).length > 0;
+            },
+
+            async callProvider(provider, options) {
+                const result = await window.modly.providers.call(provider, options);
+                return result.data || result;
+            },
+
+            applyConfiguration(newConfig, slug) {
+                const entry = this.entry(slug);
+                if (!entry) {
+                    return false;
+                }
+                Object.assign(entry.config, newConfig);
+                sessionStorage.setItem('devConfig:' + entry.slug, JSON.stringify(entry.config));
+                entry.instance.setConfig(entry.config);
+                return true;
+            },
+
+            async initializeModule(ModuleClass, config, slug) {
+                if (this.modules[slug]) {
+                    return this.modules[slug].instance;
+                }
+
+                const module = new ModuleClass();
+                const saved = sessionStorage.getItem('devConfig:' + slug);
+                const moduleConfig = saved ? JSON.parse(saved) : defaultConfigFor(module);
+                Object.assign(moduleConfig, config || {});
+
+                this.modules[slug] = { slug, instance: module, config: moduleConfig };
+                module.setConfig(moduleConfig);
+
+                if (module.start) {
+                    await module.start();
+                } else if (module.initialize) {
+                    await module.initialize();
+                }
+
+                console.log('DashspaceLib: ' + slug + ' initialized');
+                return module;
+            },
+
+            async autoInitialize(ModuleClass, slug) {
+                try {
+                    await this.initializeModule(ModuleClass, undefined, slug);
+                } catch (error) {
+                    console.error('DashspaceLib: Failed to initialize ' + slug + ':', error);
+                    throw error;
+                }
+            },
+
+            dispatchWebhookEvent(event) {
+                window.dispatchEvent(new CustomEvent('dashspace:webhook', { detail: event }));
+
+                workspaceModules
+                    .filter(meta => !meta.webhooks || meta.webhooks.provider === event.provider)
+                    .forEach(meta => {
+                        const entry = this.modules[meta.slug];
+                        if (entry && meta.webhooks) {
+                            dispatchWebhookToModule(entry.instance, event);
+                        }
+                    });
+            }
+        };
+    </script>
+
+    <script type="module">
+        // Installed modules are production bundles: a classic script defining
+        // window.__module_<id> whose init(context, deps) returns { Component }.
+        async function loadInstalledModule(meta) {
+            const [React, ReactDOM, ReactDOMClient] = await Promise.all([
+                import('react'),
+                import('react-dom'),
+                import('react-dom/client')
+            ]);
+            const deps = {
+                React: React.default || React,
+                ReactDOM: { ...(ReactDOM.default || ReactDOM), createRoot: ReactDOMClient.createRoot }
+            };
+            window.require = window.require || function(name) {
+                if (name === 'react') {
+                    return deps.React;
+                }
+                if (name === 'react-dom' || name === 'react-dom/client') {
+                    return deps.ReactDOM;
+                }
+                throw new Error('Cannot find module ' + name);
+            };
+
+            await new Promise((resolve, reject) => {
+                const script = document.createElement('script');
+                script.src = meta.bundle;
+                script.onload = resolve;
+                script.onerror = () => reject(new Error('failed to load ' + meta.bundle));
+                document.head.appendChild(script);
+            });
+
+            const factory = window['__module_' + meta.moduleId];
+            if (!factory) {
+                throw new Error(meta.bundle + ' did not define __module_' + meta.moduleId);
+            }
+
+            const result = factory.init({
+                slug: meta.slug,
+                config: window.DashspaceLib.getConfig(meta.slug),
+                modly: window.modly,
+                DashspaceLib: window.DashspaceLib
+            }, deps);
+
+            deps.ReactDOM.createRoot(document.getElementById(meta.rootId))
+                .render(deps.React.createElement(result.Component));
+        }
+
+        const grid = document.getElementById('grid');
+        const response = await fetch('/__dashspace/workspace');
+        const workspace = await response.json();
+        workspaceModules = workspace.modules;
+
+        document.getElementById('banner').textContent =
+            '🚀 Dashspace Workspace Mode - ' + workspaceModules.length + ' modules';
+
+        for (const meta of workspaceModules) {
+            const size = (meta.size && (meta.size.default || meta.size.min)) || { width: 4, height: 4 };
+
+            const widget = document.createElement('div');
+            widget.className = 'widget';
+            widget.style.gridColumn = 'span ' + Math.min(size.width, 12);
+            widget.style.gridRow = 'span ' + size.height;
+
+            const header = document.createElement('div');
+            header.className = 'widget-header';
+            header.textContent = meta.name;
+            const slug = document.createElement('span');
+            slug.textContent = meta.slug + (meta.installed ? ' · v' + meta.version : '') + ' · ' + size.width + '×' + size.height;
+            header.appendChild(slug);
+            widget.appendChild(header);
+
+            const body = document.createElement('div');
+            body.className = 'widget-body';
+            body.id = meta.rootId;
+            widget.appendChild(body);
+            grid.appendChild(widget);
+
+            if (meta.error) {
+                body.innerHTML = '';
+                const error = document.createElement('div');
+                error.className = 'widget-error';
+                error.textContent = meta.error;
+                body.appendChild(error);
+            }
+        }
+
+        for (const meta of workspaceModules) {
+            if (meta.error) {
+                continue;
+            }
+            try {
+                if (meta.installed) {
+                    await loadInstalledModule(meta);
+                } else {
+                    await import(meta.bundle);
+                }
+            } catch (err) {
+                console.error('Failed to load ' + meta.slug + ':', err);
+                document.getElementById(meta.rootId).textContent = 'Failed to load module: ' + err.message;
+            }
+        }
+    </script>
+</body>
+</html>`
+
+	tmpl, err := template.New("workspace").Parse(htmlTemplate)
+	if err != nil {
+		http.Error(w, "Template error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	tmpl.Execute(w, nil)
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+	"github.com/devlyspace/dashspace-cli/internal/webhooks"
+	"github.com/spf13/cobra"
+)
+
+func NewGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate code from the module declarations",
+	}
+
+	cmd.AddCommand(newGenerateWebhookTypesCmd())
+
+	return cmd
+}
+
+func newGenerateWebhookTypesCmd() *cobra.Command {
+	var (
+		output   string
+		provider string
+		events   []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "webhook-types",
+		Short: "Generate TypeScript types for the declared webhook payloads",
+		Long: `Generate TypeScript types for the payloads of the webhook events declared in
+Module.ts (metadata.webhooks), from the schemas bundled with the CLI.
+
+The file declares an interface per event payload and a <Provider>WebhookEvent
+type to use in handlers instead of WebhookEvent:
+
+  import type { GitHubWebhookEvent } from './webhook-types';
+
+  private async handleIssuesEvent(event: GitHubWebhookEvent<'issues'>) {
+    const { issue } = event.data; // GitHubIssue
+  }
+
+Run it again after changing the declared events. Providers with schemas: github,
+gitlab, linear, slack, stripe.
+
+EXAMPLES:
+  dashspace generate webhook-types
+  dashspace generate webhook-types -o src/types/webhooks.ts
+  dashspace generate webhook-types --provider stripe --events invoice.paid,customer.created -o -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generateWebhookTypes(provider, events, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file, - for stdout (defaults to webhook-types.ts next to Module.ts)")
+	cmd.Flags().StringVar(&provider, "provider", "", "Webhook provider (defaults to the one declared in Module.ts)")
+	cmd.Flags().StringSliceVar(&events, "events", nil, "Events to generate types for (defaults to the ones declared in Module.ts)")
+
+	return cmd
+}
+
+func generateWebhookTypes(provider string, events []string, output string) error {
+	moduleDir := "."
+	if parser, err := build.NewModuleParser(); err == nil {
+		moduleDir = filepath.Dir(parser.ModuleFile())
+
+		declared, err := parser.ExtractWebhooks()
+		if err != nil {
+			return fmt.Errorf("failed to read webhooks from Module.ts: %v", err)
+		}
+		if provider == "" {
+			provider, _ = declared["provider"].(string)
+		}
+		if len(events) == 0 {
+			events, _ = declared["events"].([]string)
+		}
+	}
+
+	if provider == "" {
+		return fmt.Errorf("no webhook provider declared in Module.ts, use --provider")
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("no webhook events declared in Module.ts, use --events")
+	}
+
+	schema, err := webhooks.LoadSchema(provider)
+	if err != nil {
+		return err
+	}
+	code, err := schema.GenerateTypeScript(events)
+	if err != nil {
+		return err
+	}
+
+	if output == "-" {
+		fmt.Print(code)
+		return nil
+	}
+	if output == "" {
+		output = filepath.Join(moduleDir, "webhook-types.ts")
+	}
+	if err := os.WriteFile(output, []byte(code), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", output, err)
+	}
+
+	fmt.Printf("✅ Generated %s payload types for %d events in %s\n", schema.Title, len(events), output)
+	if _, unschemaed := schema.CheckEvents(events); len(unschemaed) > 0 {
+		fmt.Printf("⚠️  No bundled schema for %s, their payloads are typed as Record<string, unknown>\n", strings.Join(unschemaed, ", "))
+	}
+	fmt.Printf("💡 Type your handlers with %sWebhookEvent<'%s'>\n", schema.Title, events[0])
+	return nil
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/devlyspace/dashspace-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func NewInfoCmd() *cobra.Command {
+	var (
+		versions   int
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "info <module>",
+		Short: "Show details of a module in the DashSpace store",
+		Long: `Show a store module: versions and changelog, required providers,
+permissions, implemented interfaces and configuration steps.
+
+<module> is the module slug or its numeric ID.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return showModuleInfo(cmd.Context(), args[0], versions, jsonOutput)
+		},
+	}
+
+	cmd.Flags().IntVar(&versions, "versions", 5, "Number of versions to show, 0 for all")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the module as JSON")
+
+	return cmd
+}
+
+func showModuleInfo(ctx context.Context, ref string, maxVersions int, jsonOutput bool) error {
+	client := api.NewClient()
+
+	module, err := client.GetModule(ctx, ref)
+	if errors.Is(err, api.ErrNotFound) {
+		return fmt.Errorf("module '%s' not found, try 'dashspace search %s'", ref, ref)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch module: %v", err)
+	}
+
+	versions, err := client.ListModuleVersions(ctx, module.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch versions: %v", err)
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(struct {
+			*api.ModuleDetails
+			Versions []api.ModuleVersion `json:"versions"`
+		}{module, versions})
+	}
+
+	name := module.DisplayName
+	if name == "" {
+		name = module.Name
+	}
+	fmt.Printf("📦 %s v%s\n", name, module.LatestVersion)
+	if module.Description != "" {
+		fmt.Printf("   %s\n", module.Description)
+	}
+	fmt.Println()
+	fmt.Printf("🆔 ID: %d\n", module.ID)
+	fmt.Printf("📛 Slug: %s\n", module.Slug)
+	fmt.Printf("👤 Author: %s\n", module.Author)
+	if module.Category != "" {
+		fmt.Printf("📂 Category: %s\n", module.Category)
+	}
+	if len(module.Tags) > 0 {
+		fmt.Printf("🏷️  Tags: %s\n", strings.Join(module.Tags, ", "))
+	}
+	fmt.Printf("⬇️  Downloads: %d\n", module.Downloads)
+	if !module.UpdatedAt.IsZero() {
+		fmt.Printf("🕒 Updated: %s\n", module.UpdatedAt.Local().Format("2006-01-02"))
+	}
+	if module.Repository != "" {
+		fmt.Printf("🔗 Repository: %s\n", module.Repository)
+	}
+
+	if len(module.Providers) > 0 {
+		fmt.Println("\n🔌 Providers:")
+		for _, provider := range module.Providers {
+			requirement := "optional"
+			if provider.Required {
+				requirement = "required"
+			}
+			fmt.Printf("   • %s (%s)", provider.Name, requirement)
+			if len(provider.Scopes) > 0 {
+				fmt.Printf(" - scopes: %s", strings.Join(provider.Scopes, ", "))
+			}
+			fmt.Println()
+			if provider.Description != "" {
+				fmt.Printf("     %s\n", provider.Description)
+			}
+		}
+	}
+
+	if len(module.Interfaces) > 0 {
+		fmt.Printf("\n🧩 Interfaces: %s\n", strings.Join(module.Interfaces, ", "))
+	}
+
+	if len(module.Permissions) > 0 {
+		fmt.Println("\n🔐 Permissions:")
+		for _, permission := range module.Permissions {
+			fmt.Printf("   • %s\n", permission)
+		}
+	}
+
+	if module.RequiresSetup {
+		fmt.Printf("\n⚙️  Setup: %d configuration steps\n", len(module.ConfigurationSteps))
+		for i, step := range module.ConfigurationSteps {
+			optional := ""
+			if step.Optional {
+				optional = " (optional)"
+			}
+			fmt.Printf("   %d. %s%s\n", i+1, step.Title, optional)
+			if step.Description != "" {
+				fmt.Printf("      %s\n", step.Description)
+			}
+			for _, field := range step.Fields {
+				label := field.Label
+				if label == "" {
+					label = field.Name
+				}
+				fmt.Printf("      - %s (%s)\n", label, field.Type)
+			}
+		}
+	}
+
+	fmt.Printf("\n📜 Versions (%d):\n", len(versions))
+	shown := versions
+	if maxVersions > 0 && len(shown) > maxVersions {
+		shown = shown[:maxVersions]
+	}
+	for _, version := range shown {
+		fmt.Printf("   v%s", version.Version)
+		if !version.CreatedAt.IsZero() {
+			fmt.Printf("  %s", version.CreatedAt.Local().Format("2006-01-02"))
+		}
+		fmt.Println()
+		for _, line := range strings.Split(strings.TrimSpace(version.Changelog), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Printf("     %s\n", line)
+			}
+		}
+	}
+	if len(shown) < len(versions) {
+		fmt.Printf("   … %d older versions (use --versions 0 to show all)\n", len(versions)-len(shown))
+	}
+
+	return nil
+}
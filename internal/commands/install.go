@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devlyspace/dashspace-cli/internal/api"
+	"github.com/devlyspace/dashspace-cli/internal/modules"
+	"github.com/devlyspace/dashspace-cli/internal/semver"
+	"github.com/spf13/cobra"
+)
+
+func NewInstallCmd() *cobra.Command {
+	var (
+		dir      string
+		force    bool
+		insecure bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install [module[@range]...]",
+		Short: "Install modules from the DashSpace store into the workspace",
+		Long: `Install published modules into dashspace_modules/ and pin them in
+dashspace-lock.json. 'dashspace dev --workspace' runs them next to your own.
+
+A version range may follow the module after '@' (1.2.3, ^1.2, ~1.2.0, 1.x,
+">=1.0.0 <2.0.0"). Without one the latest version is installed and pinned as
+^<version>.
+
+Without arguments, installs exactly the versions locked in dashspace-lock.json.
+Run this in CI and after cloning; commit the lockfile, not dashspace_modules/.
+
+Every archive is checked against the store checksum, its signature when the
+store signed it, and the lockfile integrity when reinstalling. Versions the
+store published without a checksum are refused unless --insecure is given.
+
+EXAMPLES:
+  dashspace install github-pr-widget
+  dashspace install github-pr-widget@^1.2 team-analytics@1.0.3
+  dashspace install
+  dashspace uninstall github-pr-widget`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return installModules(cmd.Context(), args, dir, force, insecure)
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", "", "Directory to install into (default: dashspace_modules, or the one in the lockfile)")
+	cmd.Flags().BoolVar(&force, "force", false, "Download and unpack again even if already installed")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "Install versions the store published without a checksum")
+
+	return cmd
+}
+
+func NewUninstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall <module>...",
+		Short: "Remove installed modules and their lockfile entries",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return uninstallModules(args)
+		},
+	}
+}
+
+func installModules(ctx context.Context, refs []string, dir string, force, insecure bool) error {
+	_, statErr := os.Stat(modules.LockfileName)
+	newLockfile := errors.Is(statErr, os.ErrNotExist)
+
+	lock, err := modules.LoadLockfile(modules.LockfileName)
+	if err != nil {
+		return err
+	}
+	if dir != "" {
+		if err := lock.SetDir(dir); err != nil {
+			return err
+		}
+	}
+
+	installer := modules.NewInstaller(api.NewClient(), lock.Dir)
+	installer.Insecure = insecure
+
+	if len(refs) == 0 {
+		if err := installFromLockfile(ctx, installer, lock, force); err != nil {
+			return err
+		}
+	} else {
+		for _, ref := range refs {
+			if err := installModule(ctx, installer, lock, ref, force); err != nil {
+				return fmt.Errorf("%s: %v", ref, err)
+			}
+			// Keep what was installed so far if a later module fails
+			if err := lock.Save(); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Println()
+	if newLockfile {
+		fmt.Printf("📝 Created %s - commit it and add %s/ to .gitignore\n", modules.LockfileName, lock.Dir)
+	}
+	fmt.Println("💡 Run 'dashspace dev --workspace' to run installed modules next to yours")
+
+	return nil
+}
+
+func installModule(ctx context.Context, installer *modules.Installer, lock *modules.Lockfile, ref string, force bool) error {
+	name, rangeSpec := ref, ""
+	if i := strings.LastIndex(ref, "@"); i > 0 {
+		name, rangeSpec = ref[:i], ref[i+1:]
+	}
+
+	versionRange, err := semver.ParseRange(rangeSpec)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔍 Resolving %s@%s...\n", name, versionRange)
+	module, version, err := installer.Resolve(ctx, name, versionRange)
+	if errors.Is(err, api.ErrNotFound) {
+		return fmt.Errorf("module not found, try 'dashspace search %s'", name)
+	}
+	if err != nil {
+		return err
+	}
+	if rangeSpec == "" {
+		rangeSpec = "^" + version.Version
+	}
+
+	// Reinstalling the locked version must give the same archive
+	integrity := ""
+	if locked := lock.Modules[module.Slug]; locked != nil && locked.VersionID == version.ID {
+		if !force && installer.Installed(module.Slug, locked.Integrity) {
+			locked.Range = rangeSpec
+			fmt.Printf("✅ %s v%s already installed\n", module.Slug, locked.Version)
+			return nil
+		}
+		integrity = locked.Integrity
+	}
+
+	fmt.Printf("⬇️  Downloading %s v%s...\n", module.Slug, version.Version)
+	locked, err := installer.Install(ctx, module.Slug, version, integrity)
+	if err != nil {
+		return err
+	}
+	locked.Range = rangeSpec
+	lock.Modules[module.Slug] = locked
+
+	printInstalled(installer, module.Slug, version, locked)
+	return nil
+}
+
+func installFromLockfile(ctx context.Context, installer *modules.Installer, lock *modules.Lockfile, force bool) error {
+	if len(lock.Modules) == 0 {
+		return fmt.Errorf("no modules in %s, install one with 'dashspace install <module>'", modules.LockfileName)
+	}
+
+	fmt.Printf("📦 Installing %d modules from %s\n", len(lock.Modules), modules.LockfileName)
+
+	for _, slug := range lock.Slugs() {
+		locked := lock.Modules[slug]
+		if !force && installer.Installed(slug, locked.Integrity) {
+			fmt.Printf("✅ %s v%s already installed\n", slug, locked.Version)
+			continue
+		}
+
+		version, err := installer.Locked(ctx, locked)
+		if err != nil {
+			return fmt.Errorf("%s: %v", slug, err)
+		}
+		if locked.SigningKeyID != "" && version.Signature == "" {
+			return fmt.Errorf("%s: v%s was signed when locked but the store now serves it unsigned", slug, locked.Version)
+		}
+
+		fmt.Printf("⬇️  Downloading %s v%s...\n", slug, locked.Version)
+		installed, err := installer.Install(ctx, slug, version, locked.Integrity)
+		if err != nil {
+			return fmt.Errorf("%s: %v", slug, err)
+		}
+		installed.Range = locked.Range
+		lock.Modules[slug] = installed
+
+		printInstalled(installer, slug, version, installed)
+	}
+
+	// The install directory may have changed with --dir
+	return lock.Save()
+}
+
+// printInstalled reports what was verified. locked.SigningKeyID is only set
+// once the signature was checked.
+func printInstalled(installer *modules.Installer, slug string, version *api.ModuleVersion, locked *modules.LockedModule) {
+	switch {
+	case version.SHA256 == "" && locked.SigningKeyID == "":
+		fmt.Printf("⚠️  %s has no store checksum and is not signed, it was not verified\n", slug)
+	case version.SHA256 == "":
+		fmt.Printf("🔐 Signature verified (key %s), the store published no checksum\n", locked.SigningKeyID)
+	case locked.SigningKeyID != "":
+		fmt.Printf("🔐 Checksum and signature verified (key %s)\n", locked.SigningKeyID)
+	default:
+		fmt.Printf("⚠️  %s is not signed, only its checksum was verified\n", slug)
+	}
+	fmt.Printf("✅ Installed %s v%s in %s\n", slug, locked.Version, filepath.Join(installer.Dir, slug))
+}
+
+func uninstallModules(slugs []string) error {
+	lock, err := modules.LoadLockfile(modules.LockfileName)
+	if err != nil {
+		return err
+	}
+	installer := modules.NewInstaller(api.NewClient(), lock.Dir)
+
+	for _, slug := range slugs {
+		if _, ok := lock.Modules[slug]; !ok {
+			return fmt.Errorf("'%s' is not in %s", slug, modules.LockfileName)
+		}
+		if err := installer.Remove(slug); err != nil {
+			return fmt.Errorf("failed to remove %s: %v", slug, err)
+		}
+		delete(lock.Modules, slug)
+		fmt.Printf("🗑️  Removed %s\n", slug)
+	}
+
+	return lock.Save()
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/devlyspace/dashspace-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func NewProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage API environment profiles",
+		Long: `Profiles let you switch between Modly environments (local, staging,
+production). Each profile has its own API URL and its own credentials.
+
+The active profile is, in order: --profile, DASHSPACE_PROFILE, the profile
+selected with 'dashspace profile use', then "default".
+
+EXAMPLES:
+  dashspace profile add staging --api-url https://staging.modly.dashspace.dev
+  dashspace profile use staging
+  dashspace --profile staging publish`,
+	}
+
+	cmd.AddCommand(newProfileAddCmd())
+	cmd.AddCommand(newProfileListCmd())
+	cmd.AddCommand(newProfileUseCmd())
+	cmd.AddCommand(newProfileRemoveCmd())
+
+	return cmd
+}
+
+func newProfileAddCmd() *cobra.Command {
+	var (
+		apiURL string
+		use    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.AddProfile(args[0], apiURL); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Profile '%s' added\n", args[0])
+
+			if use {
+				if err := config.UseProfile(args[0]); err != nil {
+					return err
+				}
+				fmt.Printf("👉 Now using profile '%s'\n", args[0])
+			} else {
+				fmt.Printf("💡 Run 'dashspace --profile %s login' to authenticate\n", args[0])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&apiURL, "api-url", "", "Modly API URL of this profile (defaults to "+config.DefaultAPIBaseURL+")")
+	cmd.Flags().BoolVar(&use, "use", false, "Make it the active profile")
+
+	return cmd
+}
+
+func newProfileListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := config.ProfileNames()
+			active := config.GetConfig().Profile
+
+			if len(names) == 0 {
+				fmt.Printf("* %s (%s)\n", config.DefaultProfile, config.DefaultAPIBaseURL)
+				return nil
+			}
+
+			for _, name := range names {
+				profile := config.GetProfile(name)
+				marker := " "
+				if name == active {
+					marker = "*"
+				}
+
+				apiURL := profile.APIBaseURL
+				if apiURL == "" {
+					apiURL = config.DefaultAPIBaseURL
+				}
+
+				user := ""
+				if profile.Username != "" {
+					user = " - " + profile.Username
+				}
+				fmt.Printf("%s %s (%s)%s\n", marker, name, apiURL, user)
+			}
+			return nil
+		},
+	}
+}
+
+func newProfileUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Set the active profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.UseProfile(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("👉 Now using profile '%s'\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newProfileRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <name>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a profile and its saved credentials",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.RemoveProfile(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Profile '%s' removed\n", args[0])
+			return nil
+		},
+	}
+}
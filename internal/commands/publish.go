@@ -3,25 +3,31 @@ package commands
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/devlyspace/dashspace-cli/internal/commands/build"
 	"io"
-	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
 
+	"github.com/devlyspace/dashspace-cli/internal/api"
+	"github.com/devlyspace/dashspace-cli/internal/config"
+	"github.com/devlyspace/dashspace-cli/internal/credentials"
+	"github.com/devlyspace/dashspace-cli/internal/semver"
 	"github.com/spf13/cobra"
 )
 
 func NewPublishCmd() *cobra.Command {
 	var (
-		dryRun   bool
-		buildDir string
-		signKey  string
-		moduleID int
-		modlyURL string
+		dryRun        bool
+		skipDataCheck bool
+		buildDir      string
+		signKey       string
+		moduleID      int
+		modlyURL      string
+		token         string
 	)
 
 	cmd := &cobra.Command{
@@ -29,22 +35,51 @@ func NewPublishCmd() *cobra.Command {
 		Short: "Publish module to Dashspace store",
 		Long:  "Build and publish a module to the Dashspace store",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return publishModule(dryRun, buildDir, signKey, moduleID, modlyURL)
+			if modlyURL == "" {
+				modlyURL = config.GetConfig().APIBaseURL
+			}
+			return publishModule(cmd.Context(), dryRun, skipDataCheck, buildDir, signKey, moduleID, modlyURL, token)
 		},
 	}
 
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Simulate without actual publishing")
+	cmd.Flags().BoolVar(&skipDataCheck, "skip-data-check", false, "Publish even if the data schema compatibility check cannot run")
 	cmd.Flags().StringVar(&buildDir, "build-dir", "dist", "Build directory containing the module")
 	cmd.Flags().StringVarP(&signKey, "key", "k", "", "Signature key for module signing")
 	cmd.Flags().IntVarP(&moduleID, "module-id", "m", 0, "Override module ID (uses ID from dashspace.json by default)")
-	cmd.Flags().StringVar(&modlyURL, "url", "http://localhost:10001", "Modly API URL")
+	cmd.Flags().StringVar(&modlyURL, "url", "", "Modly API URL (defaults to the active profile's API URL)")
+	cmd.Flags().StringVar(&token, "token", "", "Access token for --url when it is not the profile's API URL (env: "+credentials.TokenEnvVar+")")
 
 	return cmd
 }
 
-func publishModule(dryRun bool, buildDir string, signKey string, overrideModuleID int, modlyURL string) error {
+func publishModule(ctx context.Context, dryRun bool, skipDataCheck bool, buildDir string, signKey string, overrideModuleID int, modlyURL string, token string) error {
 	fmt.Println("📦 Publishing module to Dashspace...")
 
+	// The profile's session only goes to the profile's API URL
+	if token == "" && !api.SameServer(modlyURL, config.GetConfig().APIBaseURL) {
+		token = os.Getenv(credentials.TokenEnvVar)
+		if token == "" && !dryRun {
+			return fmt.Errorf("%s is not the API URL of the active profile: pass --token or set %s to publish there", modlyURL, credentials.TokenEnvVar)
+		}
+	}
+
+	opts := []api.Option{api.WithTimeout(config.GetConfig().Timeout)}
+	if token != "" {
+		opts = append(opts, api.WithToken(token))
+	}
+	client := api.NewClientWithBaseURL(modlyURL, opts...)
+
+	// Fail before building when there is nothing to authenticate the upload with
+	if !dryRun && token == "" {
+		if err := config.LoadCredentials(); err != nil {
+			return err
+		}
+		if config.GetConfig().AuthToken == "" {
+			return fmt.Errorf("not logged in: run 'dashspace login', or set %s in CI", credentials.TokenEnvVar)
+		}
+	}
+
 	if _, err := os.Stat(buildDir); err != nil {
 		fmt.Println("❌ Build directory not found. Running build first...")
 		buildCmd := build.NewBuildCmd()
@@ -102,6 +137,10 @@ func publishModule(dryRun bool, buildDir string, signKey string, overrideModuleI
 		}
 	}
 
+	if err := checkDataCompatibility(ctx, client, moduleID, dashspaceData, fmt.Sprint(dashspaceConfig["version"]), dryRun || skipDataCheck); err != nil {
+		return err
+	}
+
 	if dryRun {
 		fmt.Println("\n🔍 Dry-run mode - no actual publishing")
 		fmt.Println("Files to be published:")
@@ -140,7 +179,7 @@ func publishModule(dryRun bool, buildDir string, signKey string, overrideModuleI
 
 	fmt.Printf("⬆️  Uploading to %s...\n", modlyURL)
 
-	responseData, err := uploadToModly(modlyURL, moduleID, zipPath, dashspaceConfig)
+	version, err := uploadToModly(ctx, client, modlyURL, moduleID, zipPath, dashspaceConfig)
 	if err != nil {
 		return fmt.Errorf("upload failed: %v", err)
 	}
@@ -149,12 +188,9 @@ func publishModule(dryRun bool, buildDir string, signKey string, overrideModuleI
 	fmt.Printf("🆔 Module ID: %d\n", moduleID)
 	fmt.Printf("📛 Module Slug: %s\n", dashspaceConfig["slug"])
 	fmt.Printf("📦 Version: %s\n", dashspaceConfig["version"])
+	fmt.Printf("📦 Version ID: %d\n", version.ID)
 
-	if versionId, ok := responseData["id"]; ok {
-		fmt.Printf("📦 Version ID: %v\n", versionId)
-	}
-
-	if requiresSetup, ok := responseData["requires_setup"].(bool); ok && requiresSetup {
+	if version.RequiresSetup {
 		fmt.Printf("⚙️  Setup Required: Yes\n")
 	}
 
@@ -163,6 +199,103 @@ func publishModule(dryRun bool, buildDir string, signKey string, overrideModuleI
 	return nil
 }
 
+// checkDataCompatibility compares the data schema of the build with the one
+// of the latest published version before it. Breaking changes require a
+// major release (a minor one before 1.0.0). When the check cannot run, the
+// publish fails unless lenient is set (--dry-run or --skip-data-check).
+func checkDataCompatibility(ctx context.Context, client *api.Client, moduleID int, manifest []byte, version string, lenient bool) error {
+	unchecked := func(err error) error {
+		if lenient {
+			fmt.Printf("⚠️  Warning: could not check data schema compatibility: %v\n", err)
+			return nil
+		}
+		return fmt.Errorf("could not check data schema compatibility: %v (use --skip-data-check to publish anyway)", err)
+	}
+
+	current, err := build.ParseManifestDataSchema(manifest)
+	if err != nil {
+		return err
+	}
+	next, err := semver.Parse(version)
+	if err != nil {
+		return unchecked(err)
+	}
+
+	versions, err := client.ListModuleVersions(ctx, moduleID)
+	if err != nil {
+		return unchecked(err)
+	}
+
+	var previous *api.ModuleVersion
+	var previousVersion semver.Version
+	for i := range versions {
+		v, err := semver.Parse(versions[i].Version)
+		if err != nil || v.Compare(next) >= 0 {
+			continue
+		}
+		if previous == nil || v.Compare(previousVersion) > 0 {
+			previous, previousVersion = &versions[i], v
+		}
+	}
+	if previous == nil {
+		return nil
+	}
+
+	archive, err := client.DownloadModuleVersion(ctx, moduleID, previous.ID)
+	if err != nil {
+		return unchecked(fmt.Errorf("v%s: %v", previousVersion, err))
+	}
+	previousManifest, err := archiveFile(archive, "dashspace.json")
+	if err != nil {
+		return unchecked(fmt.Errorf("v%s: %v", previousVersion, err))
+	}
+	old, err := build.ParseManifestDataSchema(previousManifest)
+	if err != nil {
+		return unchecked(fmt.Errorf("v%s: %v", previousVersion, err))
+	}
+
+	changes := build.DiffDataSchemas(old, current)
+	if len(changes) == 0 {
+		if current != nil {
+			fmt.Printf("✅ Data schema unchanged since v%s\n", previousVersion)
+		}
+		return nil
+	}
+
+	fmt.Printf("🔍 Data schema changes since v%s:\n", previousVersion)
+	printDataSchemaChanges(changes)
+
+	major := next.Major > previousVersion.Major || (next.Major == 0 && next.Minor > previousVersion.Minor)
+	if len(build.BreakingChanges(changes)) > 0 && !major {
+		required := semver.Version{Major: previousVersion.Major + 1}
+		if previousVersion.Major == 0 {
+			required = semver.Version{Minor: previousVersion.Minor + 1}
+		}
+		return fmt.Errorf("v%s has breaking data schema changes since v%s: release them in a new major version (v%s)", next, previousVersion, required)
+	}
+	return nil
+}
+
+// archiveFile returns the content of a file of a module archive.
+func archiveFile(archive []byte, name string) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %v", err)
+	}
+	for _, file := range reader.File {
+		if file.Name != name {
+			continue
+		}
+		content, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %v", err)
+		}
+		defer content.Close()
+		return io.ReadAll(content)
+	}
+	return nil, fmt.Errorf("%s not found in the archive", name)
+}
+
 func createModuleZip(buildDir string, config map[string]interface{}) (string, error) {
 	slug := config["slug"].(string)
 	version := config["version"].(string)
@@ -219,80 +352,29 @@ func createModuleZip(buildDir string, config map[string]interface{}) (string, er
 	return zipPath, nil
 }
 
-func uploadToModly(modlyURL string, moduleID int, zipPath string, dashspaceConfig map[string]interface{}) (map[string]interface{}, error) {
-	file, err := os.Open(zipPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open zip file: %v", err)
-	}
-	defer file.Close()
-
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	part, err := writer.CreateFormFile("file", filepath.Base(zipPath))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %v", err)
-	}
-
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy file: %v", err)
-	}
+func uploadToModly(ctx context.Context, client *api.Client, modlyURL string, moduleID int, zipPath string, dashspaceConfig map[string]interface{}) (*api.ModuleVersion, error) {
+	fields := map[string]string{}
 
 	if metadata, err := json.Marshal(dashspaceConfig); err == nil {
-		if err := writer.WriteField("metadata", string(metadata)); err != nil {
-			fmt.Printf("⚠️  Warning: Failed to add metadata field: %v\n", err)
-		}
+		fields["metadata"] = string(metadata)
 	}
 
 	if requiresSetup, ok := dashspaceConfig["requires_setup"].(bool); ok && requiresSetup {
-		if err := writer.WriteField("requires_setup", "true"); err != nil {
-			fmt.Printf("⚠️  Warning: Failed to add requires_setup field: %v\n", err)
-		}
+		fields["requires_setup"] = "true"
 
 		if configSteps, ok := dashspaceConfig["configuration_steps"]; ok {
 			if configJSON, err := json.Marshal(configSteps); err == nil {
-				if err := writer.WriteField("configuration_steps", string(configJSON)); err != nil {
-					fmt.Printf("⚠️  Warning: Failed to add configuration_steps field: %v\n", err)
-				}
+				fields["configuration_steps"] = string(configJSON)
 			}
 		}
 	}
 
-	err = writer.Close()
-	if err != nil {
-		return nil, fmt.Errorf("failed to close writer: %v", err)
+	version, err := client.UploadModuleVersion(ctx, moduleID, zipPath, fields)
+	if errors.Is(err, api.ErrConflict) {
+		return nil, fmt.Errorf("version %v already exists, bump the version in Module.ts", dashspaceConfig["version"])
 	}
-
-	url := fmt.Sprintf("%s/modules/%d/module_versions/upload", modlyURL, moduleID)
-	req, err := http.NewRequest("POST", url, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+	if errors.Is(err, api.ErrNotFound) {
+		return nil, fmt.Errorf("module %d not found on %s, check the ID in Module.ts or use -m", moduleID, modlyURL)
 	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	responseBody, _ := io.ReadAll(resp.Body)
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(responseBody))
-	}
-
-	fmt.Printf("📡 Server response: %s\n", resp.Status)
-
-	var result map[string]interface{}
-	if len(responseBody) > 0 {
-		if err := json.Unmarshal(responseBody, &result); err == nil {
-			return result, nil
-		}
-	}
-
-	return map[string]interface{}{}, nil
+	return version, err
 }
@@ -1,49 +1,129 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/devlyspace/dashspace-cli/internal/api"
 	"github.com/spf13/cobra"
 )
 
 func NewSearchCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		opts       api.SearchOptions
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
 		Use:   "search [query]",
 		Short: "Search modules in DashSpace store",
-		Args:  cobra.ExactArgs(1),
+		Long: `Search modules in the DashSpace store. Without a query, lists modules
+matching the filters.
+
+EXAMPLES:
+  dashspace search github
+  dashspace search --provider github --interface ISearchable
+  dashspace search --category analytics --sort downloads --limit 50
+  dashspace search issues --page 2 --json`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			query := args[0]
-			return searchModules(query)
+			if len(args) == 1 {
+				opts.Query = args[0]
+			}
+			if opts.Sort != "" && opts.Sort != api.SortDownloads && opts.Sort != api.SortUpdated {
+				return fmt.Errorf("invalid sort '%s' (use %s or %s)", opts.Sort, api.SortDownloads, api.SortUpdated)
+			}
+			if opts.Page < 1 {
+				return fmt.Errorf("--page must be 1 or more")
+			}
+			if opts.Limit < 1 || opts.Limit > 100 {
+				return fmt.Errorf("--limit must be between 1 and 100")
+			}
+			return searchModules(cmd.Context(), opts, jsonOutput)
 		},
 	}
+
+	cmd.Flags().StringVar(&opts.Category, "category", "", "Only modules in this category")
+	cmd.Flags().StringSliceVar(&opts.Tags, "tag", nil, "Only modules with this tag (repeatable)")
+	cmd.Flags().StringVar(&opts.Provider, "provider", "", "Only modules using this provider (e.g. github)")
+	cmd.Flags().StringVar(&opts.Interface, "interface", "", "Only modules implementing this interface (e.g. ISearchable)")
+	cmd.Flags().StringVar(&opts.Sort, "sort", "", "Sort by downloads or updated (default: relevance)")
+	cmd.Flags().IntVar(&opts.Page, "page", 1, "Page of results")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 20, "Results per page (max 100)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print results as JSON")
+
+	return cmd
 }
 
-func searchModules(query string) error {
-	fmt.Printf("🔍 Searching modules: %s\n\n", query)
+func searchModules(ctx context.Context, opts api.SearchOptions, jsonOutput bool) error {
+	if !jsonOutput {
+		if opts.Query != "" {
+			fmt.Printf("🔍 Searching modules: %s\n\n", opts.Query)
+		} else {
+			fmt.Printf("🔍 Browsing modules\n\n")
+		}
+	}
 
 	client := api.NewClient()
-	modules, err := client.SearchModules(query)
+	result, err := client.SearchModules(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("search error: %v", err)
 	}
 
-	if len(modules) == 0 {
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	if len(result.Items) == 0 {
+		if opts.Page > 1 && result.Total > 0 {
+			fmt.Printf("❌ No modules on page %d (%d pages)\n", opts.Page, result.Pages())
+			return nil
+		}
 		fmt.Println("❌ No modules found")
 		return nil
 	}
 
-	fmt.Printf("📦 %d modules found:\n\n", len(modules))
+	fmt.Printf("📦 %d modules found:\n\n", result.Total)
 
-	for _, module := range modules {
-		fmt.Printf("• %s v%s\n", module.Name, module.Version)
+	for _, module := range result.Items {
+		fmt.Printf("• %s v%s", module.Name, module.Version)
+		if module.Slug != "" && module.Slug != module.Name {
+			fmt.Printf(" (%s)", module.Slug)
+		}
+		fmt.Println()
 		fmt.Printf("  %s\n", module.Description)
-		fmt.Printf("  👤 %s\n", module.Author)
+
+		details := []string{"👤 " + module.Author}
+		if module.Category != "" {
+			details = append(details, "📂 "+module.Category)
+		}
+		if module.Downloads > 0 {
+			details = append(details, fmt.Sprintf("⬇️  %d", module.Downloads))
+		}
+		if !module.UpdatedAt.IsZero() {
+			details = append(details, "🕒 "+module.UpdatedAt.Local().Format("2006-01-02"))
+		}
+		fmt.Printf("  %s\n", strings.Join(details, "  "))
+
 		if len(module.Tags) > 0 {
 			fmt.Printf("  🏷️  Tags: %v\n", module.Tags)
 		}
 		fmt.Println()
 	}
 
+	if pages := result.Pages(); pages > 1 {
+		fmt.Printf("📄 Page %d/%d", result.Page, pages)
+		if result.Page < pages {
+			fmt.Printf(" - next: --page %d", result.Page+1)
+		}
+		fmt.Println()
+	}
+	fmt.Println("💡 Run 'dashspace info <module>' for details")
+
 	return nil
 }
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+	"github.com/spf13/cobra"
+)
+
+func NewTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Run module checks that execute the module's code",
+	}
+
+	cmd.AddCommand(newTestInterfacesCmd())
+
+	return cmd
+}
+
+func newTestInterfacesCmd() *cobra.Command {
+	var opts build.InterfaceTestOptions
+
+	cmd := &cobra.Command{
+		Use:   "interfaces [interface...]",
+		Short: "Call the interface handlers and check what they return",
+		Long: `Bundle Component.tsx, render it in Node and call the handlers it passes to
+useModuleInterfaces for each interface declared in Module.ts.
+
+Each method is called with generated inputs and must not throw. Results are
+checked against the shapes the dashboard expects: search result items, export
+formats, UQL capabilities... Methods with side effects outside the module
+(export downloads, scheduling) are skipped.
+
+react and react-dom must be installed in the project (npm install). Provider
+calls return empty data.
+
+EXAMPLES:
+  dashspace test interfaces
+  dashspace test interfaces ISearchable
+  dashspace test interfaces --timeout 30s --logs`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Interfaces = args
+
+			ctx, stop := devContext()
+			defer stop()
+			return build.RunInterfaceTests(ctx, opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opts.CallTimeout, "timeout", build.DefaultCallTimeout, "Timeout of each handler call")
+	cmd.Flags().BoolVar(&opts.ShowLogs, "logs", false, "Print the module's console output")
+
+	return cmd
+}
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devlyspace/dashspace-cli/internal/api"
+	"github.com/devlyspace/dashspace-cli/internal/credentials"
+	"github.com/spf13/cobra"
+)
+
+func NewTokensCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Manage personal access tokens for CI",
+		Long: `Personal access tokens authenticate CI pipelines and scripts, where no one
+can type a password or open the app. Give them the smallest scope that works
+and an expiry.
+
+Use a token with DASHSPACE_TOKEN, or save it with 'dashspace login --token-stdin'.
+
+EXAMPLES:
+  dashspace tokens create --name github-actions --scope publish --expires 30d
+  dashspace tokens list
+  dashspace tokens revoke 42`,
+	}
+
+	cmd.AddCommand(newTokensCreateCmd())
+	cmd.AddCommand(newTokensListCmd())
+	cmd.AddCommand(newTokensRevokeCmd())
+
+	return cmd
+}
+
+func newTokensCreateCmd() *cobra.Command {
+	var (
+		name    string
+		scopes  []string
+		expires string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a personal access token",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			expiresIn, err := parseTokenExpiry(expires)
+			if err != nil {
+				return err
+			}
+			if name == "" {
+				hostname, _ := os.Hostname()
+				name = fmt.Sprintf("dashspace-cli@%s", hostname)
+			}
+
+			client := api.NewClient()
+			token, err := client.CreateAccessToken(cmd.Context(), name, scopes, expiresIn)
+			if err != nil {
+				return fmt.Errorf("failed to create token: %v", err)
+			}
+
+			fmt.Printf("✅ Token '%s' created (ID %d)\n", token.Name, token.ID)
+			fmt.Printf("   Scopes: %s\n", strings.Join(token.Scopes, ", "))
+			if token.ExpiresAt != nil {
+				fmt.Printf("   Expires: %s\n", token.ExpiresAt.Local().Format(time.RFC1123))
+			} else {
+				fmt.Println("   Expires: never")
+			}
+			fmt.Printf("\n%s\n\n", token.Token)
+			fmt.Println("⚠️  Copy it now, it won't be shown again")
+			fmt.Printf("💡 In CI, set %s to this token\n", credentials.TokenEnvVar)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Name to recognize the token by (defaults to dashspace-cli@<hostname>)")
+	cmd.Flags().StringSliceVar(&scopes, "scope", []string{api.ScopePublish}, "Scopes granted: "+strings.Join([]string{api.ScopeRead, api.ScopePublish, api.ScopeTokens}, ", "))
+	cmd.Flags().StringVar(&expires, "expires", "30d", "Lifetime, e.g. 12h, 30d, 8w, or 'never'")
+
+	return cmd
+}
+
+func newTokensListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List personal access tokens",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := api.NewClient()
+			tokens, err := client.ListAccessTokens(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list tokens: %v", err)
+			}
+
+			if len(tokens) == 0 {
+				fmt.Println("No personal access tokens")
+				fmt.Println("💡 Create one with 'dashspace tokens create'")
+				return nil
+			}
+
+			for _, token := range tokens {
+				fmt.Printf("• %d  %s", token.ID, token.Name)
+				if token.Prefix != "" {
+					fmt.Printf(" (%s…)", token.Prefix)
+				}
+				fmt.Println()
+				fmt.Printf("  Scopes: %s\n", strings.Join(token.Scopes, ", "))
+				fmt.Printf("  Created: %s\n", token.CreatedAt.Local().Format("2006-01-02"))
+
+				switch {
+				case token.ExpiresAt == nil:
+					fmt.Println("  Expires: never")
+				case token.ExpiresAt.Before(time.Now()):
+					fmt.Printf("  Expired: %s\n", token.ExpiresAt.Local().Format("2006-01-02"))
+				default:
+					fmt.Printf("  Expires: %s\n", token.ExpiresAt.Local().Format("2006-01-02"))
+				}
+
+				if token.LastUsedAt != nil {
+					fmt.Printf("  Last used: %s\n", token.LastUsedAt.Local().Format("2006-01-02 15:04"))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newTokensRevokeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke a personal access token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid token ID '%s' (see 'dashspace tokens list')", args[0])
+			}
+
+			client := api.NewClient()
+			err = client.RevokeAccessToken(cmd.Context(), id)
+			if errors.Is(err, api.ErrNotFound) {
+				return fmt.Errorf("no token with ID %d (see 'dashspace tokens list')", id)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to revoke token: %v", err)
+			}
+
+			fmt.Printf("✅ Token %d revoked\n", id)
+			return nil
+		},
+	}
+}
+
+// parseTokenExpiry accepts Go durations plus days (30d) and weeks (8w).
+// "never" and "0" mean no expiry.
+func parseTokenExpiry(value string) (time.Duration, error) {
+	switch value {
+	case "never", "0", "":
+		return 0, nil
+	}
+
+	if unit := value[len(value)-1]; unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n > 0 {
+			days := n
+			if unit == 'w' {
+				days *= 7
+			}
+			return time.Duration(days) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+
+	return 0, fmt.Errorf("invalid expiry '%s' (e.g. 12h, 30d, 8w or never)", value)
+}
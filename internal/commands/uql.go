@@ -0,0 +1,443 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+	"github.com/devlyspace/dashspace-cli/internal/uql"
+	"github.com/spf13/cobra"
+)
+
+// uqlInterfaces are the interfaces translating UQL queries, in the order
+// they are picked.
+var uqlInterfaces = []string{"ISearchable", "IFilterable"}
+
+func NewUQLCmd() *cobra.Command {
+	var (
+		iface      string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "uql <query>",
+		Short: "Check a UQL query and show the module's translation",
+		Long: `Parse a UQL query, check it against the module's getUQLCapabilities and run it
+through its translateUQLQuery handler, in Node like 'dashspace test interfaces'.
+
+A query mixes free text, filters (field operator value, joined by 'and'),
+'sort by field [asc|desc]' and 'limit n'. Operators: =, !=, >, >=, <, <=,
+contains, startswith, endswith, in (a, b), not in (a, b).
+
+EXAMPLES:
+  dashspace uql 'status = open sort by updated desc limit 20'
+  dashspace uql '"login bug" labels in (bug, "needs triage")' --interface IFilterable
+  dashspace uql 'author = octocat' --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := devContext()
+			defer stop()
+			return translateUQL(ctx, args[0], iface, jsonOutput)
+		},
+	}
+
+	cmd.Flags().StringVar(&iface, "interface", "", "Interface whose handlers translate the query (default: ISearchable, then IFilterable)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print only the translated request as JSON")
+
+	cmd.AddCommand(newUQLTestCmd())
+
+	return cmd
+}
+
+func newUQLTestCmd() *cobra.Command {
+	var (
+		iface  string
+		update bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test [dir]",
+		Short: "Compare query translations with golden files",
+		Long: `Translate every <name>.uql query of a directory (default: uql) and compare the
+result with <name>.golden.json. Lines starting with # in .uql files are comments.
+
+A golden file holds {"request": ...}, the translation, or {"errors": [...]} for
+queries the module must reject. --update rewrites the golden files with the
+current results: review them before committing.
+
+EXAMPLES:
+  dashspace uql test
+  dashspace uql test testdata/uql --update`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "uql"
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			ctx, stop := devContext()
+			defer stop()
+			return testUQLGoldens(ctx, dir, iface, update)
+		},
+	}
+
+	cmd.Flags().StringVar(&iface, "interface", "", "Interface whose handlers translate the queries (default: ISearchable, then IFilterable)")
+	cmd.Flags().BoolVar(&update, "update", false, "Rewrite the golden files with the current results")
+
+	return cmd
+}
+
+// uqlRunner translates queries through a module's handlers.
+type uqlRunner struct {
+	harness      *build.Harness
+	iface        string
+	capabilities *uql.Capabilities
+}
+
+func startUQLRunner(ctx context.Context, iface string) (*uqlRunner, error) {
+	harness, err := build.StartComponentHarness(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &uqlRunner{harness: harness, iface: iface, capabilities: &uql.Capabilities{}}
+	if r.iface == "" {
+		for _, candidate := range uqlInterfaces {
+			if containsMethod(harness.Interfaces[candidate], "translateUQLQuery") {
+				r.iface = candidate
+				break
+			}
+		}
+	}
+	if r.iface == "" {
+		harness.Close()
+		return nil, fmt.Errorf("no handler implements translateUQLQuery (checked %s)", strings.Join(uqlInterfaces, ", "))
+	}
+	if !containsMethod(harness.Interfaces[r.iface], "translateUQLQuery") {
+		harness.Close()
+		return nil, fmt.Errorf("%s handler does not implement translateUQLQuery", r.iface)
+	}
+
+	if !containsMethod(harness.Interfaces[r.iface], "getUQLCapabilities") {
+		fmt.Printf("⚠️  Warning: %s has no getUQLCapabilities, queries are not checked\n", r.iface)
+		return r, nil
+	}
+	result, err := harness.Call(r.iface, "getUQLCapabilities")
+	if err == nil && !result.OK {
+		err = fmt.Errorf("getUQLCapabilities threw: %s", result.Error)
+	}
+	if err == nil {
+		r.capabilities, err = uql.ParseCapabilities(result.Value)
+	}
+	if err != nil {
+		harness.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// translate checks q against the capabilities and translates it. Rejected
+// queries return their errors and no request.
+func (r *uqlRunner) translate(q *uql.Query) (json.RawMessage, []*uql.Error, error) {
+	if errs := r.capabilities.Check(q); len(errs) > 0 {
+		return nil, errs, nil
+	}
+
+	result, err := r.harness.Call(r.iface, "translateUQLQuery", q)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !result.OK {
+		return nil, nil, fmt.Errorf("translateUQLQuery threw: %s", result.Error)
+	}
+	if result.Type == "undefined" {
+		return nil, nil, fmt.Errorf("translateUQLQuery returned undefined")
+	}
+	return result.Value, nil, nil
+}
+
+func (r *uqlRunner) Close() {
+	r.harness.Close()
+}
+
+func translateUQL(ctx context.Context, raw, iface string, jsonOutput bool) error {
+	q, err := uql.Parse(raw)
+	if err != nil {
+		fmt.Println("❌ Invalid query:")
+		printUQLErrors(raw, err)
+		return fmt.Errorf("invalid query")
+	}
+
+	if !jsonOutput {
+		printUQLQuery(q)
+		fmt.Println("🧪 Rendering the component...")
+	}
+
+	runner, err := startUQLRunner(ctx, iface)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	request, errs, err := runner.translate(q)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		fmt.Printf("❌ Not supported by %s:\n", runner.iface)
+		printUQLErrors(raw, uqlErrorList(errs)...)
+		return fmt.Errorf("query uses capabilities the module does not declare")
+	}
+
+	pretty, err := indentJSON(request)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		fmt.Println(pretty)
+		return nil
+	}
+
+	fmt.Printf("\n📤 Provider request (%s.translateUQLQuery):\n%s\n", runner.iface, pretty)
+	return nil
+}
+
+func printUQLQuery(q *uql.Query) {
+	fmt.Println("🔎 Query")
+	if q.Text != "" {
+		fmt.Printf("   text:    %q\n", q.Text)
+	}
+	for i, f := range q.Filters {
+		label := "filters:"
+		if i > 0 {
+			label = ""
+		}
+		value, _ := json.Marshal(f.Value)
+		fmt.Printf("   %-8s %s %s %s\n", label, f.Field, f.Operator, value)
+	}
+	if len(q.Sort) > 0 {
+		parts := make([]string, len(q.Sort))
+		for i, s := range q.Sort {
+			parts[i] = s.Field + " " + s.Direction
+		}
+		fmt.Printf("   sort:    %s\n", strings.Join(parts, ", "))
+	}
+	if q.Limit > 0 {
+		fmt.Printf("   limit:   %d\n", q.Limit)
+	}
+}
+
+func uqlErrorList(errs []*uql.Error) []error {
+	list := make([]error, len(errs))
+	for i, err := range errs {
+		list[i] = err
+	}
+	return list
+}
+
+// printUQLErrors points at the position of each error in the query.
+func printUQLErrors(raw string, errs ...error) {
+	for _, err := range errs {
+		uqlErr, ok := err.(*uql.Error)
+		if !ok {
+			fmt.Printf("   %v\n", err)
+			continue
+		}
+		fmt.Printf("   %s\n", raw)
+		fmt.Printf("   %s^ %s\n", strings.Repeat(" ", utf8.RuneCountInString(raw[:uqlErr.Pos])), uqlErr.Msg)
+	}
+}
+
+// uqlGolden is the expected outcome of a query.
+type uqlGolden struct {
+	Request json.RawMessage `json:"request,omitempty"`
+	Errors  []string        `json:"errors,omitempty"`
+}
+
+func testUQLGoldens(ctx context.Context, dir, iface string, update bool) error {
+	queries, err := filepath.Glob(filepath.Join(dir, "*.uql"))
+	if err != nil {
+		return err
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no .uql files in %s", dir)
+	}
+	sort.Strings(queries)
+
+	fmt.Println("🧪 Rendering the component...")
+	runner, err := startUQLRunner(ctx, iface)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+	fmt.Printf("🔎 Translating %d queries with %s\n\n", len(queries), runner.iface)
+
+	passed, failed, updated := 0, 0, 0
+	for _, queryFile := range queries {
+		name := strings.TrimSuffix(filepath.Base(queryFile), ".uql")
+		goldenFile := strings.TrimSuffix(queryFile, ".uql") + ".golden.json"
+
+		raw, err := readUQLFile(queryFile)
+		if err != nil {
+			return err
+		}
+		got, err := uqlOutcome(runner, raw)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", name, err)
+			failed++
+			continue
+		}
+
+		want, err := os.ReadFile(goldenFile)
+		switch {
+		case update:
+			if err == nil && bytes.Equal(want, got) {
+				passed++
+				continue
+			}
+			if err := os.WriteFile(goldenFile, got, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %v", goldenFile, err)
+			}
+			fmt.Printf("📝 %s: updated %s\n", name, goldenFile)
+			updated++
+		case os.IsNotExist(err):
+			fmt.Printf("❌ %s: %s is missing (run with --update to create it)\n", name, goldenFile)
+			failed++
+		case err != nil:
+			return fmt.Errorf("failed to read %s: %v", goldenFile, err)
+		default:
+			if wantPretty, err := indentJSON(want); err == nil {
+				want = []byte(wantPretty + "\n")
+			}
+			if bytes.Equal(want, got) {
+				fmt.Printf("✅ %s\n", name)
+				passed++
+				continue
+			}
+			fmt.Printf("❌ %s: translation differs from %s\n", name, goldenFile)
+			printLineDiff(string(want), string(got))
+			failed++
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d failed", passed, failed)
+	if update {
+		fmt.Printf(", %d updated", updated)
+	}
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d UQL golden tests failed", failed)
+	}
+	return nil
+}
+
+// readUQLFile returns the query of a .uql file, without comment lines.
+func readUQLFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			lines = append(lines, trimmed)
+		}
+	}
+	return strings.Join(lines, " "), nil
+}
+
+// uqlOutcome returns the golden file content for a query: its translation,
+// or the parse and capability errors.
+func uqlOutcome(runner *uqlRunner, raw string) ([]byte, error) {
+	golden := uqlGolden{}
+	q, err := uql.Parse(raw)
+	if err != nil {
+		golden.Errors = []string{err.Error()}
+	} else {
+		request, errs, err := runner.translate(q)
+		if err != nil {
+			return nil, err
+		}
+		golden.Request = request
+		for _, e := range errs {
+			golden.Errors = append(golden.Errors, e.Error())
+		}
+	}
+
+	data, err := json.Marshal(golden)
+	if err != nil {
+		return nil, err
+	}
+	pretty, err := indentJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(pretty + "\n"), nil
+}
+
+// indentJSON re-encodes data with sorted keys and indentation, so equal
+// values compare equal.
+func indentJSON(data []byte) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+	pretty, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(pretty), nil
+}
+
+// printLineDiff prints the lines only in want (-) and only in got (+),
+// around their longest common subsequence.
+func printLineDiff(want, got string) {
+	a := strings.Split(strings.TrimRight(want, "\n"), "\n")
+	b := strings.Split(strings.TrimRight(got, "\n"), "\n")
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fmt.Printf("      %s\n", a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Printf("   -  %s\n", a[i])
+			i++
+		default:
+			fmt.Printf("   +  %s\n", b[j])
+			j++
+		}
+	}
+}
+
+func containsMethod(methods []string, name string) bool {
+	for _, method := range methods {
+		if method == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/devlyspace/dashspace-cli/internal/commands/build"
+	"github.com/devlyspace/dashspace-cli/internal/webhooks"
+	"github.com/spf13/cobra"
+)
+
+func NewWebhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Simulate webhook deliveries against the dev server",
+	}
+
+	cmd.AddCommand(newWebhookSendCmd())
+	cmd.AddCommand(newWebhookSamplesCmd())
+
+	return cmd
+}
+
+func newWebhookSendCmd() *cobra.Command {
+	var (
+		payloadFile string
+		provider    string
+		action      string
+		port        string
+		secret      string
+		useHTTPS    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "send <event>",
+		Short: "Send a webhook event to the running dev server",
+		Long: `Send a signed webhook delivery to 'dashspace dev'.
+
+The provider defaults to the one declared in Module.ts (metadata.webhooks.provider).
+Without --payload, the bundled sample payload for the event is used. Payloads
+that do not match the provider's bundled schema are reported, then sent anyway.
+
+Deliveries go to the address the dev server running in this directory recorded
+in .dev/server.json, or to --port on localhost when given.
+
+EXAMPLES:
+  dashspace webhook send issues
+  dashspace webhook send pull_request --action closed
+  dashspace webhook send issues --payload ./fixtures/issue.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return sendWebhook(args[0], payloadFile, provider, action, port, secret, useHTTPS)
+		},
+	}
+
+	cmd.Flags().StringVar(&payloadFile, "payload", "", "JSON payload file (defaults to the bundled sample)")
+	cmd.Flags().StringVar(&provider, "provider", "", "Webhook provider (defaults to the one declared in Module.ts)")
+	cmd.Flags().StringVar(&action, "action", "", "Override the payload 'action' field")
+	cmd.Flags().StringVarP(&port, "port", "p", "", "Port of a dev server on localhost (defaults to the address recorded by 'dashspace dev')")
+	cmd.Flags().StringVar(&secret, "secret", webhooks.DefaultSecret, "Secret used to sign the delivery")
+	cmd.Flags().BoolVar(&useHTTPS, "https", false, "The dev server on --port runs with --https (its self-signed certificate is trusted)")
+
+	return cmd
+}
+
+func newWebhookSamplesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "samples [provider]",
+		Short: "List bundled sample webhook payloads",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			providers := webhooks.Providers()
+			if len(args) == 1 {
+				providers = []string{strings.ToLower(args[0])}
+			}
+
+			for _, provider := range providers {
+				events := webhooks.SampleEvents(provider)
+				if len(events) == 0 {
+					fmt.Printf("❌ No sample payloads for %s\n", provider)
+					continue
+				}
+				fmt.Printf("🪝 %s\n", provider)
+				for _, event := range events {
+					fmt.Printf("   • %s\n", event)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func sendWebhook(event, payloadFile, provider, action, port, secret string, useHTTPS bool) error {
+	if provider == "" {
+		provider = declaredWebhookProvider()
+		if provider == "" {
+			return fmt.Errorf("no webhook provider declared in Module.ts, use --provider")
+		}
+	}
+	provider = strings.ToLower(provider)
+
+	var payload []byte
+	var err error
+	if payloadFile != "" {
+		payload, err = os.ReadFile(payloadFile)
+		if err != nil {
+			return fmt.Errorf("failed to read payload: %v", err)
+		}
+	} else {
+		payload, err = webhooks.SamplePayload(provider, event)
+		if err != nil {
+			return err
+		}
+	}
+
+	if action != "" {
+		payload, err = overrideAction(payload, action)
+		if err != nil {
+			return err
+		}
+	}
+	checkWebhookPayload(provider, event, payload)
+
+	baseURL := devServerURL(port, useHTTPS)
+	client := http.DefaultClient
+	if strings.HasPrefix(baseURL, "https://") {
+		// The dev server uses a self-signed certificate
+		client = &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}}
+	}
+
+	url := baseURL + "/__dashspace/webhooks"
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	for key, value := range webhooks.SignatureHeaders(provider, event, secret, payload) {
+		req.Header.Set(key, value)
+	}
+
+	fmt.Printf("🪝 Sending %s/%s to %s...\n", provider, event, url)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("dev server not reachable at %s (is 'dashspace dev' running?): %v", baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("delivery rejected (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		ID        string `json:"id"`
+		Verified  bool   `json:"verified"`
+		Delivered int    `json:"delivered"`
+	}
+	json.Unmarshal(body, &result)
+
+	if result.Delivered == 0 {
+		fmt.Println("⚠️  Delivered, but no dev page is connected - open the dev server in a browser")
+	} else {
+		fmt.Printf("✅ Delivered to %d page(s) (id: %s)\n", result.Delivered, result.ID)
+	}
+	if !result.Verified {
+		fmt.Println("⚠️  Signature was not accepted by the dev server - check --secret / --webhook-secret")
+	}
+
+	return nil
+}
+
+// devServerURL returns the base URL of the dev server: localhost:port when a
+// port is given, otherwise the address recorded by the running dev server,
+// falling back to http://localhost:3000.
+func devServerURL(port string, useHTTPS bool) string {
+	if port == "" {
+		if info, err := readDevServerInfo(); err == nil {
+			return strings.TrimSuffix(info.URL, "/")
+		}
+		port = "3000"
+	}
+
+	scheme := "http"
+	if useHTTPS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://localhost:%s", scheme, port)
+}
+
+// checkWebhookPayload warns when a payload does not match the bundled schema
+// of its event. It is sent anyway, handlers have to cope with it.
+func checkWebhookPayload(provider, event string, payload []byte) {
+	schema, err := webhooks.LoadSchema(provider)
+	if err != nil {
+		return
+	}
+
+	problems, err := schema.ValidatePayload(event, payload)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		return
+	}
+	if len(problems) > 0 {
+		fmt.Printf("⚠️  Payload does not match the %s %s schema:\n", schema.Title, event)
+		for _, problem := range problems {
+			fmt.Printf("   - %s\n", problem)
+		}
+	}
+}
+
+func declaredWebhookProvider() string {
+	parser, err := build.NewModuleParser()
+	if err != nil {
+		return ""
+	}
+
+	declared, err := parser.ExtractWebhooks()
+	if err != nil || declared == nil {
+		return ""
+	}
+
+	provider, _ := declared["provider"].(string)
+	return provider
+}
+
+func overrideAction(payload []byte, action string) ([]byte, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, fmt.Errorf("payload is not a JSON object: %v", err)
+	}
+	data["action"] = action
+	return json.MarshalIndent(data, "", "  ")
+}
@@ -2,38 +2,191 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devlyspace/dashspace-cli/internal/credentials"
 )
 
+// DefaultAPIBaseURL is the production Modly API.
+const DefaultAPIBaseURL = "https://modly.dashspace.dev"
+
+// DefaultTimeout bounds how long the API may take to answer a request.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultProfile is the profile used when none is selected.
+const DefaultProfile = "default"
+
+// Environment variables overriding the config file.
+const (
+	ProfileEnvVar = "DASHSPACE_PROFILE"
+	APIURLEnvVar  = "DASHSPACE_API_URL"
+	DebugEnvVar   = "DASHSPACE_DEBUG"
+	TimeoutEnvVar = "DASHSPACE_TIMEOUT"
+)
+
+// Config is the resolved configuration of the active profile, after flags
+// and environment variables have been applied.
 type Config struct {
-	APIBaseURL string `json:"api_base_url"`
-	AuthToken  string `json:"auth_token,omitempty"`
+	Profile         string
+	APIBaseURL      string
+	CredentialStore string
+	Debug           bool
+	Timeout         time.Duration
+	Username        string
+	Email           string
+
+	// Tokens live in the credential store, see LoadCredentials and SaveCredentials
+	AuthToken    string
+	RefreshToken string
+	TokenExpiry  int64 // Unix timestamp, 0 if unknown
+}
+
+// Overrides are the values given on the command line (--profile, --api-url, --debug).
+type Overrides struct {
+	Profile string
+	APIURL  string
+	Debug   bool
+}
+
+// file is the content of ~/.dashspace/config.json.
+type file struct {
+	CurrentProfile  string              `json:"current_profile,omitempty"`
+	CredentialStore string              `json:"credential_store,omitempty"`
+	Debug           bool                `json:"debug,omitempty"`
+	Timeout         string              `json:"timeout,omitempty"`
+	Profiles        map[string]*Profile `json:"profiles"`
+}
+
+// Profile is one named API environment and the identity used with it.
+type Profile struct {
+	APIBaseURL string `json:"api_base_url,omitempty"`
 	Username   string `json:"username,omitempty"`
 	Email      string `json:"email,omitempty"`
 }
 
-var globalConfig *Config
+// legacyFile is the single-profile config.json of older versions, which also
+// held the tokens in plaintext.
+type legacyFile struct {
+	APIBaseURL   string `json:"api_base_url"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	AuthToken    string `json:"auth_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenExpiry  int64  `json:"token_expiry"`
+}
 
-func InitConfig() {
-	globalConfig = &Config{
-		APIBaseURL: "https://modly.dashspace.dev",
+// SetTokens stores a new access/refresh token pair. expiresIn is in seconds;
+// 0 means the server did not say. An empty refreshToken keeps the current one.
+func (c *Config) SetTokens(accessToken, refreshToken string, expiresIn int) {
+	c.AuthToken = accessToken
+	if refreshToken != "" {
+		c.RefreshToken = refreshToken
 	}
+	c.TokenExpiry = 0
+	if expiresIn > 0 {
+		c.TokenExpiry = time.Now().Add(time.Duration(expiresIn) * time.Second).Unix()
+	}
+}
+
+// TokenExpiresWithin reports whether the access token is known to expire within d.
+func (c *Config) TokenExpiresWithin(d time.Duration) bool {
+	return c.TokenExpiry != 0 && time.Now().Add(d).Unix() >= c.TokenExpiry
+}
+
+var (
+	globalConfig *Config
+	configFile   *file
+	overrides    Overrides
+	legacy       legacyFile
+
+	credentialsLoaded bool
+	store             credentials.Store
+)
+
+// InitConfig loads config.json and resolves the active profile. Precedence,
+// highest first: command-line flags, environment variables, the profile,
+// built-in defaults.
+func InitConfig(o Overrides) {
+	overrides = o
 	loadConfig()
+	resolve()
 }
 
 func GetConfig() *Config {
 	return globalConfig
 }
 
+// resolve builds globalConfig from the config file, env vars and overrides.
+func resolve() {
+	name := firstNonEmpty(overrides.Profile, os.Getenv(ProfileEnvVar), configFile.CurrentProfile, DefaultProfile)
+
+	profile := configFile.Profiles[name]
+	if profile == nil {
+		profile = &Profile{}
+	}
+
+	globalConfig = &Config{
+		Profile:         name,
+		APIBaseURL:      strings.TrimRight(firstNonEmpty(overrides.APIURL, os.Getenv(APIURLEnvVar), profile.APIBaseURL, DefaultAPIBaseURL), "/"),
+		CredentialStore: configFile.CredentialStore,
+		Debug:           overrides.Debug || envBool(DebugEnvVar) || configFile.Debug,
+		Timeout:         parseTimeout(os.Getenv(TimeoutEnvVar), configFile.Timeout),
+		Username:        profile.Username,
+		Email:           profile.Email,
+	}
+
+	credentialsLoaded = false
+	store = nil
+}
+
+// SaveConfig writes the identity of the active profile and the global
+// settings to config.json. Values coming from flags or env vars are not saved.
 func SaveConfig() error {
+	if profile := configFile.Profiles[globalConfig.Profile]; profile != nil || globalConfig.Username != "" {
+		if profile == nil {
+			profile = &Profile{}
+			configFile.Profiles[globalConfig.Profile] = profile
+		}
+		profile.Username = globalConfig.Username
+		profile.Email = globalConfig.Email
+	}
+	configFile.CredentialStore = globalConfig.CredentialStore
+
+	return writeConfigFile()
+}
+
+func writeConfigFile() error {
 	configDir := getConfigDir()
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return err
 	}
 
+	// Move plaintext tokens of older versions out before rewriting the file
+	if legacy.AuthToken != "" {
+		if s, err := openStore(); err == nil {
+			migrateLegacyTokens(s)
+		}
+	}
+
+	var content interface{} = configFile
+	if legacy.AuthToken != "" {
+		// Migration failed: keep the tokens where they were rather than lose them
+		content = struct {
+			*file
+			AuthToken    string `json:"auth_token"`
+			RefreshToken string `json:"refresh_token,omitempty"`
+			TokenExpiry  int64  `json:"token_expiry,omitempty"`
+		}{configFile, legacy.AuthToken, legacy.RefreshToken, legacy.TokenExpiry}
+	}
+
 	configPath := filepath.Join(configDir, "config.json")
-	data, err := json.MarshalIndent(globalConfig, "", "  ")
+	data, err := json.MarshalIndent(content, "", "  ")
 	if err != nil {
 		return err
 	}
@@ -42,13 +195,176 @@ func SaveConfig() error {
 }
 
 func loadConfig() {
+	configFile = &file{Profiles: map[string]*Profile{}}
+	legacy = legacyFile{}
+
 	configPath := filepath.Join(getConfigDir(), "config.json")
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return // Fichier n'existe pas, utiliser les valeurs par défaut
 	}
 
-	json.Unmarshal(data, globalConfig)
+	json.Unmarshal(data, configFile)
+	if configFile.Profiles == nil {
+		configFile.Profiles = map[string]*Profile{}
+	}
+
+	// Older versions had a single, flat profile
+	json.Unmarshal(data, &legacy)
+	if legacy.APIBaseURL != "" && len(configFile.Profiles) == 0 {
+		configFile.Profiles[DefaultProfile] = &Profile{
+			APIBaseURL: legacy.APIBaseURL,
+			Username:   legacy.Username,
+			Email:      legacy.Email,
+		}
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseTimeout returns the first valid positive duration of values, or DefaultTimeout.
+func parseTimeout(values ...string) time.Duration {
+	for _, value := range values {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultTimeout
+}
+
+func envBool(name string) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && value
+}
+
+// openStore returns the credential store in use. DASHSPACE_TOKEN always wins
+// so CI never touches saved credentials.
+func openStore() (credentials.Store, error) {
+	if store != nil {
+		return store, nil
+	}
+
+	name := globalConfig.CredentialStore
+	if os.Getenv(credentials.TokenEnvVar) != "" {
+		name = credentials.BackendEnv
+	}
+
+	s, err := credentials.Open(name, getConfigDir())
+	if err != nil {
+		return nil, err
+	}
+	store = s
+	return store, nil
+}
+
+// SetCredentialStore switches the backend tokens are saved to (keyring, file or env).
+func SetCredentialStore(name string) error {
+	s, err := credentials.Open(name, getConfigDir())
+	if err != nil {
+		return err
+	}
+	globalConfig.CredentialStore = name
+	store = s
+	return nil
+}
+
+// CredentialStoreName describes where tokens are kept, for messages.
+func CredentialStoreName() string {
+	s, err := openStore()
+	if err != nil {
+		return globalConfig.CredentialStore
+	}
+	return s.Name()
+}
+
+// credentialAccount keys the tokens of the active profile in the credential store.
+func credentialAccount() string {
+	return globalConfig.Profile
+}
+
+// LoadCredentials reads the tokens from the credential store into the config.
+// Tokens found in plaintext in config.json are moved to the store first.
+// Loading is lazy so that commands which never authenticate don't prompt
+// for a passphrase.
+func LoadCredentials() error {
+	if credentialsLoaded {
+		return nil
+	}
+
+	s, err := openStore()
+	if err != nil {
+		return err
+	}
+
+	if legacy.AuthToken != "" {
+		migrateLegacyTokens(s)
+	}
+
+	creds, err := s.Get(credentialAccount())
+	if err != nil && !errors.Is(err, credentials.ErrNotFound) {
+		return fmt.Errorf("failed to read credentials from %s: %w", s.Name(), err)
+	}
+	if creds != nil {
+		globalConfig.AuthToken = creds.AccessToken
+		globalConfig.RefreshToken = creds.RefreshToken
+		globalConfig.TokenExpiry = creds.Expiry
+	}
+
+	credentialsLoaded = true
+	return nil
+}
+
+func migrateLegacyTokens(s credentials.Store) {
+	creds := &credentials.Credentials{
+		AccessToken:  legacy.AuthToken,
+		RefreshToken: legacy.RefreshToken,
+		Expiry:       legacy.TokenExpiry,
+	}
+
+	// The flat config.json became the default profile
+	if err := s.Set(DefaultProfile, creds); err != nil {
+		if !errors.Is(err, credentials.ErrReadOnly) {
+			fmt.Printf("⚠️  Could not move your token out of config.json: %v\n", err)
+		}
+		return
+	}
+
+	legacy.AuthToken = ""
+	if err := writeConfigFile(); err != nil {
+		fmt.Printf("⚠️  Could not rewrite config.json: %v\n", err)
+		return
+	}
+	fmt.Printf("🔐 Moved your saved token from config.json to the %s\n", s.Name())
+}
+
+// SaveCredentials writes the current tokens to the credential store.
+func SaveCredentials() error {
+	s, err := openStore()
+	if err != nil {
+		return err
+	}
+
+	err = s.Set(credentialAccount(), &credentials.Credentials{
+		AccessToken:  globalConfig.AuthToken,
+		RefreshToken: globalConfig.RefreshToken,
+		Expiry:       globalConfig.TokenExpiry,
+	})
+	if errors.Is(err, credentials.ErrReadOnly) {
+		return fmt.Errorf("%s is set, unset it to save credentials", credentials.TokenEnvVar)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save credentials to %s: %w", s.Name(), err)
+	}
+
+	credentialsLoaded = true
+	return nil
 }
 
 func getConfigDir() string {
@@ -56,9 +372,25 @@ func getConfigDir() string {
 	return filepath.Join(home, ".dashspace")
 }
 
-func ClearAuth() {
+// ClearAuth forgets the user and purges their tokens from every credential
+// store that may hold them, including plaintext config.json.
+func ClearAuth() error {
 	globalConfig.AuthToken = ""
+	globalConfig.RefreshToken = ""
+	globalConfig.TokenExpiry = 0
 	globalConfig.Username = ""
 	globalConfig.Email = ""
-	SaveConfig()
+	legacy.AuthToken = ""
+
+	var errs []error
+	for _, s := range credentials.All(getConfigDir(), store) {
+		if err := s.Delete(credentialAccount()); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+
+	if err := SaveConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
 }
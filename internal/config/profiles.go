@@ -0,0 +1,226 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/devlyspace/dashspace-cli/internal/credentials"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// ProfileNames returns the saved profiles, sorted.
+func ProfileNames() []string {
+	names := make([]string, 0, len(configFile.Profiles))
+	for name := range configFile.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProfile returns a saved profile, or nil.
+func GetProfile(name string) *Profile {
+	return configFile.Profiles[name]
+}
+
+// CurrentProfile is the profile selected with 'profile use' (not flags or env vars).
+func CurrentProfile() string {
+	return firstNonEmpty(configFile.CurrentProfile, DefaultProfile)
+}
+
+// AddProfile saves a new profile pointing at apiURL.
+func AddProfile(name, apiURL string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s' (letters, digits, '-' and '_' only)", name)
+	}
+	if _, exists := configFile.Profiles[name]; exists {
+		return fmt.Errorf("profile '%s' already exists", name)
+	}
+	if apiURL != "" {
+		if err := validateURL(apiURL); err != nil {
+			return err
+		}
+	}
+
+	configFile.Profiles[name] = &Profile{APIBaseURL: apiURL}
+	return writeConfigFile()
+}
+
+// UseProfile makes name the profile used when no --profile or DASHSPACE_PROFILE is given.
+func UseProfile(name string) error {
+	if _, exists := configFile.Profiles[name]; !exists && name != DefaultProfile {
+		return fmt.Errorf("profile '%s' does not exist (see 'dashspace profile list')", name)
+	}
+
+	configFile.CurrentProfile = name
+	if err := writeConfigFile(); err != nil {
+		return err
+	}
+	resolve()
+	return nil
+}
+
+// RemoveProfile deletes a profile and purges its credentials.
+func RemoveProfile(name string) error {
+	if _, exists := configFile.Profiles[name]; !exists {
+		return fmt.Errorf("profile '%s' does not exist", name)
+	}
+
+	for _, s := range credentials.All(getConfigDir(), store) {
+		if err := s.Delete(name); err != nil {
+			fmt.Printf("⚠️  Failed to remove credentials of '%s' from the %s: %v\n", name, s.Name(), err)
+		}
+	}
+
+	delete(configFile.Profiles, name)
+	if configFile.CurrentProfile == name {
+		configFile.CurrentProfile = ""
+	}
+	if err := writeConfigFile(); err != nil {
+		return err
+	}
+	resolve()
+	return nil
+}
+
+// Setting is a configuration key with its effective value and where it came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string // flag, env, profile, config or default
+}
+
+// Keys lists the settings accepted by Get and Set.
+func Keys() []string {
+	return []string{"profile", "api_url", "credential_store", "debug", "timeout"}
+}
+
+// Get returns the effective value of key for the active profile.
+func Get(key string) (Setting, error) {
+	profile := configFile.Profiles[globalConfig.Profile]
+	if profile == nil {
+		profile = &Profile{}
+	}
+
+	switch key {
+	case "profile":
+		return pick(key,
+			source{"flag", overrides.Profile},
+			source{"env", os.Getenv(ProfileEnvVar)},
+			source{"config", configFile.CurrentProfile},
+			source{"default", DefaultProfile}), nil
+	case "api_url":
+		return pick(key,
+			source{"flag", overrides.APIURL},
+			source{"env", os.Getenv(APIURLEnvVar)},
+			source{"profile", profile.APIBaseURL},
+			source{"default", DefaultAPIBaseURL}), nil
+	case "credential_store":
+		if os.Getenv(credentials.TokenEnvVar) != "" {
+			return Setting{Key: key, Value: credentials.BackendEnv, Source: "env"}, nil
+		}
+		return pick(key,
+			source{"config", configFile.CredentialStore},
+			source{"default", "auto"}), nil
+	case "debug":
+		debug := ""
+		if overrides.Debug {
+			debug = "true"
+		}
+		configDebug := ""
+		if configFile.Debug {
+			configDebug = "true"
+		}
+		envDebug := ""
+		if envBool(DebugEnvVar) {
+			envDebug = "true"
+		}
+		return pick(key,
+			source{"flag", debug},
+			source{"env", envDebug},
+			source{"config", configDebug},
+			source{"default", "false"}), nil
+	case "timeout":
+		return pick(key,
+			source{"env", os.Getenv(TimeoutEnvVar)},
+			source{"config", configFile.Timeout},
+			source{"default", DefaultTimeout.String()}), nil
+	}
+	return Setting{}, fmt.Errorf("unknown config key '%s' (available: %v)", key, Keys())
+}
+
+// Set saves key in config.json: api_url goes to the active profile, the
+// other keys are global.
+func Set(key, value string) error {
+	switch key {
+	case "profile":
+		return UseProfile(value)
+	case "api_url":
+		if err := validateURL(value); err != nil {
+			return err
+		}
+		profile := configFile.Profiles[globalConfig.Profile]
+		if profile == nil {
+			profile = &Profile{}
+			configFile.Profiles[globalConfig.Profile] = profile
+		}
+		profile.APIBaseURL = value
+	case "credential_store":
+		if value == "auto" {
+			value = ""
+		}
+		if value != "" {
+			if _, err := credentials.Open(value, getConfigDir()); err != nil {
+				return err
+			}
+		}
+		configFile.CredentialStore = value
+	case "debug":
+		debug, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("debug must be true or false")
+		}
+		configFile.Debug = debug
+	case "timeout":
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("timeout must be a positive duration, e.g. 30s or 2m")
+		}
+		configFile.Timeout = value
+	default:
+		return fmt.Errorf("unknown config key '%s' (available: %v)", key, Keys())
+	}
+
+	if err := writeConfigFile(); err != nil {
+		return err
+	}
+	resolve()
+	return nil
+}
+
+type source struct {
+	name  string
+	value string
+}
+
+func pick(key string, sources ...source) Setting {
+	for _, s := range sources {
+		if s.value != "" {
+			return Setting{Key: key, Value: s.value, Source: s.name}
+		}
+	}
+	return Setting{Key: key}
+}
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid API URL '%s' (expected http(s)://host)", value)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package credentials stores CLI access tokens outside of config.json.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Backend names accepted by Open and the credential_store config setting.
+const (
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+	BackendEnv     = "env"
+)
+
+// TokenEnvVar holds a token for CI, used instead of any saved credentials.
+const TokenEnvVar = "DASHSPACE_TOKEN"
+
+// ErrNotFound is returned by Get when no credentials are saved for an account.
+var ErrNotFound = errors.New("no saved credentials")
+
+// ErrReadOnly is returned when saving to a backend that cannot store tokens.
+var ErrReadOnly = errors.New("credential store is read-only")
+
+// Credentials are the tokens of one account.
+type Credentials struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Expiry       int64  `json:"expiry,omitempty"`
+}
+
+// Store saves credentials per account.
+type Store interface {
+	// Name describes the backend for messages, e.g. "system keyring".
+	Name() string
+	Get(account string) (*Credentials, error)
+	Set(account string, creds *Credentials) error
+	Delete(account string) error
+}
+
+// Open returns the backend called name. An empty name picks the best
+// available one: the env var if set, then the keyring, then the encrypted file.
+func Open(name, configDir string) (Store, error) {
+	switch name {
+	case "":
+		if os.Getenv(TokenEnvVar) != "" {
+			return envStore{}, nil
+		}
+		if keyringAvailable() {
+			return keyringStore{}, nil
+		}
+		return NewFileStore(configDir), nil
+	case BackendKeyring:
+		if !keyringAvailable() {
+			return nil, fmt.Errorf("no Secret Service keyring available (requires secret-tool and a D-Bus session)")
+		}
+		return keyringStore{}, nil
+	case BackendFile:
+		return NewFileStore(configDir), nil
+	case BackendEnv:
+		return envStore{}, nil
+	}
+	return nil, fmt.Errorf("unknown credential store '%s' (use %s, %s or %s)", name, BackendKeyring, BackendFile, BackendEnv)
+}
+
+// All returns every backend usable on this machine, for purging on logout.
+// current, the store in use or nil, replaces the backend of the same kind, so
+// a file store that is already unlocked does not ask for the passphrase again.
+func All(configDir string, current Store) []Store {
+	file := NewFileStore(configDir)
+	if s, ok := current.(*FileStore); ok && s.path == file.path {
+		file = s
+	}
+
+	stores := []Store{file}
+	if keyringAvailable() {
+		stores = append(stores, keyringStore{})
+	}
+	return stores
+}
+
+// envStore reads the token from DASHSPACE_TOKEN and never persists anything.
+type envStore struct{}
+
+func (envStore) Name() string { return TokenEnvVar }
+
+func (envStore) Get(account string) (*Credentials, error) {
+	token := os.Getenv(TokenEnvVar)
+	if token == "" {
+		return nil, ErrNotFound
+	}
+	return &Credentials{AccessToken: token}, nil
+}
+
+func (envStore) Set(account string, creds *Credentials) error { return ErrReadOnly }
+
+func (envStore) Delete(account string) error { return ErrReadOnly }
@@ -0,0 +1,235 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/term"
+)
+
+// PassphraseEnvVar unlocks the encrypted credentials file without a prompt.
+const PassphraseEnvVar = "DASHSPACE_PASSPHRASE"
+
+const (
+	credentialsFile = "credentials.enc"
+	kdfIterations   = 600000
+	keyLength       = 32
+)
+
+// PassphraseFunc returns the passphrase protecting the credentials file.
+// confirm is true when the file is being created.
+type PassphraseFunc func(confirm bool) (string, error)
+
+// FileStore keeps credentials in an AES-256-GCM encrypted file whose key is
+// derived from a passphrase with PBKDF2-SHA256.
+type FileStore struct {
+	path       string
+	Passphrase PassphraseFunc
+
+	passphrase string
+}
+
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// NewFileStore returns the encrypted file store in configDir.
+func NewFileStore(configDir string) *FileStore {
+	return &FileStore{
+		path:       filepath.Join(configDir, credentialsFile),
+		Passphrase: promptPassphrase,
+	}
+}
+
+func (s *FileStore) Name() string { return "encrypted file " + s.path }
+
+func (s *FileStore) Get(account string) (*Credentials, error) {
+	accounts, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	creds, ok := accounts[account]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return creds, nil
+}
+
+func (s *FileStore) Set(account string, creds *Credentials) error {
+	accounts, err := s.load()
+	if err != nil {
+		return err
+	}
+	accounts[account] = creds
+	return s.save(accounts)
+}
+
+func (s *FileStore) Delete(account string) error {
+	if _, err := os.Stat(s.path); os.IsNotExist(err) {
+		return nil
+	}
+
+	accounts, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(accounts, account)
+
+	if len(accounts) == 0 {
+		return os.Remove(s.path)
+	}
+	return s.save(accounts)
+}
+
+func (s *FileStore) load() (map[string]*Credentials, error) {
+	raw, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]*Credentials{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("corrupted credentials file %s: %w", s.path, err)
+	}
+	if file.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported key derivation '%s' in %s", file.KDF, s.path)
+	}
+
+	passphrase, err := s.getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		s.passphrase = ""
+		return nil, errors.New("wrong passphrase for the credentials file")
+	}
+
+	accounts := map[string]*Credentials{}
+	if err := json.Unmarshal(plaintext, &accounts); err != nil {
+		return nil, fmt.Errorf("corrupted credentials file %s: %w", s.path, err)
+	}
+	return accounts, nil
+}
+
+func (s *FileStore) save(accounts map[string]*Credentials) error {
+	plaintext, err := json.Marshal(accounts)
+	if err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(s.path)
+	passphrase, err := s.getPassphrase(os.IsNotExist(statErr))
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(passphrase, salt, kdfIterations)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(encryptedFile{
+		Version:    1,
+		KDF:        "pbkdf2-sha256",
+		Iterations: kdfIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Data:       gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+func (s *FileStore) getPassphrase(confirm bool) (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+	passphrase, err := s.Passphrase(confirm)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", errors.New("empty passphrase")
+	}
+	s.passphrase = passphrase
+	return passphrase, nil
+}
+
+func promptPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(PassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return "", fmt.Errorf("the credentials file is encrypted: set %s or run in a terminal", PassphraseEnvVar)
+	}
+
+	fmt.Print("🔑 Credentials passphrase: ")
+	first, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("error reading passphrase: %w", err)
+	}
+
+	if confirm {
+		fmt.Print("🔑 Confirm passphrase: ")
+		second, err := term.ReadPassword(int(syscall.Stdin))
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("error reading passphrase: %w", err)
+		}
+		if string(first) != string(second) {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+
+	return string(first), nil
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(passphrase), salt, iterations, keyLength, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,73 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const keyringService = "dashspace-cli"
+
+// keyringStore keeps credentials in the Secret Service (GNOME Keyring,
+// KWallet...) through libsecret's secret-tool, which talks D-Bus for us.
+type keyringStore struct{}
+
+func keyringAvailable() bool {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return false
+	}
+	return os.Getenv("DBUS_SESSION_BUS_ADDRESS") != ""
+}
+
+func (keyringStore) Name() string { return "system keyring" }
+
+func (keyringStore) Get(account string) (*Credentials, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "account", account).Output()
+	if err != nil {
+		// secret-tool exits 1 with no output when nothing matches
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) == 0 {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("keyring lookup failed: %w", err)
+	}
+
+	secret := strings.TrimSpace(string(out))
+	if secret == "" {
+		return nil, ErrNotFound
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal([]byte(secret), &creds); err != nil {
+		return nil, fmt.Errorf("invalid credentials in keyring: %w", err)
+	}
+	return &creds, nil
+}
+
+func (keyringStore) Set(account string, creds *Credentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("secret-tool", "store",
+		"--label=DashSpace CLI ("+account+")",
+		"service", keyringService,
+		"account", account,
+	)
+	// The secret goes through stdin so it never shows up in the process list
+	cmd.Stdin = strings.NewReader(string(data))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("keyring store failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (keyringStore) Delete(account string) error {
+	cmd := exec.Command("secret-tool", "clear", "service", keyringService, "account", account)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("keyring clear failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
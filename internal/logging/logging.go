@@ -0,0 +1,141 @@
+// Package logging sets up the CLI's structured logs: a rotating file for bug
+// reports, and stderr output when --verbose or --debug is given. HTTP headers
+// and bodies are only logged, to both, with --debug.
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// LogFileName is the active log file in Dir().
+const LogFileName = "dashspace.log"
+
+// Options selects what is logged to stderr. The log file always gets the info
+// level, and the debug level too with Debug.
+type Options struct {
+	Verbose bool // info level on stderr
+	Debug   bool // debug level on stderr, including HTTP headers and bodies
+}
+
+var (
+	logger  = slog.New(discardHandler{})
+	logFile io.Closer
+)
+
+// Setup installs the logger used by Logger and slog's default logger.
+// Failing to open the log file is not fatal: stderr logging still works.
+func Setup(opts Options) error {
+	var handlers []slog.Handler
+
+	level := slog.LevelInfo
+	if opts.Debug {
+		level = slog.LevelDebug
+	}
+	if opts.Debug || opts.Verbose {
+		handlers = append(handlers, slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	}
+
+	file, err := NewRotatingFile(filepath.Join(Dir(), LogFileName), DefaultMaxSize, DefaultMaxBackups)
+	if err == nil {
+		handlers = append(handlers, slog.NewJSONHandler(file, &slog.HandlerOptions{Level: level}))
+		if logFile != nil {
+			logFile.Close()
+		}
+		logFile = file
+	}
+
+	logger = slog.New(fanout(handlers))
+	slog.SetDefault(logger)
+	return err
+}
+
+// Logger returns the CLI logger. Before Setup it discards everything.
+func Logger() *slog.Logger {
+	return logger
+}
+
+// Close flushes and closes the log file.
+func Close() error {
+	if logFile == nil {
+		return nil
+	}
+	err := logFile.Close()
+	logFile = nil
+	return err
+}
+
+// Dir is the platform's log directory:
+//   - macOS: ~/Library/Logs/dashspace
+//   - Linux: $XDG_DATA_HOME/dashspace/logs, or ~/.local/share/dashspace/logs
+//   - Windows: %APPDATA%/dashspace/logs
+func Dir() string {
+	home, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Logs", "dashspace")
+	case "windows":
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			return filepath.Join(appData, "dashspace", "logs")
+		}
+		return filepath.Join(home, "AppData", "Roaming", "dashspace", "logs")
+	}
+
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "dashspace", "logs")
+	}
+	return filepath.Join(home, ".local", "share", "dashspace", "logs")
+}
+
+// fanout sends every record to all handlers that accept its level.
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, record.Level) {
+			if err := h.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (f fanout) WithGroup(name string) slog.Handler {
+	handlers := make(fanout, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
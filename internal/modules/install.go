@@ -0,0 +1,322 @@
+package modules
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/devlyspace/dashspace-cli/internal/api"
+	"github.com/devlyspace/dashspace-cli/internal/semver"
+)
+
+// maxUnpackedSize caps the total size of an unpacked archive.
+const maxUnpackedSize = 100 << 20
+
+// integrityFile records, inside an installed module, which archive it was
+// unpacked from.
+const integrityFile = ".dashspace-integrity"
+
+// Manifest is the part of an installed module's dashspace.json read when
+// installing it.
+type Manifest struct {
+	ID       int    `json:"id"`
+	Slug     string `json:"slug"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Entry    string `json:"entry"`
+	Checksum string `json:"checksum"`
+}
+
+// ReadManifest reads dashspace.json from an installed module directory.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "dashspace.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid dashspace.json: %w", err)
+	}
+	if manifest.Entry == "" {
+		manifest.Entry = "bundle.js"
+	}
+	return &manifest, nil
+}
+
+// Installer downloads, verifies and unpacks module versions into Dir.
+type Installer struct {
+	Dir string
+
+	// Insecure accepts versions the store published without a checksum.
+	Insecure bool
+
+	client *api.Client
+	keys   map[string]api.SigningKey
+}
+
+func NewInstaller(client *api.Client, dir string) *Installer {
+	return &Installer{Dir: dir, client: client}
+}
+
+// Resolve finds a module by slug or ID and the highest published version
+// satisfying versionRange.
+func (i *Installer) Resolve(ctx context.Context, ref string, versionRange semver.Range) (*api.ModuleDetails, *api.ModuleVersion, error) {
+	module, err := i.client.GetModule(ctx, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	versions, err := i.client.ListModuleVersions(ctx, module.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	parsed := make([]semver.Version, 0, len(versions))
+	byVersion := map[string]*api.ModuleVersion{}
+	for j := range versions {
+		v, err := semver.Parse(versions[j].Version)
+		if err != nil {
+			continue // not installable by range
+		}
+		parsed = append(parsed, v)
+		byVersion[v.String()] = &versions[j]
+	}
+
+	best, ok := versionRange.Best(parsed)
+	if !ok {
+		return nil, nil, fmt.Errorf("no version of %s matches %s", module.Slug, versionRange)
+	}
+	return module, byVersion[best.String()], nil
+}
+
+// Locked returns the locked version of a module from the store, so its
+// hash and signature can be checked again.
+func (i *Installer) Locked(ctx context.Context, locked *LockedModule) (*api.ModuleVersion, error) {
+	versions, err := i.client.ListModuleVersions(ctx, locked.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	for j := range versions {
+		if versions[j].ID == locked.VersionID {
+			return &versions[j], nil
+		}
+	}
+	return nil, fmt.Errorf("version %s is no longer available in the store", locked.Version)
+}
+
+// Installed reports whether slug is unpacked in Dir from the archive with
+// the given integrity.
+func (i *Installer) Installed(slug, integrity string) bool {
+	data, err := os.ReadFile(filepath.Join(i.Dir, slug, integrityFile))
+	return err == nil && strings.TrimSpace(string(data)) == integrity
+}
+
+// Install downloads a version, checks it against the store hash, its
+// signature when signed and, if not empty, the integrity from the lockfile,
+// then replaces Dir/slug with its content. A version without a store hash
+// is refused unless Insecure is set.
+func (i *Installer) Install(ctx context.Context, slug string, version *api.ModuleVersion, integrity string) (*LockedModule, error) {
+	if version.SHA256 == "" && !i.Insecure {
+		return nil, fmt.Errorf("the store published v%s without a checksum, so it cannot be verified (use --insecure to install it anyway)", version.Version)
+	}
+
+	archive, err := i.client.DownloadModuleVersion(ctx, version.ModuleID, version.ID)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+
+	sum := sha256.Sum256(archive)
+	actual := "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+
+	if integrity != "" && integrity != actual {
+		return nil, fmt.Errorf("integrity mismatch: lockfile has %s, downloaded archive is %s", integrity, actual)
+	}
+	if version.SHA256 != "" && !strings.EqualFold(version.SHA256, hex.EncodeToString(sum[:])) {
+		return nil, fmt.Errorf("checksum mismatch: the store announced %s, downloaded archive is %x", version.SHA256, sum)
+	}
+	// Only a verified signature is recorded, so SigningKeyID means signed
+	signingKeyID := ""
+	if version.Signature != "" {
+		if err := i.verifySignature(ctx, version, archive); err != nil {
+			return nil, err
+		}
+		signingKeyID = version.SigningKeyID
+	}
+
+	if err := i.unpack(slug, archive, actual); err != nil {
+		return nil, err
+	}
+
+	return &LockedModule{
+		ID:           version.ModuleID,
+		Version:      version.Version,
+		VersionID:    version.ID,
+		Integrity:    actual,
+		SigningKeyID: signingKeyID,
+	}, nil
+}
+
+// Remove deletes an installed module.
+func (i *Installer) Remove(slug string) error {
+	if !validSlug(slug) {
+		return fmt.Errorf("invalid module slug '%s'", slug)
+	}
+	return os.RemoveAll(filepath.Join(i.Dir, slug))
+}
+
+func (i *Installer) verifySignature(ctx context.Context, version *api.ModuleVersion, archive []byte) error {
+	if i.keys == nil {
+		keys, err := i.client.ListSigningKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch signing keys: %w", err)
+		}
+		i.keys = map[string]api.SigningKey{}
+		for _, key := range keys {
+			i.keys[key.ID] = key
+		}
+	}
+
+	key, ok := i.keys[version.SigningKeyID]
+	if !ok {
+		return fmt.Errorf("archive is signed with unknown key '%s'", version.SigningKeyID)
+	}
+	if key.Algorithm != "" && !strings.EqualFold(key.Algorithm, "ed25519") {
+		return fmt.Errorf("unsupported signing algorithm '%s'", key.Algorithm)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key '%s'", key.ID)
+	}
+	signature, err := base64.StdEncoding.DecodeString(version.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	if !ed25519.Verify(publicKey, archive, signature) {
+		return fmt.Errorf("signature verification failed with key '%s'", key.ID)
+	}
+	return nil
+}
+
+// unpack extracts the archive next to Dir/slug, checks the bundle against
+// its manifest checksum, then swaps it in place of the previous install.
+func (i *Installer) unpack(slug string, archive []byte, integrity string) error {
+	if !validSlug(slug) {
+		return fmt.Errorf("invalid module slug '%s'", slug)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+
+	if err := os.MkdirAll(i.Dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(i.Dir, ".install-"+slug+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+
+	var total int64
+	for _, file := range reader.File {
+		name := filepath.FromSlash(strings.TrimSuffix(file.Name, "/"))
+		if name == "" || !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid archive: unsafe path '%s'", file.Name)
+		}
+		target := filepath.Join(tmp, name)
+
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			return fmt.Errorf("invalid archive: '%s' is not a regular file", file.Name)
+		}
+
+		total += int64(file.UncompressedSize64)
+		if total > maxUnpackedSize {
+			return fmt.Errorf("invalid archive: more than %d MB unpacked", maxUnpackedSize>>20)
+		}
+		if err := extractFile(file, target); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := ReadManifest(tmp)
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	if !filepath.IsLocal(manifest.Entry) {
+		return fmt.Errorf("invalid archive: unsafe entry '%s'", manifest.Entry)
+	}
+	if manifest.Checksum != "" {
+		bundle, err := os.ReadFile(filepath.Join(tmp, manifest.Entry))
+		if err != nil {
+			return fmt.Errorf("invalid archive: %w", err)
+		}
+		if sum := sha256.Sum256(bundle); hex.EncodeToString(sum[:]) != strings.ToLower(manifest.Checksum) {
+			return fmt.Errorf("bundle checksum mismatch: %s does not match dashspace.json", manifest.Entry)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(tmp, integrityFile), []byte(integrity+"\n"), 0644); err != nil {
+		return err
+	}
+
+	dest := filepath.Join(i.Dir, slug)
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+// validSlug rejects slugs that would escape Dir once used as a directory name.
+func validSlug(slug string) bool {
+	return slug != "" && !strings.HasPrefix(slug, ".") && !strings.ContainsAny(slug, `/\`) && filepath.IsLocal(slug)
+}
+
+func extractFile(file *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("invalid archive: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	// Never write more than the size counted against maxUnpackedSize
+	_, err = io.Copy(dst, io.LimitReader(src, int64(file.UncompressedSize64)))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", file.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package modules
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devlyspace/dashspace-cli/internal/api"
+	"github.com/devlyspace/dashspace-cli/test"
+)
+
+// zipEntry is a file of a test archive. size overrides the uncompressed
+// size written in its header.
+type zipEntry struct {
+	name    string
+	content string
+	size    uint64
+}
+
+func buildArchive(t *testing.T, entries ...zipEntry) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Store}
+		header.SetMode(0644)
+		if entry.size == 0 {
+			w, err := writer.CreateHeader(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write([]byte(entry.content))
+			continue
+		}
+
+		// Raw entries keep the header as written, so it can lie about its size
+		header.CRC32 = 0
+		header.CompressedSize64 = uint64(len(entry.content))
+		header.UncompressedSize64 = entry.size
+		w, err := writer.CreateRaw(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(entry.content))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func validArchive(t *testing.T) []byte {
+	return buildArchive(t,
+		zipEntry{name: "dashspace.json", content: `{"slug": "widget", "entry": "bundle.js"}`},
+		zipEntry{name: "bundle.js", content: "export default {}"},
+	)
+}
+
+func TestUnpack(t *testing.T) {
+	installer := &Installer{Dir: t.TempDir()}
+	if err := installer.unpack("widget", validArchive(t), "sha256-test"); err != nil {
+		t.Fatalf("unpack: %v", err)
+	}
+
+	bundle, err := os.ReadFile(filepath.Join(installer.Dir, "widget", "bundle.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertEqual(t, string(bundle), "export default {}")
+	test.AssertEqual(t, installer.Installed("widget", "sha256-test"), true)
+}
+
+func TestUnpackRejectsUnsafePaths(t *testing.T) {
+	for _, name := range []string{"../escape.js", "a/../../escape.js", "/etc/escape.js"} {
+		dir := t.TempDir()
+		installer := &Installer{Dir: filepath.Join(dir, "modules")}
+		archive := buildArchive(t,
+			zipEntry{name: "dashspace.json", content: `{"slug": "widget"}`},
+			zipEntry{name: name, content: "pwned"},
+		)
+
+		err := installer.unpack("widget", archive, "sha256-test")
+		if err == nil || !strings.Contains(err.Error(), "unsafe path") {
+			t.Errorf("%s: expected an unsafe path error, got %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "escape.js")); err == nil {
+			t.Errorf("%s: file written outside the install directory", name)
+		}
+		if _, err := os.Stat(filepath.Join(installer.Dir, "widget")); err == nil {
+			t.Errorf("%s: rejected archive was installed", name)
+		}
+	}
+}
+
+func TestUnpackRejectsOversizedArchive(t *testing.T) {
+	installer := &Installer{Dir: t.TempDir()}
+	archive := buildArchive(t,
+		zipEntry{name: "dashspace.json", content: `{"slug": "widget"}`},
+		zipEntry{name: "a.bin", content: "small"},
+		zipEntry{name: "b.bin", content: "small", size: maxUnpackedSize - 4},
+	)
+
+	err := installer.unpack("widget", archive, "sha256-test")
+	if err == nil || !strings.Contains(err.Error(), "MB unpacked") {
+		t.Fatalf("expected a size error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(installer.Dir, "widget")); err == nil {
+		t.Error("oversized archive was installed")
+	}
+}
+
+func TestUnpackRejectsUnsafeSlug(t *testing.T) {
+	installer := &Installer{Dir: t.TempDir()}
+	for _, slug := range []string{"", "..", ".hidden", "a/b", `a\b`} {
+		if err := installer.unpack(slug, validArchive(t), "sha256-test"); err == nil {
+			t.Errorf("slug %q should be rejected", slug)
+		}
+	}
+}
+
+// storeServer serves archive as version 1 of module 1, and publicKey as
+// signing key "key-1".
+func storeServer(t *testing.T, archive []byte, publicKey ed25519.PublicKey) *api.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/modules/1/module_versions/1/download", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(archive)
+	})
+	mux.HandleFunc("/signing_keys", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string][]api.SigningKey{"items": {{
+			ID:        "key-1",
+			Algorithm: "ed25519",
+			PublicKey: base64.StdEncoding.EncodeToString(publicKey),
+		}}})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return api.NewClientWithBaseURL(server.URL, api.WithToken("token"), api.WithRetries(0))
+}
+
+func TestInstallRecordsVerifiedSigningKey(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := validArchive(t)
+	sum := sha256.Sum256(archive)
+	version := &api.ModuleVersion{
+		ID: 1, ModuleID: 1, Version: "1.0.0",
+		SHA256:       hex.EncodeToString(sum[:]),
+		Signature:    base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, archive)),
+		SigningKeyID: "key-1",
+	}
+
+	installer := NewInstaller(storeServer(t, archive, publicKey), t.TempDir())
+	locked, err := installer.Install(context.Background(), "widget", version, "")
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	test.AssertEqual(t, locked.SigningKeyID, "key-1")
+}
+
+func TestInstallUnsignedRecordsNoSigningKey(t *testing.T) {
+	archive := validArchive(t)
+	sum := sha256.Sum256(archive)
+
+	// The store names a key but serves no signature, so nothing was verified
+	version := &api.ModuleVersion{ID: 1, ModuleID: 1, Version: "1.0.0", SHA256: hex.EncodeToString(sum[:]), SigningKeyID: "key-1"}
+
+	installer := NewInstaller(storeServer(t, archive, nil), t.TempDir())
+	locked, err := installer.Install(context.Background(), "widget", version, "")
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	test.AssertEqual(t, locked.SigningKeyID, "")
+}
+
+func TestInstallRejectsBadSignature(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := validArchive(t)
+	sum := sha256.Sum256(archive)
+	version := &api.ModuleVersion{
+		ID: 1, ModuleID: 1, Version: "1.0.0",
+		SHA256:       hex.EncodeToString(sum[:]),
+		Signature:    base64.StdEncoding.EncodeToString(ed25519.Sign(otherKey, archive)),
+		SigningKeyID: "key-1",
+	}
+
+	installer := NewInstaller(storeServer(t, archive, publicKey), t.TempDir())
+	if _, err := installer.Install(context.Background(), "widget", version, ""); err == nil {
+		t.Fatal("expected the signature check to fail")
+	}
+	test.AssertEqual(t, installer.Installed("widget", "sha256-"+base64.StdEncoding.EncodeToString(sum[:])), false)
+}
@@ -0,0 +1,129 @@
+// Package modules installs published store modules into a local directory
+// and pins them in a lockfile.
+package modules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// LockfileName is the lockfile written next to the workspace.
+	LockfileName = "dashspace-lock.json"
+
+	// DefaultDir is where modules are unpacked, one directory per slug.
+	DefaultDir = "dashspace_modules"
+
+	lockfileVersion = 1
+)
+
+// Lockfile pins installed modules to an exact version and archive hash.
+type Lockfile struct {
+	LockfileVersion int                      `json:"lockfileVersion"`
+	Dir             string                   `json:"dir"`
+	Modules         map[string]*LockedModule `json:"modules"`
+
+	path string
+}
+
+// LockedModule is one installed module, keyed by slug in the lockfile.
+type LockedModule struct {
+	ID           int    `json:"id"`
+	Version      string `json:"version"`
+	VersionID    int    `json:"version_id"`
+	Range        string `json:"range"`
+	Integrity    string `json:"integrity"`
+	SigningKeyID string `json:"signing_key_id,omitempty"`
+}
+
+// LoadLockfile reads the lockfile at path. A missing file gives an empty lockfile.
+func LoadLockfile(path string) (*Lockfile, error) {
+	lock := &Lockfile{
+		LockfileVersion: lockfileVersion,
+		Dir:             DefaultDir,
+		Modules:         map[string]*LockedModule{},
+		path:            path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Base(path), err)
+	}
+	if lock.LockfileVersion > lockfileVersion {
+		return nil, fmt.Errorf("%s was written by a newer CLI (lockfileVersion %d), please update", filepath.Base(path), lock.LockfileVersion)
+	}
+	lock.LockfileVersion = lockfileVersion
+	if lock.Dir == "" {
+		lock.Dir = DefaultDir
+	}
+	if err := checkDir(lock.Dir); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", filepath.Base(path), err)
+	}
+	if lock.Modules == nil {
+		lock.Modules = map[string]*LockedModule{}
+	}
+	for slug := range lock.Modules {
+		if !validSlug(slug) {
+			return nil, fmt.Errorf("invalid %s: invalid module slug '%s'", filepath.Base(path), slug)
+		}
+	}
+
+	return lock, nil
+}
+
+// SetDir changes the directory modules are installed into.
+func (l *Lockfile) SetDir(dir string) error {
+	if err := checkDir(dir); err != nil {
+		return err
+	}
+	l.Dir = dir
+	return nil
+}
+
+// checkDir rejects install directories outside the project, since installing
+// and uninstalling delete directories inside them.
+func checkDir(dir string) error {
+	if !filepath.IsLocal(filepath.FromSlash(dir)) {
+		return fmt.Errorf("install directory '%s' must be a relative path inside the project", dir)
+	}
+	return nil
+}
+
+// Slugs returns the locked module slugs in order.
+func (l *Lockfile) Slugs() []string {
+	slugs := make([]string, 0, len(l.Modules))
+	for slug := range l.Modules {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+// Save writes the lockfile atomically.
+func (l *Lockfile) Save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(l.path), err)
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(l.path), err)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+// Package semver parses module versions and the version ranges accepted by
+// `dashspace install` (npm-style: 1.2.3, ^1.2, ~1.2.3, 1.x, >=1.0.0 <2.0.0, a || b).
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version. Build metadata is ignored.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+// Parse parses "1.2.3", "v1.2.3" or "1.2.3-beta.1".
+func Parse(s string) (Version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+
+	var v Version
+	core := s
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		core, v.Prerelease = s[:i], s[i+1:]
+		if v.Prerelease == "" {
+			return Version{}, fmt.Errorf("invalid version '%s'", s)
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version '%s' (expected MAJOR.MINOR.PATCH)", s)
+	}
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version '%s'", s)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1. A prerelease sorts before its release.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1 // numeric identifiers sort first
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// Range is a set of acceptable versions.
+type Range struct {
+	raw  string
+	sets [][]comparator // OR of ANDs
+}
+
+type comparator struct {
+	op string // =, >, >=, <, <=
+	v  Version
+}
+
+// ParseRange parses a version range. "", "*" and "latest" accept any release.
+func ParseRange(s string) (Range, error) {
+	r := Range{raw: strings.TrimSpace(s)}
+
+	for _, alternative := range strings.Split(r.raw, "||") {
+		var set []comparator
+		for _, term := range strings.Fields(alternative) {
+			comparators, err := parseTerm(term)
+			if err != nil {
+				return Range{}, fmt.Errorf("invalid version range '%s': %v", s, err)
+			}
+			set = append(set, comparators...)
+		}
+		r.sets = append(r.sets, set)
+	}
+	return r, nil
+}
+
+func (r Range) String() string {
+	if r.raw == "" {
+		return "*"
+	}
+	return r.raw
+}
+
+// Contains reports whether v satisfies the range. Prereleases only match a
+// comparator naming a prerelease of the same MAJOR.MINOR.PATCH.
+func (r Range) Contains(v Version) bool {
+	for _, set := range r.sets {
+		if setContains(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Best returns the highest version satisfying the range.
+func (r Range) Best(versions []Version) (Version, bool) {
+	var best Version
+	found := false
+	for _, v := range versions {
+		if r.Contains(v) && (!found || v.Compare(best) > 0) {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
+
+func setContains(set []comparator, v Version) bool {
+	prereleaseAllowed := v.Prerelease == ""
+	for _, c := range set {
+		if !c.matches(v) {
+			return false
+		}
+		if c.v.Prerelease != "" && c.v.Major == v.Major && c.v.Minor == v.Minor && c.v.Patch == v.Patch {
+			prereleaseAllowed = true
+		}
+	}
+	return prereleaseAllowed
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := v.Compare(c.v)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return cmp == 0
+}
+
+// parseTerm turns one range term into comparators.
+func parseTerm(term string) ([]comparator, error) {
+	switch {
+	case term == "*" || term == "latest" || term == "x":
+		return nil, nil
+	case strings.HasPrefix(term, "^"):
+		return caret(term[1:])
+	case strings.HasPrefix(term, "~"):
+		return tilde(term[1:])
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(term, op) {
+			v, err := Parse(term[len(op):])
+			if err != nil {
+				return nil, err
+			}
+			return []comparator{{op, v}}, nil
+		}
+	}
+
+	// A partial version (1, 1.2, 1.x, 1.2.*) is a range on the missing parts
+	base, parts, err := partial(term)
+	if err != nil {
+		return nil, err
+	}
+	switch parts {
+	case 3:
+		return []comparator{{"=", base}}, nil
+	case 2:
+		return []comparator{{">=", base}, {"<", Version{Major: base.Major, Minor: base.Minor + 1}}}, nil
+	}
+	return []comparator{{">=", base}, {"<", Version{Major: base.Major + 1}}}, nil
+}
+
+// caret allows changes that don't modify the left-most non-zero part.
+func caret(s string) ([]comparator, error) {
+	base, parts, err := partial(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var upper Version
+	switch {
+	case base.Major > 0 || parts == 1:
+		upper = Version{Major: base.Major + 1}
+	case base.Minor > 0 || parts == 2:
+		upper = Version{Minor: base.Minor + 1}
+	default:
+		upper = Version{Patch: base.Patch + 1}
+	}
+	return []comparator{{">=", base}, {"<", upper}}, nil
+}
+
+// tilde allows patch changes, or minor changes when only the major is given.
+func tilde(s string) ([]comparator, error) {
+	base, parts, err := partial(s)
+	if err != nil {
+		return nil, err
+	}
+
+	upper := Version{Major: base.Major, Minor: base.Minor + 1}
+	if parts == 1 {
+		upper = Version{Major: base.Major + 1}
+	}
+	return []comparator{{">=", base}, {"<", upper}}, nil
+}
+
+// partial parses a version whose minor and patch may be missing or
+// wildcards, and returns how many parts were given.
+func partial(s string) (Version, int, error) {
+	s = strings.TrimPrefix(s, "v")
+	if strings.ContainsAny(s, "-+") {
+		v, err := Parse(s)
+		return v, 3, err
+	}
+
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return Version{}, 0, fmt.Errorf("invalid version '%s'", s)
+	}
+
+	numbers := []int{0, 0, 0}
+	given := 0
+	for i, field := range fields {
+		if field == "x" || field == "X" || field == "*" {
+			break
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return Version{}, 0, fmt.Errorf("invalid version '%s'", s)
+		}
+		numbers[i] = n
+		given++
+	}
+	if given == 0 {
+		return Version{}, 0, fmt.Errorf("invalid version '%s'", s)
+	}
+
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, given, nil
+}
@@ -0,0 +1,144 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/devlyspace/dashspace-cli/test"
+)
+
+func TestParse(t *testing.T) {
+	v, err := Parse("v1.2.3-beta.1+build.5")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	test.AssertEqual(t, v, Version{Major: 1, Minor: 2, Patch: 3, Prerelease: "beta.1"})
+	test.AssertEqual(t, v.String(), "1.2.3-beta.1")
+
+	for _, invalid := range []string{"", "1.2", "1.2.3.4", "1.2.x", "1.2.3-", "-1.2.3", "a.b.c"} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("Parse(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.2", "1.0.0-alpha.10", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0-beta", "1.0.0-alpha", 1},
+	}
+
+	for _, tt := range tests {
+		if got := mustParse(t, tt.a).Compare(mustParse(t, tt.b)); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, expected %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		rng     string
+		match   []string
+		noMatch []string
+	}{
+		{"", []string{"0.0.1", "9.9.9"}, []string{"1.0.0-beta"}},
+		{"*", []string{"1.0.0"}, []string{"1.0.0-beta"}},
+		{"latest", []string{"1.0.0"}, nil},
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^1.2", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^0", []string{"0.0.1", "0.9.0"}, []string{"1.0.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"1.x", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.0"}},
+		{"1.2.*", []string{"1.2.0", "1.2.9"}, []string{"1.3.0"}},
+		{"1", []string{"1.5.0"}, []string{"2.0.0"}},
+		{">=1.0.0 <2.0.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.9", "2.0.0"}},
+		{">1.0.0 <=1.2.0", []string{"1.0.1", "1.2.0"}, []string{"1.0.0", "1.2.1"}},
+		{"^1.0.0 || ^3.0.0", []string{"1.5.0", "3.1.0"}, []string{"2.0.0"}},
+
+		// Prereleases only match comparators naming one of the same version
+		{"^1.0.0", nil, []string{"1.1.0-beta"}},
+		{">=1.2.0-beta", []string{"1.2.0-beta", "1.2.0-rc.1", "1.2.0", "1.3.0"}, []string{"1.3.0-beta", "1.2.0-alpha"}},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRange(tt.rng)
+		if err != nil {
+			t.Fatalf("ParseRange(%q): %v", tt.rng, err)
+		}
+		for _, v := range tt.match {
+			if !r.Contains(mustParse(t, v)) {
+				t.Errorf("%q should contain %s", tt.rng, v)
+			}
+		}
+		for _, v := range tt.noMatch {
+			if r.Contains(mustParse(t, v)) {
+				t.Errorf("%q should not contain %s", tt.rng, v)
+			}
+		}
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	for _, invalid := range []string{"^", "~x", "1.2.3.4", ">=abc", "^1.a", "foo"} {
+		if _, err := ParseRange(invalid); err == nil {
+			t.Errorf("ParseRange(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestRangeBest(t *testing.T) {
+	var versions []Version
+	for _, v := range []string{"1.0.0", "1.4.2", "1.10.0", "2.0.0-rc.1", "2.0.0", "2.1.0"} {
+		versions = append(versions, mustParse(t, v))
+	}
+
+	tests := []struct {
+		rng  string
+		want string
+	}{
+		{"", "2.1.0"},
+		{"^1.0.0", "1.10.0"},
+		{"~1.4.0", "1.4.2"},
+		{"<2.0.0", "1.10.0"},
+		{"2.0.0-rc.1", "2.0.0-rc.1"},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRange(tt.rng)
+		if err != nil {
+			t.Fatalf("ParseRange(%q): %v", tt.rng, err)
+		}
+		best, ok := r.Best(versions)
+		if !ok {
+			t.Errorf("%q: no version found, expected %s", tt.rng, tt.want)
+			continue
+		}
+		test.AssertEqual(t, best.String(), tt.want, tt.rng)
+	}
+
+	r, _ := ParseRange("^3.0.0")
+	if _, ok := r.Best(versions); ok {
+		t.Error("^3.0.0 should match nothing")
+	}
+}
+
+func mustParse(t *testing.T, s string) Version {
+	t.Helper()
+	v, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return v
+}
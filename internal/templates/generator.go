@@ -157,7 +157,7 @@ func (g *Generator) generateChartTemplate() string {
             <div key={index} className="flex-1 flex flex-col items-center">
               <div
                 className="bg-blue-500 w-full rounded-t"
-                style={{ height: `+"`${(item.value / 800) * 100}%`"+` }}
+                style={{ height: `+"`${(item.value / 800) * 100}%%`"+` }}
               ></div>
               <span className="text-xs mt-1 text-gray-600">{item.name}</span>
             </div>
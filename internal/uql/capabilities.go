@@ -0,0 +1,202 @@
+package uql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Capabilities is what a module's getUQLCapabilities returns. Everything is
+// optional: what a module does not declare is not restricted.
+type Capabilities struct {
+	// Fields that can be filtered and sorted on
+	Fields []FieldCapability `json:"fields,omitempty"`
+	// Operators accepted on every field without its own list
+	Operators []string `json:"operators,omitempty"`
+	// Sortable lists the fields results can be sorted by
+	Sortable []string `json:"sortable,omitempty"`
+	// MaxLimit bounds the limit of a query
+	MaxLimit int `json:"maxLimit,omitempty"`
+	// FullText tells whether free text is supported
+	FullText *bool `json:"fullText,omitempty"`
+}
+
+// FieldCapability describes a field. It can be declared as a bare name.
+type FieldCapability struct {
+	Name string `json:"name"`
+	// Type is string, number, boolean or date
+	Type      string   `json:"type,omitempty"`
+	Operators []string `json:"operators,omitempty"`
+	Sortable  bool     `json:"sortable,omitempty"`
+}
+
+func (f *FieldCapability) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*f = FieldCapability{Name: name}
+		return nil
+	}
+
+	type plain FieldCapability
+	var field plain
+	if err := json.Unmarshal(data, &field); err != nil {
+		return fmt.Errorf("a field must be a name or an object with a name")
+	}
+	*f = FieldCapability(field)
+	return nil
+}
+
+// ParseCapabilities decodes and checks the result of getUQLCapabilities.
+func ParseCapabilities(data []byte) (*Capabilities, error) {
+	caps := &Capabilities{}
+	if err := json.Unmarshal(data, caps); err != nil {
+		return nil, fmt.Errorf("invalid UQL capabilities: %w", err)
+	}
+
+	for i, field := range caps.Fields {
+		if field.Name == "" {
+			return nil, fmt.Errorf("invalid UQL capabilities: fields[%d] has no name", i)
+		}
+		switch field.Type {
+		case "", "string", "number", "boolean", "date":
+		default:
+			return nil, fmt.Errorf("invalid UQL capabilities: field %s has unknown type '%s'", field.Name, field.Type)
+		}
+		if err := checkOperators(field.Operators); err != nil {
+			return nil, fmt.Errorf("invalid UQL capabilities: field %s: %w", field.Name, err)
+		}
+	}
+	if err := checkOperators(caps.Operators); err != nil {
+		return nil, fmt.Errorf("invalid UQL capabilities: %w", err)
+	}
+	if caps.MaxLimit < 0 {
+		return nil, fmt.Errorf("invalid UQL capabilities: maxLimit must be positive")
+	}
+	return caps, nil
+}
+
+func checkOperators(operators []string) error {
+	for _, op := range operators {
+		if !contains(Operators, op) {
+			return fmt.Errorf("unknown operator '%s' (UQL operators: %s)", op, strings.Join(Operators, ", "))
+		}
+	}
+	return nil
+}
+
+func (c *Capabilities) field(name string) (*FieldCapability, bool) {
+	for i := range c.Fields {
+		if c.Fields[i].Name == name {
+			return &c.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// Check returns what the query uses that the capabilities do not allow.
+func (c *Capabilities) Check(q *Query) []*Error {
+	errs := []*Error{}
+
+	if q.Text != "" && c.FullText != nil && !*c.FullText {
+		errs = append(errs, &Error{Pos: 0, Msg: "free text is not supported by this module"})
+	}
+
+	for _, f := range q.Filters {
+		field, declared := c.field(f.Field)
+		if len(c.Fields) > 0 && !declared {
+			errs = append(errs, &Error{Pos: f.Pos, Msg: fmt.Sprintf("unknown field '%s' (fields: %s)", f.Field, c.fieldNames())})
+			continue
+		}
+
+		operators := c.Operators
+		if declared && len(field.Operators) > 0 {
+			operators = field.Operators
+		}
+		if len(operators) > 0 && !contains(operators, f.Operator) {
+			errs = append(errs, &Error{Pos: f.Pos, Msg: fmt.Sprintf("operator '%s' is not supported on %s (operators: %s)", f.Operator, f.Field, strings.Join(operators, ", "))})
+		}
+
+		if declared && field.Type != "" {
+			values := []interface{}{f.Value}
+			if list, ok := f.Value.([]interface{}); ok {
+				values = list
+			}
+			for _, value := range values {
+				if err := checkType(field.Type, value); err != nil {
+					errs = append(errs, &Error{Pos: f.Pos, Msg: fmt.Sprintf("%s: %v", f.Field, err)})
+					break
+				}
+			}
+		}
+	}
+
+	sortable := append([]string{}, c.Sortable...)
+	for _, field := range c.Fields {
+		if field.Sortable {
+			sortable = append(sortable, field.Name)
+		}
+	}
+	for _, s := range q.Sort {
+		if len(sortable) > 0 && !contains(sortable, s.Field) {
+			errs = append(errs, &Error{Pos: s.Pos, Msg: fmt.Sprintf("cannot sort by '%s' (sortable: %s)", s.Field, strings.Join(sortable, ", "))})
+		}
+	}
+
+	if c.MaxLimit > 0 && q.Limit > c.MaxLimit {
+		pos := strings.LastIndex(strings.ToLower(q.Raw), "limit")
+		errs = append(errs, &Error{Pos: max(pos, 0), Msg: fmt.Sprintf("limit %d exceeds the maximum of %d", q.Limit, c.MaxLimit)})
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Pos < errs[j].Pos })
+	return errs
+}
+
+func (c *Capabilities) fieldNames() string {
+	names := make([]string, len(c.Fields))
+	for i, field := range c.Fields {
+		names[i] = field.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func checkType(fieldType string, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	switch fieldType {
+	case "number":
+		switch value.(type) {
+		case int64, float64:
+			return nil
+		}
+		return fmt.Errorf("expected a number, got %v", value)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected true or false, got %v", value)
+		}
+	case "date":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a date, got %v", value)
+		}
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				return fmt.Errorf("expected a date (2006-01-02 or RFC 3339), got %q", s)
+			}
+		}
+	case "string":
+		// Bare words and numbers are accepted as text
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
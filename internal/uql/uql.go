@@ -0,0 +1,371 @@
+// Package uql parses UQL, the query language of Dashspace global search and
+// filters, and checks queries against the capabilities a module declares.
+//
+// A query mixes free text, filters, sorting and a limit, in any order:
+//
+//	"login bug" status = open and labels in (bug, "needs triage") sort by updated desc limit 20
+//
+// Filters are `field operator value`, joined by an optional `and`. Operators
+// are =, !=, >, >=, <, <=, contains, startswith, endswith, in and not in.
+// Values are quoted strings, numbers, true, false, null or bare words.
+// Words and quoted strings that are not part of a filter form the text, and
+// so do sort and order without by, and limit without a number after it
+// ("sort issues by date", "rate limit exceeded").
+package uql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query is a parsed UQL query, passed as is to translateUQLQuery.
+type Query struct {
+	Raw     string   `json:"raw"`
+	Text    string   `json:"text,omitempty"`
+	Filters []Filter `json:"filters"`
+	Sort    []Sort   `json:"sort"`
+	Limit   int      `json:"limit,omitempty"`
+}
+
+// Filter is one `field operator value` condition. The value of in and
+// not in is a list.
+type Filter struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Value    interface{} `json:"value"`
+
+	Pos int `json:"-"`
+}
+
+// Sort orders results by a field.
+type Sort struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"`
+
+	Pos int `json:"-"`
+}
+
+// Operators lists the filter operators of UQL.
+var Operators = []string{"=", "!=", ">", ">=", "<", "<=", "contains", "startswith", "endswith", "in", "not in"}
+
+// Error is a syntax or capability error at a byte offset of the query.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Pos+1, e.Msg)
+}
+
+// Parse parses a UQL query.
+func Parse(raw string) (*Query, error) {
+	tokens, err := lex(raw)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, query: &Query{Raw: raw, Filters: []Filter{}, Sort: []Sort{}}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.query, nil
+}
+
+// MustParse is like Parse but panics on error.
+func MustParse(raw string) *Query {
+	q, err := Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenPunct
+	tokenEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string // unquoted for strings
+	pos  int
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return "'" + t.text + "'"
+}
+
+func lex(raw string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(raw) && raw[j] != c; j++ {
+				if raw[j] == '\\' && j+1 < len(raw) {
+					j++
+				}
+				b.WriteByte(raw[j])
+			}
+			if j >= len(raw) {
+				return nil, &Error{Pos: i, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), pos: i})
+			i = j + 1
+
+		case strings.IndexByte("=!<>", c) >= 0:
+			op := string(c)
+			if i+1 < len(raw) && raw[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &Error{Pos: i, Msg: "unexpected '!', did you mean '!='?"}
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c), pos: i})
+			i++
+
+		default:
+			j := i
+			for j < len(raw) && strings.IndexByte(" \t\n\r\"'=!<>(),", raw[j]) < 0 {
+				j++
+			}
+			text := raw[i:j]
+			kind := tokenWord
+			if _, err := strconv.ParseFloat(text, 64); err == nil {
+				kind = tokenNumber
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: i})
+			i = j
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(raw)}), nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+	query  *Query
+	text   []string
+}
+
+func (p *parser) peek(offset int) token {
+	if p.i+offset < len(p.tokens) {
+		return p.tokens[p.i+offset]
+	}
+	return p.tokens[len(p.tokens)-1]
+}
+
+func (p *parser) next() token {
+	t := p.peek(0)
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+// isListStart reports whether the token at offset opens a list. Without
+// it, in is a word of the text ("log in page").
+func (p *parser) isListStart(offset int) bool {
+	t := p.peek(offset)
+	return t.kind == tokenPunct && t.text == "("
+}
+
+// operatorAt returns the filter operator starting at the token offset and
+// the number of tokens it spans.
+func (p *parser) operatorAt(offset int) (string, int) {
+	t := p.peek(offset)
+	switch {
+	case t.kind == tokenOperator:
+		return t.text, 1
+	case t.is("contains"), t.is("startswith"), t.is("endswith"):
+		return strings.ToLower(t.text), 1
+	case t.is("in") && p.isListStart(offset+1):
+		return "in", 1
+	case t.is("not") && p.peek(offset+1).is("in") && p.isListStart(offset+2):
+		return "not in", 2
+	}
+	return "", 0
+}
+
+func (p *parser) parse() error {
+	limitSeen := false
+	for {
+		t := p.peek(0)
+		switch {
+		case t.kind == tokenEOF:
+			p.query.Text = strings.Join(p.text, " ")
+			return nil
+
+		case t.is("and"):
+			p.next()
+
+		case (t.is("sort") || t.is("order")) && p.peek(1).is("by"):
+			if err := p.parseSort(); err != nil {
+				return err
+			}
+
+		case t.is("limit") && p.peek(1).kind == tokenNumber:
+			if limitSeen {
+				return &Error{Pos: t.pos, Msg: "limit given twice"}
+			}
+			limitSeen = true
+			if err := p.parseLimit(); err != nil {
+				return err
+			}
+
+		case t.kind == tokenWord:
+			if op, _ := p.operatorAt(1); op != "" {
+				if err := p.parseFilter(); err != nil {
+					return err
+				}
+				continue
+			}
+			p.text = append(p.text, p.next().text)
+
+		case t.kind == tokenString, t.kind == tokenNumber:
+			if op, _ := p.operatorAt(1); op != "" {
+				return &Error{Pos: t.pos, Msg: fmt.Sprintf("expected a field name before %s, got %s", op, t.describe())}
+			}
+			p.text = append(p.text, p.next().text)
+
+		default:
+			return &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t.describe())}
+		}
+	}
+}
+
+func (p *parser) parseFilter() error {
+	field := p.next()
+	op, n := p.operatorAt(0)
+	for ; n > 0; n-- {
+		p.next()
+	}
+
+	filter := Filter{Field: field.text, Operator: op, Pos: field.pos}
+	if op == "in" || op == "not in" {
+		values, err := p.parseList()
+		if err != nil {
+			return err
+		}
+		filter.Value = values
+	} else {
+		value, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		filter.Value = value
+	}
+
+	p.query.Filters = append(p.query.Filters, filter)
+	return nil
+}
+
+func (p *parser) parseList() ([]interface{}, error) {
+	open := p.next()
+	if open.text != "(" || open.kind != tokenPunct {
+		return nil, &Error{Pos: open.pos, Msg: fmt.Sprintf("expected '(' to start a list, got %s", open.describe())}
+	}
+
+	values := []interface{}{}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t := p.next()
+		switch {
+		case t.kind == tokenPunct && t.text == ",":
+			continue
+		case t.kind == tokenPunct && t.text == ")":
+			return values, nil
+		}
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("expected ',' or ')' in list, got %s", t.describe())}
+	}
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return t.text, nil
+	case tokenNumber:
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return n, nil
+		}
+		f, _ := strconv.ParseFloat(t.text, 64)
+		return f, nil
+	case tokenWord:
+		switch strings.ToLower(t.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return t.text, nil
+	}
+	return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("expected a value, got %s", t.describe())}
+}
+
+func (p *parser) parseSort() error {
+	p.next()
+	p.next() // by
+
+	for {
+		field := p.next()
+		if field.kind != tokenWord {
+			return &Error{Pos: field.pos, Msg: fmt.Sprintf("expected a field to sort by, got %s", field.describe())}
+		}
+
+		sort := Sort{Field: field.text, Direction: "asc", Pos: field.pos}
+		if t := p.peek(0); t.is("asc") || t.is("desc") {
+			sort.Direction = strings.ToLower(p.next().text)
+		}
+		p.query.Sort = append(p.query.Sort, sort)
+
+		if t := p.peek(0); t.kind != tokenPunct || t.text != "," {
+			return nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseLimit() error {
+	p.next()
+	t := p.next()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokenNumber || err != nil || n <= 0 {
+		return &Error{Pos: t.pos, Msg: fmt.Sprintf("limit must be a positive integer, got %s", t.describe())}
+	}
+	p.query.Limit = n
+	return nil
+}
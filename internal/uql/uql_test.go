@@ -0,0 +1,117 @@
+package uql
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string // the query as JSON, without raw
+	}{
+		{
+			`"login bug" status = open and labels in (bug, "needs triage") sort by updated desc limit 20`,
+			`{"text": "login bug", "filters": [
+				{"field": "status", "operator": "=", "value": "open"},
+				{"field": "labels", "operator": "in", "value": ["bug", "needs triage"]}
+			], "sort": [{"field": "updated", "direction": "desc"}], "limit": 20}`,
+		},
+		{
+			`author != octocat comments >= 3 draft = false milestone = null`,
+			`{"filters": [
+				{"field": "author", "operator": "!=", "value": "octocat"},
+				{"field": "comments", "operator": ">=", "value": 3},
+				{"field": "draft", "operator": "=", "value": false},
+				{"field": "milestone", "operator": "=", "value": null}
+			], "sort": []}`,
+		},
+		{
+			`title CONTAINS 'crash' path startswith src/ score < 0.5 state not in (closed, merged)`,
+			`{"filters": [
+				{"field": "title", "operator": "contains", "value": "crash"},
+				{"field": "path", "operator": "startswith", "value": "src/"},
+				{"field": "score", "operator": "<", "value": 0.5},
+				{"field": "state", "operator": "not in", "value": ["closed", "merged"]}
+			], "sort": []}`,
+		},
+		{
+			`ORDER BY priority, created asc`,
+			`{"filters": [], "sort": [{"field": "priority", "direction": "asc"}, {"field": "created", "direction": "asc"}]}`,
+		},
+		{
+			`limit 5 bug`,
+			`{"text": "bug", "filters": [], "sort": [], "limit": 5}`,
+		},
+
+		// Keywords without what follows them are words of the text
+		{`rate limit exceeded`, `{"text": "rate limit exceeded", "filters": [], "sort": []}`},
+		{`sort issues by date`, `{"text": "sort issues by date", "filters": [], "sort": []}`},
+		{`order status`, `{"text": "order status", "filters": [], "sort": []}`},
+		{`speed limit`, `{"text": "speed limit", "filters": [], "sort": []}`},
+		{`log in page`, `{"text": "log in page", "filters": [], "sort": []}`},
+		{`not in stock`, `{"text": "not in stock", "filters": [], "sort": []}`},
+		{`"sort by" 42`, `{"text": "sort by 42", "filters": [], "sort": []}`},
+		{``, `{"filters": [], "sort": []}`},
+	}
+
+	for _, tt := range tests {
+		q, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+
+		var got, expected map[string]interface{}
+		data, _ := json.Marshal(q)
+		json.Unmarshal(data, &got)
+		delete(got, "raw")
+		if err := json.Unmarshal([]byte(tt.expected), &expected); err != nil {
+			t.Fatalf("invalid expected JSON for %q: %v", tt.query, err)
+		}
+		gotJSON, _ := json.Marshal(got)
+		expectedJSON, _ := json.Marshal(expected)
+		if string(gotJSON) != string(expectedJSON) {
+			t.Errorf("Parse(%q) = %s, expected %s", tt.query, gotJSON, expectedJSON)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`status = "open`, `col 10: unterminated string`},
+		{`status ! open`, `col 8: unexpected '!', did you mean '!='?`},
+		{`status =`, `col 9: expected a value, got end of query`},
+		{`labels in (bug, feature`, `col 24: expected ',' or ')' in list, got end of query`},
+		{`"status" = open`, `col 1: expected a field name before =, got "status"`},
+		{`limit 0`, `col 7: limit must be a positive integer, got '0'`},
+		{`limit 2.5`, `col 7: limit must be a positive integer, got '2.5'`},
+		{`limit 5 limit 10`, `col 9: limit given twice`},
+		{`sort by`, `col 8: expected a field to sort by, got end of query`},
+		{`) bug`, `col 1: unexpected ')'`},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		if err == nil {
+			t.Errorf("Parse(%q) should fail with %q", tt.query, tt.expected)
+			continue
+		}
+		if err.Error() != tt.expected {
+			t.Errorf("Parse(%q): %q, expected %q", tt.query, err, tt.expected)
+		}
+	}
+}
+
+func TestParsePositions(t *testing.T) {
+	q := MustParse(`bug status = open sort by updated`)
+	if q.Filters[0].Pos != 4 {
+		t.Errorf("filter position = %d, expected 4", q.Filters[0].Pos)
+	}
+	if q.Sort[0].Pos != 26 {
+		t.Errorf("sort position = %d, expected 26", q.Sort[0].Pos)
+	}
+}
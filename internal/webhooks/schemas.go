@@ -0,0 +1,167 @@
+package webhooks
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/devlyspace/dashspace-cli/internal/utils"
+)
+
+//go:embed schemas
+var schemasFS embed.FS
+
+// ProviderSchema holds the JSON Schemas of the payloads a provider sends,
+// keyed by event name. Refs point into Defs as "#/$defs/<Name>".
+type ProviderSchema struct {
+	Provider string `json:"provider"`
+	// Title is the provider name as written in generated type names
+	Title  string             `json:"title"`
+	Defs   map[string]*Schema `json:"$defs"`
+	Events map[string]*Schema `json:"events"`
+}
+
+// Schema is the subset of JSON Schema used to describe payloads.
+type Schema struct {
+	Ref         string        `json:"$ref,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Type        SchemaTypes   `json:"type,omitempty"`
+	Format      string        `json:"format,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Properties  Properties    `json:"properties,omitempty"`
+	Required    []string      `json:"required,omitempty"`
+	Items       *Schema       `json:"items,omitempty"`
+	AnyOf       []*Schema     `json:"anyOf,omitempty"`
+	// AdditionalProperties describes the values of a map-like object
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+}
+
+// SchemaTypes is "type", written either as a name or as a list of names.
+type SchemaTypes []string
+
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = SchemaTypes{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("type must be a name or a list of names")
+	}
+	*t = names
+	return nil
+}
+
+// Property is a named object property.
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties keeps object properties in the order they are written.
+type Properties []Property
+
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return fmt.Errorf("properties must be an object")
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		schema := &Schema{}
+		if err := decoder.Decode(schema); err != nil {
+			return fmt.Errorf("property %v: %w", token, err)
+		}
+		*p = append(*p, Property{Name: token.(string), Schema: schema})
+	}
+	return nil
+}
+
+// SchemaProviders returns the providers that have bundled payload schemas.
+func SchemaProviders() []string {
+	entries, err := fs.ReadDir(schemasFS, "schemas")
+	if err != nil {
+		return nil
+	}
+
+	providers := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			providers = append(providers, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// LoadSchema returns the bundled payload schemas of a provider.
+func LoadSchema(provider string) (*ProviderSchema, error) {
+	data, err := schemasFS.ReadFile(path.Join("schemas", strings.ToLower(provider)+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("no payload schemas bundled for provider '%s' (available: %s)", provider, strings.Join(SchemaProviders(), ", "))
+	}
+
+	schema := &ProviderSchema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("invalid %s payload schemas: %w", provider, err)
+	}
+	return schema, nil
+}
+
+// EventNames returns the events of the provider, sorted.
+func (p *ProviderSchema) EventNames() []string {
+	names := make([]string, 0, len(p.Events))
+	for name := range p.Events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve follows a "#/$defs/<Name>" ref.
+func (p *ProviderSchema) Resolve(ref string) (string, *Schema, error) {
+	name := strings.TrimPrefix(ref, "#/$defs/")
+	def, ok := p.Defs[name]
+	if !ok || name == ref {
+		return "", nil, fmt.Errorf("unresolved ref '%s'", ref)
+	}
+	return name, def, nil
+}
+
+// Suggest returns the event closest to an unknown event name, or "" when
+// none is close enough to be a typo.
+func (p *ProviderSchema) Suggest(event string) string {
+	best, bestDistance := "", 3
+	for _, name := range p.EventNames() {
+		if d := utils.EditDistance(strings.ToLower(event), name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// CheckEvents sorts out the events without a bundled schema. The ones close
+// to a bundled event are likely typos and returned as errors; the others may
+// be real events the schemas do not cover.
+func (p *ProviderSchema) CheckEvents(events []string) (typos []error, unschemaed []string) {
+	for _, event := range events {
+		if _, ok := p.Events[event]; ok {
+			continue
+		}
+		if suggestion := p.Suggest(event); suggestion != "" {
+			typos = append(typos, fmt.Errorf("%s has no '%s' event, did you mean '%s'?", p.Provider, event, suggestion))
+		} else {
+			unschemaed = append(unschemaed, event)
+		}
+	}
+	return typos, unschemaed
+}
@@ -0,0 +1,248 @@
+package webhooks
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// untypedPayload stands in for the schema of events that have none bundled.
+var untypedPayload = &Schema{
+	Description:          "No bundled schema, the payload is not typed",
+	Type:                 SchemaTypes{"object"},
+	AdditionalProperties: &Schema{},
+}
+
+// GenerateTypeScript returns TypeScript declarations for the payloads of
+// events: an interface per event, the shared types they use, and a
+// <Title>WebhookEvent type narrowing WebhookEvent to them. Events without a
+// bundled schema get an untyped payload; likely typos are an error.
+func (p *ProviderSchema) GenerateTypeScript(events []string) (string, error) {
+	if len(events) == 0 {
+		return "", fmt.Errorf("no events to generate types for")
+	}
+	events = append([]string{}, events...)
+	sort.Strings(events)
+
+	if typos, _ := p.CheckEvents(events); len(typos) > 0 {
+		return "", typos[0]
+	}
+
+	defs := map[string]bool{}
+	for _, event := range events {
+		schema, ok := p.Events[event]
+		if !ok {
+			continue
+		}
+		if err := p.collectRefs(schema, defs); err != nil {
+			return "", fmt.Errorf("%s event '%s': %w", p.Provider, event, err)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Generated by `dashspace generate webhook-types` from the %s payload\n", p.Title)
+	b.WriteString("// schemas bundled with the CLI. Do not edit: run the command again after\n")
+	b.WriteString("// changing metadata.webhooks.events.\n\n")
+	b.WriteString("import type { WebhookEvent } from 'dashspace-lib';\n")
+
+	defNames := make([]string, 0, len(defs))
+	for name := range defs {
+		defNames = append(defNames, name)
+	}
+	sort.Strings(defNames)
+	for _, name := range defNames {
+		b.WriteString("\n")
+		p.writeDeclaration(&b, p.Title+name, p.Defs[name], "")
+	}
+
+	for _, event := range events {
+		schema, ok := p.Events[event]
+		if !ok {
+			schema = untypedPayload
+		}
+		b.WriteString("\n")
+		p.writeDeclaration(&b, p.payloadTypeName(event), schema, event)
+	}
+
+	payloads := p.Title + "WebhookPayloads"
+	fmt.Fprintf(&b, "\n/** Payload of each declared %s event, by event name. */\n", p.Title)
+	fmt.Fprintf(&b, "export interface %s {\n", payloads)
+	for _, event := range events {
+		fmt.Fprintf(&b, "  %s: %s;\n", propertyName(event), p.payloadTypeName(event))
+	}
+	b.WriteString("}\n")
+
+	eventType := p.Title + "WebhookEvent"
+	fmt.Fprintf(&b, "\n/** A WebhookEvent whose data matches its event, e.g. %s<'%s'>. */\n", eventType, events[0])
+	fmt.Fprintf(&b, "export type %s<E extends keyof %s = keyof %s> = {\n", eventType, payloads, payloads)
+	fmt.Fprintf(&b, "  [K in E]: Omit<WebhookEvent, 'type' | 'data'> & { type: K; data: %s[K] };\n", payloads)
+	b.WriteString("}[E];\n")
+
+	return b.String(), nil
+}
+
+func (p *ProviderSchema) collectRefs(schema *Schema, defs map[string]bool) error {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		name, def, err := p.Resolve(schema.Ref)
+		if err != nil {
+			return err
+		}
+		if defs[name] {
+			return nil
+		}
+		defs[name] = true
+		return p.collectRefs(def, defs)
+	}
+
+	children := append([]*Schema{schema.Items, schema.AdditionalProperties}, schema.AnyOf...)
+	for _, property := range schema.Properties {
+		children = append(children, property.Schema)
+	}
+	for _, child := range children {
+		if err := p.collectRefs(child, defs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *ProviderSchema) payloadTypeName(event string) string {
+	return p.Title + pascalCase(event) + "Payload"
+}
+
+func (p *ProviderSchema) writeDeclaration(b *strings.Builder, name string, schema *Schema, event string) {
+	doc := schema.Description
+	if event != "" {
+		doc = strings.TrimSpace(fmt.Sprintf("%s (%s)", doc, event))
+	}
+	if doc != "" {
+		fmt.Fprintf(b, "/** %s */\n", doc)
+	}
+
+	if len(schema.Properties) > 0 {
+		fmt.Fprintf(b, "export interface %s %s\n", name, p.objectType(schema, ""))
+		return
+	}
+	fmt.Fprintf(b, "export type %s = %s;\n", name, p.tsType(schema, ""))
+}
+
+func (p *ProviderSchema) tsType(schema *Schema, indent string) string {
+	if schema.Ref != "" {
+		name, _, err := p.Resolve(schema.Ref)
+		if err != nil {
+			return "unknown"
+		}
+		return p.Title + name
+	}
+
+	if len(schema.AnyOf) > 0 {
+		options := make([]string, len(schema.AnyOf))
+		for i, option := range schema.AnyOf {
+			options[i] = p.tsType(option, indent)
+		}
+		return strings.Join(options, " | ")
+	}
+
+	if len(schema.Enum) > 0 {
+		literals := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			switch v := value.(type) {
+			case string:
+				literals[i] = "'" + strings.ReplaceAll(v, "'", "\\'") + "'"
+			case nil:
+				literals[i] = "null"
+			default:
+				literals[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	if len(schema.Type) == 0 {
+		if len(schema.Properties) > 0 {
+			return p.objectType(schema, indent)
+		}
+		return "unknown"
+	}
+
+	types := make([]string, len(schema.Type))
+	for i, t := range schema.Type {
+		switch t {
+		case "string", "boolean", "null":
+			types[i] = t
+		case "integer", "number":
+			types[i] = "number"
+		case "array":
+			types[i] = p.arrayType(schema.Items, indent)
+		case "object":
+			types[i] = p.objectType(schema, indent)
+		default:
+			types[i] = "unknown"
+		}
+	}
+	return strings.Join(types, " | ")
+}
+
+func (p *ProviderSchema) arrayType(items *Schema, indent string) string {
+	if items == nil {
+		return "unknown[]"
+	}
+	item := p.tsType(items, indent)
+	if strings.Contains(item, " | ") && !strings.HasPrefix(item, "{") {
+		return "(" + item + ")[]"
+	}
+	return item + "[]"
+}
+
+func (p *ProviderSchema) objectType(schema *Schema, indent string) string {
+	if len(schema.Properties) == 0 {
+		if schema.AdditionalProperties != nil {
+			return "Record<string, " + p.tsType(schema.AdditionalProperties, indent) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	inner := indent + "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, property := range schema.Properties {
+		if property.Schema.Description != "" {
+			fmt.Fprintf(&b, "%s/** %s */\n", inner, property.Schema.Description)
+		}
+		optional := "?"
+		if required[property.Name] {
+			optional = ""
+		}
+		fmt.Fprintf(&b, "%s%s%s: %s;\n", inner, propertyName(property.Name), optional, p.tsType(property.Schema, inner))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+func propertyName(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	return "'" + name + "'"
+}
+
+func pascalCase(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '.' || r == '-'
+	})
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ValidatePayload checks a payload against the schema of its event and
+// returns what does not match, one "path: problem" line per mismatch.
+// Properties the schema does not describe are accepted.
+func (p *ProviderSchema) ValidatePayload(event string, payload []byte) ([]string, error) {
+	schema, ok := p.Events[event]
+	if !ok {
+		return nil, fmt.Errorf("no bundled %s schema for '%s' events, the payload was not checked", p.Provider, event)
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, fmt.Errorf("payload is not valid JSON: %w", err)
+	}
+
+	problems := []string{}
+	p.validate(schema, data, "", &problems)
+	return problems, nil
+}
+
+func (p *ProviderSchema) validate(schema *Schema, value interface{}, at string, problems *[]string) {
+	report := func(format string, args ...interface{}) {
+		where := at
+		if where == "" {
+			where = "payload"
+		}
+		*problems = append(*problems, where+": "+fmt.Sprintf(format, args...))
+	}
+
+	if schema.Ref != "" {
+		_, def, err := p.Resolve(schema.Ref)
+		if err != nil {
+			report("%v", err)
+			return
+		}
+		schema = def
+	}
+
+	if len(schema.AnyOf) > 0 {
+		for _, option := range schema.AnyOf {
+			var mismatches []string
+			p.validate(option, value, at, &mismatches)
+			if len(mismatches) == 0 {
+				return
+			}
+		}
+		report("matches none of the expected shapes, got %s", describeValue(value))
+		return
+	}
+
+	if len(schema.Type) > 0 && !matchesType(schema.Type, value) {
+		report("expected %s, got %s", strings.Join(schema.Type, " or "), describeValue(value))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		report("unexpected value %s (expected one of %s)", describeValue(value), formatEnum(schema.Enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				report("missing required property '%s'", name)
+			}
+		}
+		for _, property := range schema.Properties {
+			if field, ok := v[property.Name]; ok {
+				p.validate(property.Schema, field, joinPath(at, property.Name), problems)
+			}
+		}
+		if schema.AdditionalProperties != nil && len(schema.Properties) == 0 {
+			for key, field := range v {
+				p.validate(schema.AdditionalProperties, field, joinPath(at, key), problems)
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range v {
+				p.validate(schema.Items, item, fmt.Sprintf("%s[%d]", at, i), problems)
+			}
+		}
+	}
+}
+
+func matchesType(types SchemaTypes, value interface{}) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		values[i] = describeValue(value)
+	}
+	return strings.Join(values, ", ")
+}
+
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func joinPath(at, name string) string {
+	if at == "" {
+		return name
+	}
+	return at + "." + name
+}
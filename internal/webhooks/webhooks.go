@@ -0,0 +1,293 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed samples
+var samplesFS embed.FS
+
+// DefaultSecret is the signing secret shared by `dashspace dev` and
+// `dashspace webhook send` when none is given.
+const DefaultSecret = "dashspace-dev-secret"
+
+// Event mirrors the WebhookEvent object handed to module handlers.
+type Event struct {
+	ID         string            `json:"id"`
+	Provider   string            `json:"provider"`
+	Type       string            `json:"type"`
+	Action     string            `json:"action,omitempty"`
+	Data       interface{}       `json:"data"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Verified   bool              `json:"verified"`
+	ReceivedAt string            `json:"receivedAt"`
+}
+
+// Providers returns the providers that have bundled sample payloads.
+func Providers() []string {
+	entries, err := fs.ReadDir(samplesFS, "samples")
+	if err != nil {
+		return nil
+	}
+
+	providers := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			providers = append(providers, entry.Name())
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// SampleEvents returns the events that have a bundled sample payload for a provider.
+func SampleEvents(provider string) []string {
+	entries, err := fs.ReadDir(samplesFS, path.Join("samples", strings.ToLower(provider)))
+	if err != nil {
+		return nil
+	}
+
+	events := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			events = append(events, strings.TrimSuffix(entry.Name(), ".json"))
+		}
+	}
+	sort.Strings(events)
+	return events
+}
+
+// SamplePayload returns the bundled payload for a provider event. Sample files
+// are named after the event, dots included (e.g. Stripe's invoice.paid.json).
+func SamplePayload(provider, event string) ([]byte, error) {
+	data, err := samplesFS.ReadFile(path.Join("samples", strings.ToLower(provider), event+".json"))
+	if err != nil {
+		available := SampleEvents(provider)
+		if len(available) == 0 {
+			return nil, fmt.Errorf("no sample payloads bundled for provider '%s'", provider)
+		}
+		return nil, fmt.Errorf("no sample payload for %s event '%s' (available: %s)", provider, event, strings.Join(available, ", "))
+	}
+	return data, nil
+}
+
+// SignatureHeaders returns the delivery headers a provider would send for body,
+// signed with secret the same way the real provider does.
+func SignatureHeaders(provider, event, secret string, body []byte) map[string]string {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	switch strings.ToLower(provider) {
+	case "github":
+		headers["X-GitHub-Event"] = event
+		headers["X-GitHub-Delivery"] = newDeliveryID()
+		headers["X-Hub-Signature-256"] = "sha256=" + hmacHex(secret, body)
+	case "gitlab":
+		headers["X-Gitlab-Event"] = gitlabEventHeader(event)
+		headers["X-Gitlab-Token"] = secret
+	case "slack":
+		headers["X-Slack-Request-Timestamp"] = timestamp
+		headers["X-Slack-Signature"] = "v0=" + hmacHex(secret, []byte("v0:"+timestamp+":"+string(body)))
+	case "stripe":
+		headers["Stripe-Signature"] = fmt.Sprintf("t=%s,v1=%s", timestamp, hmacHex(secret, []byte(timestamp+"."+string(body))))
+	case "linear":
+		headers["Linear-Event"] = linearEventHeader(event)
+		headers["Linear-Delivery"] = newDeliveryID()
+		headers["Linear-Signature"] = hmacHex(secret, body)
+	default:
+		headers["X-Dashspace-Signature"] = "sha256=" + hmacHex(secret, body)
+	}
+
+	headers["X-Dashspace-Provider"] = strings.ToLower(provider)
+	headers["X-Dashspace-Event"] = event
+	return headers
+}
+
+// DetectProvider guesses the provider of an incoming delivery from its headers.
+func DetectProvider(h http.Header) string {
+	switch {
+	case h.Get("X-Dashspace-Provider") != "":
+		return strings.ToLower(h.Get("X-Dashspace-Provider"))
+	case h.Get("X-GitHub-Event") != "":
+		return "github"
+	case h.Get("X-Gitlab-Event") != "":
+		return "gitlab"
+	case h.Get("X-Slack-Signature") != "":
+		return "slack"
+	case h.Get("Stripe-Signature") != "":
+		return "stripe"
+	case h.Get("Linear-Signature") != "":
+		return "linear"
+	}
+	return ""
+}
+
+// Verify checks the signature headers of a delivery against secret.
+func Verify(provider, secret string, h http.Header, body []byte) error {
+	switch provider {
+	case "github":
+		return compareSignature(h.Get("X-Hub-Signature-256"), "sha256="+hmacHex(secret, body))
+	case "gitlab":
+		return compareSignature(h.Get("X-Gitlab-Token"), secret)
+	case "slack":
+		timestamp := h.Get("X-Slack-Request-Timestamp")
+		return compareSignature(h.Get("X-Slack-Signature"), "v0="+hmacHex(secret, []byte("v0:"+timestamp+":"+string(body))))
+	case "stripe":
+		var timestamp, signature string
+		for _, part := range strings.Split(h.Get("Stripe-Signature"), ",") {
+			if strings.HasPrefix(part, "t=") {
+				timestamp = strings.TrimPrefix(part, "t=")
+			} else if strings.HasPrefix(part, "v1=") {
+				signature = strings.TrimPrefix(part, "v1=")
+			}
+		}
+		return compareSignature(signature, hmacHex(secret, []byte(timestamp+"."+string(body))))
+	case "linear":
+		return compareSignature(h.Get("Linear-Signature"), hmacHex(secret, body))
+	default:
+		return compareSignature(h.Get("X-Dashspace-Signature"), "sha256="+hmacHex(secret, body))
+	}
+}
+
+// NewEvent builds the event delivered to the module from a raw delivery.
+func NewEvent(provider, event string, h http.Header, body []byte) (*Event, error) {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("payload is not valid JSON: %w", err)
+	}
+
+	headers := make(map[string]string, len(h))
+	for key := range h {
+		headers[key] = h.Get(key)
+	}
+
+	if event == "" {
+		event = eventFromPayload(data)
+	}
+	if event == "" {
+		return nil, fmt.Errorf("unable to determine %s event name from delivery", provider)
+	}
+
+	evt := &Event{
+		ID:         newDeliveryID(),
+		Provider:   provider,
+		Type:       event,
+		Data:       data,
+		Headers:    headers,
+		ReceivedAt: time.Now().Format(time.RFC3339),
+	}
+
+	if obj, ok := data.(map[string]interface{}); ok {
+		if action, ok := obj["action"].(string); ok {
+			evt.Action = action
+		}
+	}
+
+	return evt, nil
+}
+
+// EventName extracts the event name a provider put in the delivery headers.
+func EventName(provider string, h http.Header) string {
+	if event := h.Get("X-Dashspace-Event"); event != "" {
+		return event
+	}
+
+	switch provider {
+	case "github":
+		return h.Get("X-GitHub-Event")
+	case "gitlab":
+		header := strings.TrimSuffix(h.Get("X-Gitlab-Event"), " Hook")
+		return strings.ReplaceAll(strings.ToLower(header), " ", "_")
+	case "linear":
+		return linearEventName(h.Get("Linear-Event"))
+	}
+	return ""
+}
+
+// eventFromPayload reads the event name from providers that carry it in the
+// body rather than in a header (Stripe's "type", Slack's "event.type").
+func eventFromPayload(data interface{}) string {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if inner, ok := obj["event"].(map[string]interface{}); ok {
+		if t, ok := inner["type"].(string); ok {
+			return t
+		}
+	}
+	if t, ok := obj["type"].(string); ok {
+		return t
+	}
+	return ""
+}
+
+func gitlabEventHeader(event string) string {
+	parts := strings.Split(event, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, " ") + " Hook"
+}
+
+// linearEventHeader turns issue_label into the IssueLabel Linear sends.
+func linearEventHeader(event string) string {
+	parts := strings.Split(event, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// linearEventName turns the IssueLabel Linear sends into issue_label.
+func linearEventName(header string) string {
+	var b strings.Builder
+	for i, r := range header {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
+
+func compareSignature(got, want string) error {
+	if got == "" {
+		return fmt.Errorf("missing signature header")
+	}
+	if !hmac.Equal([]byte(got), []byte(want)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func hmacHex(secret string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
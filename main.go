@@ -7,6 +7,7 @@ import (
 
 	"github.com/devlyspace/dashspace-cli/internal/commands"
 	"github.com/devlyspace/dashspace-cli/internal/config"
+	"github.com/devlyspace/dashspace-cli/internal/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +22,29 @@ des modules pour l'écosystème DashSpace.`,
 		Version: version,
 	}
 
-	config.InitConfig()
+	var (
+		overrides config.Overrides
+		verbose   bool
+	)
+	rootCmd.PersistentFlags().StringVar(&overrides.Profile, "profile", "", "Configuration profile to use (env: DASHSPACE_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&overrides.APIURL, "api-url", "", "Modly API URL, overrides the profile (env: DASHSPACE_API_URL)")
+	rootCmd.PersistentFlags().BoolVar(&overrides.Debug, "debug", false, "Enable debug output, including HTTP headers and bodies (env: DASHSPACE_DEBUG)")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log API requests and timings to stderr")
+
+	// Flags are only parsed once a command runs
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		config.InitConfig(overrides)
+
+		cfg := config.GetConfig()
+		if err := logging.Setup(logging.Options{Verbose: verbose, Debug: cfg.Debug}); err != nil && cfg.Debug {
+			fmt.Fprintf(os.Stderr, "⚠️  Could not open the log file: %v\n", err)
+		}
+		logging.Logger().Info("command started",
+			"command", cmd.CommandPath(),
+			"version", version,
+			"profile", cfg.Profile,
+			"api_url", cfg.APIBaseURL)
+	}
 
 	rootCmd.AddCommand(commands.NewLoginCmd())
 	rootCmd.AddCommand(commands.NewLogoutCmd())
@@ -30,10 +53,27 @@ des modules pour l'écosystème DashSpace.`,
 	rootCmd.AddCommand(commands.NewPreviewCmd())
 	rootCmd.AddCommand(commands.NewPublishCmd())
 	rootCmd.AddCommand(commands.NewSearchCmd())
+	rootCmd.AddCommand(commands.NewInfoCmd())
+	rootCmd.AddCommand(commands.NewInstallCmd())
+	rootCmd.AddCommand(commands.NewUninstallCmd())
 	rootCmd.AddCommand(build.NewBuildCmd())
+	rootCmd.AddCommand(commands.NewTestCmd())
+	rootCmd.AddCommand(commands.NewUQLCmd())
 	rootCmd.AddCommand(commands.NewDevCmd())
+	rootCmd.AddCommand(commands.NewWebhookCmd())
+	rootCmd.AddCommand(commands.NewGenerateCmd())
+	rootCmd.AddCommand(commands.NewDataCmd())
+	rootCmd.AddCommand(commands.NewProfileCmd())
+	rootCmd.AddCommand(commands.NewConfigCmd())
+	rootCmd.AddCommand(commands.NewTokensCmd())
+
+	err := rootCmd.Execute()
+	if err != nil {
+		logging.Logger().Error("command failed", "error", err)
+	}
+	logging.Close()
 
-	if err := rootCmd.Execute(); err != nil {
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}